@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+const cacheUsage = `Usage: pd cache <command>
+
+Commands:
+  list                      List cached documentation
+  size                      Show the total size of cached documentation
+  prune --older-than <age>  Remove docs fetched longer ago than age (e.g. 30d, 2w, 12h)
+  clear <dep> [version]     Remove cached docs for a dependency
+  clear --all               Remove all cached docs
+`
+
+// runCache implements `pd cache` and returns the process exit code
+func runCache(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return 2
+	}
+
+	store, err := cache.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		err = cacheList(store)
+	case "size":
+		err = cacheSize(store)
+	case "prune":
+		err = cachePrune(store, args[1:])
+	case "clear":
+		err = cacheClear(store, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown cache command %q\n\n%s", args[0], cacheUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func cacheList(store *cache.Store) error {
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No cached documentation")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tECOSYSTEM\tSIZE\tFETCHED\tSOURCE\tPATH")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Name,
+			entry.Version,
+			entry.Ecosystem,
+			cache.FormatSize(entry.Size),
+			entry.FetchedAt.Format(time.DateTime),
+			entry.Source,
+			entry.Path)
+	}
+	return w.Flush()
+}
+
+func cacheSize(store *cache.Store) error {
+	size, err := store.Size()
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s in %d doc sets (%s)\n", cache.FormatSize(size), len(entries), store.Dir())
+	return nil
+}
+
+func cachePrune(store *cache.Store, args []string) error {
+	flags := flag.NewFlagSet("pd cache prune", flag.ContinueOnError)
+	olderThan := flags.String("older-than", "", "Remove docs fetched longer ago than this age (e.g. 30d)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *olderThan == "" {
+		return fmt.Errorf("--older-than is required")
+	}
+
+	age, err := cache.ParseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+
+	removed, err := store.Prune(age)
+	if err != nil {
+		return err
+	}
+	printRemoved(removed)
+	return nil
+}
+
+func cacheClear(store *cache.Store, args []string) error {
+	flags := flag.NewFlagSet("pd cache clear", flag.ContinueOnError)
+	all := flags.Bool("all", false, "Remove all cached docs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var removed []cache.Entry
+	var err error
+	switch {
+	case *all:
+		removed, err = store.ClearAll()
+	case flags.NArg() == 1:
+		removed, err = store.Clear(flags.Arg(0), "")
+	case flags.NArg() == 2:
+		removed, err = store.Clear(flags.Arg(0), flags.Arg(1))
+	default:
+		return fmt.Errorf("usage: pd cache clear <dep> [version] | --all")
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 && !*all {
+		return fmt.Errorf("no cached docs found for %s", flags.Arg(0))
+	}
+	printRemoved(removed)
+	return nil
+}
+
+func printRemoved(removed []cache.Entry) {
+	var freed int64
+	for _, entry := range removed {
+		fmt.Printf("Removed %s %s (%s)\n", entry.Name, entry.Version, entry.Ecosystem)
+		freed += entry.Size
+	}
+	fmt.Printf("%d doc sets removed, %s freed\n", len(removed), cache.FormatSize(freed))
+}
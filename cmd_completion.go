@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/favorites"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// completionShells are the shells `pd completion` writes scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// The scripts ask `pd __complete <words before the cursor> <current word>`
+// for candidates, printed one per line as name<TAB>description
+const bashCompletion = `# bash completion for pd, load with: source <(pd completion bash)
+_pd() {
+    local cur words cword
+    # Keep --project=apps/web and name:version as one word when
+    # bash-completion is loaded, instead of splitting on COMP_WORDBREAKS
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur=${COMP_WORDS[COMP_CWORD]} words=("${COMP_WORDS[@]}") cword=$COMP_CWORD
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($(pd __complete "${words[@]:1:cword-1}" "$cur" 2>/dev/null | cut -f1))
+    # Readline still only replaces the text after the last = or :, so drop
+    # what comes before it from the candidates
+    if [[ $cur != "${COMP_WORDS[COMP_CWORD]}" ]]; then
+        local prefix=${cur%"${cur##*[=:]}"}
+        COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+    fi
+}
+complete -F _pd pd
+`
+
+const zshCompletion = `#compdef pd
+# zsh completion for pd, load with: source <(pd completion zsh)
+_pd() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(pd __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] && candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+    done
+    _describe 'pd' candidates
+}
+if [[ $funcstack[1] == _pd ]]; then
+    _pd "$@"
+else
+    compdef _pd pd
+fi
+`
+
+const fishCompletion = `# fish completion for pd, load with: pd completion fish | source
+function __pd_complete
+    set -l tokens (commandline -opc)
+    pd __complete $tokens[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c pd -f -a '(__pd_complete)'
+`
+
+// runCompletion implements `pd completion` and returns the process exit code
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: pd completion <%s>\n", strings.Join(completionShells, "|"))
+		return 2
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown shell %q, expected one of %s\n", args[0], strings.Join(completionShells, ", "))
+		return 2
+	}
+	return 0
+}
+
+// candidate is a completion with an optional description
+type candidate struct {
+	value       string
+	description string
+}
+
+// runComplete implements the hidden `pd __complete` used by the completion
+// scripts. Its last argument is the word being completed.
+func runComplete(args []string) int {
+	current := ""
+	if len(args) > 0 {
+		current, args = args[len(args)-1], args[:len(args)-1]
+	}
+	for _, c := range completions(args, current) {
+		if strings.HasPrefix(strings.ToLower(c.value), strings.ToLower(current)) {
+			fmt.Printf("%s\t%s\n", c.value, c.description)
+		}
+	}
+	return 0
+}
+
+// completions returns the candidates for the word after args
+func completions(args []string, current string) []candidate {
+	if name, _, found := strings.Cut(current, "="); found && takesValue(name) {
+		// --project=<value> completes as a single word
+		result := []candidate{}
+		for _, c := range flagValues(name) {
+			result = append(result, candidate{name + "=" + c.value, c.description})
+		}
+		return result
+	}
+	if strings.HasPrefix(current, "-") {
+		return nil
+	}
+
+	// Flag values aren't completed, except for the few with fixed choices
+	positional := []string{}
+	for i, arg := range args {
+		switch {
+		case arg != "-" && strings.HasPrefix(arg, "-"):
+		case i > 0 && takesValue(args[i-1]):
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(args) > 0 && takesValue(args[len(args)-1]) {
+		return flagValues(args[len(args)-1])
+	}
+
+	if len(positional) == 0 {
+		return append(commandCandidates(), dependencyCandidates()...)
+	}
+
+	cmd, ok := lookupCommand(positional[0])
+	if !ok {
+		// pd <dep> <keyword>: keywords aren't completed
+		return nil
+	}
+	rest := positional[1:]
+	switch {
+	case len(rest) == 0 && cmd.dependency:
+		return dependencyCandidates()
+	case len(rest) == 0 && cmd.name == "help":
+		return commandCandidates()
+	case len(rest) == 0:
+		return values(cmd.subcommands)
+	case cmd.name == "fav" && len(rest) == 1 && (rest[0] == "remove" || rest[0] == "rm"):
+		return favoriteCandidates(slices.Contains(args, "--global") || slices.Contains(args, "-global"))
+	case cmd.name == "fav" && len(rest) == 1 && rest[0] == "add":
+		return dependencyCandidates()
+	case cmd.name == "config" && len(rest) == 1 && (rest[0] == "get" || rest[0] == "set"):
+		loadPlugins()
+		return values(config.Keys())
+	case cmd.name == "config" && len(rest) == 2 && rest[0] == "set":
+		return configValues(rest[1])
+	}
+	return nil
+}
+
+// valueFlags are the flags of any command that take a value
+var valueFlags = []string{"o", "n", "j", "q", "addr", "older-than", "browser", "project"}
+
+// takesValue reports whether arg is a flag whose value is the next argument
+func takesValue(arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	return name != arg && !strings.Contains(name, "=") && slices.Contains(valueFlags, name)
+}
+
+// flagValues are the choices for the value of flag, for the few with fixed
+// choices
+func flagValues(flag string) []candidate {
+	switch strings.TrimLeft(flag, "-") {
+	case "o":
+		return values(config.OutputFormats)
+	case "project":
+		return projectCandidates()
+	}
+	return nil
+}
+
+// commandCandidates are the commands shown in the help
+func commandCandidates() []candidate {
+	result := []candidate{}
+	for _, cmd := range commands() {
+		if !cmd.hidden && cmd.name != "-" {
+			result = append(result, candidate{cmd.name, cmd.summary})
+		}
+	}
+	return result
+}
+
+// dependencyCandidates are the dependencies of the project in the working
+// directory, with their versions
+func dependencyCandidates() []candidate {
+	loadPlugins()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		return nil
+	}
+	sortDependencies(deps)
+
+	result := []candidate{}
+	seen := map[string]bool{}
+	for _, dep := range deps {
+		if !seen[dep.Name] {
+			seen[dep.Name] = true
+			result = append(result, candidate{dep.Name, dep.Version})
+		}
+	}
+	return result
+}
+
+// favoriteCandidates are the saved favorites of the workspace in the working
+// directory, or the global ones, as `pd fav remove` matches them
+func favoriteCandidates(global bool) []candidate {
+	loadPlugins()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	store, err := favorites.OpenDefault()
+	if err != nil {
+		return nil
+	}
+	root := cwd
+	if ws, err := loadWorkspace(cwd); err == nil {
+		root = ws.Root
+	}
+	favs, err := store.List(root)
+	if err != nil {
+		return nil
+	}
+
+	result := []candidate{}
+	seen := map[string]bool{}
+	for _, fav := range favs {
+		if fav.Global == global && !seen[fav.Name] {
+			seen[fav.Name] = true
+			result = append(result, candidate{fav.Name, fav.Ecosystem})
+		}
+	}
+	return result
+}
+
+// projectCandidates are the projects of the workspace in the working
+// directory, relative to its root
+func projectCandidates() []candidate {
+	loadPlugins()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	ws, err := parser.DiscoverWorkspace(cwd)
+	if err != nil {
+		return nil
+	}
+	// A directory can hold projects of several ecosystems
+	types := map[string][]string{}
+	for _, p := range ws.Projects {
+		types[p.Path] = append(types[p.Path], string(p.Type))
+	}
+	result := []candidate{}
+	for _, path := range ws.Paths() {
+		result = append(result, candidate{path, strings.Join(types[path], ", ")})
+	}
+	return result
+}
+
+// configValues are the choices for settings that have a fixed set of values
+func configValues(key string) []candidate {
+	switch {
+	case key == "output":
+		return values(config.OutputFormats)
+	case strings.HasPrefix(key, "docs."):
+		return values(config.DocsSources)
+	}
+	return nil
+}
+
+func values(names []string) []candidate {
+	result := make([]candidate, 0, len(names))
+	for _, name := range names {
+		result = append(result, candidate{value: name})
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+const configUsage = `Usage: pd config <command>
+
+Commands:
+  list                            Show every setting, its value and where it comes from
+  get <key>                       Print the value of a setting
+  set <key> <value> [--project]   Save a setting globally, or in the project's .pudding.toml
+  set <key> "" [--project]        Remove a setting
+  path [--project]                Print the path of the config file
+
+Settings are read from the global config, the project's .pudding.toml,
+PUDDING_* environment variables and flags, each overriding the one before.
+Lists such as exclude and extra are comma-separated.
+`
+
+// runConfig implements `pd config` and returns the process exit code
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("pd config "+args[0], flag.ContinueOnError)
+	project := flags.Bool("project", false, "Use the project's .pudding.toml instead of the global config")
+	positional, err := parseInterspersed(flags, args[1:])
+	if err != nil {
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		err = configList()
+	case "get":
+		err = configGet(positional)
+	case "set":
+		err = configSet(positional, *project)
+	case "path":
+		var path string
+		if path, err = configPath(*project); err == nil {
+			fmt.Println(path)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(configUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command %q\n\n%s", args[0], configUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func configList() error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+	for _, err := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range cfg.List() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return w.Flush()
+}
+
+func configGet(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: pd config get <key>")
+	}
+
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+	value, err := cfg.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func configSet(args []string, project bool) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: pd config set <key> <value> [--project]")
+	}
+
+	if project && config.GlobalOnly(args[0]) && args[1] != "" {
+		return fmt.Errorf("%s can only be set in the global config", args[0])
+	}
+	path, err := configPath(project)
+	if err != nil {
+		return err
+	}
+	if err := config.Set(path, args[0], args[1]); err != nil {
+		return err
+	}
+	if args[1] == "" {
+		fmt.Printf("Removed %s from %s\n", args[0], path)
+	} else {
+		fmt.Printf("Set %s in %s\n", args[0], path)
+	}
+	return nil
+}
+
+// configPath returns the global config file, or with project the closest
+// .pudding.toml, which is created at the workspace root when missing
+func configPath(project bool) (string, error) {
+	if !project {
+		return config.DefaultPath()
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if path, ok := config.FindProjectFile(cwd); ok {
+		return path, nil
+	}
+	ws, err := parser.DiscoverWorkspace(cwd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(ws.Root, config.ProjectFile), nil
+}
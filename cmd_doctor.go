@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/heycomputer/pudding/internal/doctor"
+)
+
+// runDoctor implements `pd doctor` and returns the process exit code, which
+// is 1 when anything pd needs is missing
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("pd doctor", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	env := doctor.DefaultEnv(cwd)
+	env.Plugins = loadPlugins()
+	results := doctor.Check(env)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	problems := []doctor.Result{}
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, result.Status, result.Detail)
+		if result.Status != doctor.OK {
+			problems = append(problems, result)
+		}
+	}
+	w.Flush()
+
+	if len(problems) == 0 {
+		fmt.Println("\nEverything pd needs is in place")
+		return 0
+	}
+	fmt.Println("\nTo fix:")
+	for _, problem := range problems {
+		fmt.Printf("  %s: %s\n", problem.Name, problem.Fix)
+	}
+	if doctor.Critical(results) {
+		fmt.Fprintln(os.Stderr, "\npd won't work in this project until the failed checks are fixed")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/heycomputer/pudding/internal/favorites"
+)
+
+const favUsage = `Usage: pd fav <command>
+
+Commands:
+  list [--global]          List favorites for this project and global ones
+  add <dep> [--global]     Pin a dependency of this project, or of every project with --global
+  remove <dep> [--global]  Unpin a dependency
+`
+
+// runFav implements `pd fav` and returns the process exit code
+func runFav(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, favUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("pd fav "+args[0], flag.ContinueOnError)
+	global := flags.Bool("global", false, "Apply to favorites shared by every project")
+	positional, err := parseInterspersed(flags, args[1:])
+	if err != nil {
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	store, err := favorites.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		err = favList(store, cwd, *global)
+	case "add":
+		err = favAdd(store, cwd, positional, *global)
+	case "remove", "rm":
+		err = favRemove(store, cwd, positional, *global)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown fav command %q\n\n%s", args[0], favUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func favList(store *favorites.Store, cwd string, globalOnly bool) error {
+	root := cwd
+	if ws, err := loadWorkspace(cwd); err == nil {
+		root = ws.Root
+	}
+
+	favs, err := store.List(root)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tECOSYSTEM\tSCOPE")
+	count := 0
+	for _, fav := range favs {
+		if globalOnly && !fav.Global {
+			continue
+		}
+		scope := "project"
+		if fav.Global {
+			scope = "global"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", fav.Name, fav.Ecosystem, scope)
+		count++
+	}
+	if count == 0 {
+		fmt.Println("No favorites yet; add one with `pd fav add <dep>`")
+		return nil
+	}
+	return w.Flush()
+}
+
+func favAdd(store *favorites.Store, cwd string, args []string, global bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: pd fav add <dep> [--global]")
+	}
+
+	ws, err := loadWorkspace(cwd)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range ws.Dependencies {
+		if strings.EqualFold(dep.Name, args[0]) {
+			if err := store.Add(ws.Root, global, dep.Type, dep.Name); err != nil {
+				return err
+			}
+			fmt.Printf("Added %s to %s\n", dep.Name, favScope(global))
+			return nil
+		}
+	}
+	return fmt.Errorf("no dependency named '%s' in this project", args[0])
+}
+
+func favRemove(store *favorites.Store, cwd string, args []string, global bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: pd fav remove <dep> [--global]")
+	}
+
+	// Favorites may outlive the dependency, so match against what's saved
+	root := cwd
+	if ws, err := loadWorkspace(cwd); err == nil {
+		root = ws.Root
+	}
+	favs, err := store.List(root)
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	for _, fav := range favs {
+		if fav.Global != global || !strings.EqualFold(fav.Name, args[0]) {
+			continue
+		}
+		ok, err := store.Remove(root, global, fav.Ecosystem, fav.Name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			removed = true
+			fmt.Printf("Removed %s from %s\n", fav.Name, favScope(global))
+		}
+	}
+	if !removed {
+		return fmt.Errorf("'%s' is not in %s", args[0], favScope(global))
+	}
+	return nil
+}
+
+func favScope(global bool) string {
+	if global {
+		return "global favorites"
+	}
+	return "this project's favorites"
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/history"
+)
+
+const historyUsage = `Usage: pd history [command]
+
+Commands:
+  list [-n N]        List recently opened docs, newest first (default)
+  open <n>           Reopen entry n from the list
+  remove <n>...      Delete entries from the history
+  clear              Delete the whole history
+
+Run ` + "`pd -`" + ` to reopen the last docs you looked at.
+`
+
+// runHistory implements `pd history` and returns the process exit code
+func runHistory(args []string) int {
+	command := "list"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet("pd history "+command, flag.ContinueOnError)
+	limit := flags.Int("n", 20, "Number of entries to list, 0 for all")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	store, err := history.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "list":
+		err = historyList(store, *limit)
+	case "open":
+		err = historyOpen(store, positional)
+	case "remove", "rm":
+		err = historyRemove(store, positional)
+	case "clear":
+		if err = store.Clear(); err == nil {
+			fmt.Println("Cleared history")
+		}
+	case "help", "-h", "--help":
+		fmt.Print(historyUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown history command %q\n\n%s", command, historyUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runReopen implements `pd -`, reopening the most recently viewed docs
+func runReopen() int {
+	store, err := history.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	last, ok, err := store.Last()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "No history yet; open some docs first")
+		return 1
+	}
+	if err := reopen(*last); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func historyList(store *history.Store, limit int) error {
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No history yet")
+		return nil
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tNAME\tVERSION\tECOSYSTEM\tSEARCH\tOPENED\tPROJECT")
+	for i, entry := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			i+1, entry.Name, entry.Version, entry.Ecosystem, entry.Keywords,
+			entry.OpenedAt.Local().Format(time.DateTime), entry.ProjectRoot)
+	}
+	return w.Flush()
+}
+
+func historyOpen(store *history.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: pd history open <n>")
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	index, err := historyIndex(args[0], len(entries))
+	if err != nil {
+		return err
+	}
+	return reopen(entries[index])
+}
+
+func historyRemove(store *history.Store, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: pd history remove <n>...")
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	// Resolve every number before removing so they refer to the listed order
+	doomed := map[history.Entry]bool{}
+	for _, arg := range args {
+		index, err := historyIndex(arg, len(entries))
+		if err != nil {
+			return err
+		}
+		doomed[entries[index]] = true
+	}
+
+	removed, err := store.Remove(func(entry history.Entry) bool {
+		return doomed[entry]
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d history entries\n", removed)
+	return nil
+}
+
+// historyIndex converts a 1-based number from `pd history list` to an index
+func historyIndex(arg string, count int) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > count {
+		return 0, fmt.Errorf("'%s' is not a history entry; see `pd history list`", arg)
+	}
+	return n - 1, nil
+}
+
+// reopen opens the docs of a history entry again at the same search term
+func reopen(entry history.Entry) error {
+	dep := entry.Dependency()
+	if entry.Keywords != "" {
+		fmt.Printf("Opening documentation for %s %s (%s)...\n", dep.Name, dep.Version, entry.Keywords)
+	} else {
+		fmt.Printf("Opening documentation for %s %s...\n", dep.Name, dep.Version)
+	}
+	if err := docs.FetchAndOpen(&dep, entry.Keywords); err != nil {
+		return fmt.Errorf("failed to open documentation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/selector"
+)
+
+// listedDependency is one dependency as printed by `pd list`
+type listedDependency struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Type        string             `json:"type"`
+	ProjectType parser.ProjectType `json:"project_type"`
+	Direct      bool               `json:"direct"`
+	Source      string             `json:"source,omitempty"`
+	Extras      []string           `json:"extras,omitempty"`
+	Markers     string             `json:"markers,omitempty"`
+	Cached      bool               `json:"cached"`
+	DocPath     string             `json:"doc_path,omitempty"`
+	DocURL      string             `json:"doc_url,omitempty"`
+	Projects    []string           `json:"projects,omitempty"` // sub-projects using it, in a workspace with several
+}
+
+// runList implements `pd list` and returns the process exit code
+func runList(args []string) int {
+	flags := flag.NewFlagSet("pd list", flag.ContinueOnError)
+	includeTransitive := flags.Bool("a", false, "Include transitive dependencies")
+	asJSON := flags.Bool("json", false, "Print a JSON array")
+	asNDJSON := flags.Bool("ndjson", false, "Print one JSON object per line")
+	asTSV := flags.Bool("tsv", false, "Print tab-separated values with a header row")
+	output := flags.String("o", "", "Output format: table, json, ndjson or tsv (default from the output setting)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := listFormat(*output, *asJSON, *asNDJSON, *asTSV)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	sortDependencies(deps)
+	if !*includeTransitive {
+		deps = selector.DirectDependencies(deps)
+	}
+
+	listed := make([]listedDependency, 0, len(deps))
+	for i := range deps {
+		listed = append(listed, describeDependency(&deps[i]))
+	}
+
+	switch format {
+	case "json":
+		err = writeListJSON(os.Stdout, listed)
+	case "ndjson":
+		err = writeListNDJSON(os.Stdout, listed)
+	case "tsv":
+		err = writeListTSV(os.Stdout, listed)
+	default:
+		err = writeListTable(os.Stdout, listed, parser.VersionConflicts(deps))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listFormat picks the output format: --json, --ndjson and --tsv win over -o,
+// which wins over the output setting
+func listFormat(output string, asJSON, asNDJSON, asTSV bool) (string, error) {
+	switch {
+	case asJSON:
+		return "json", nil
+	case asNDJSON:
+		return "ndjson", nil
+	case asTSV:
+		return "tsv", nil
+	}
+	if output == "" {
+		cfg, err := config.Current()
+		if err != nil {
+			return "", err
+		}
+		output = cfg.Output
+	}
+	if output == "" {
+		return "table", nil
+	}
+	if !slices.Contains(config.OutputFormats, output) {
+		return "", fmt.Errorf("unknown output format %q, expected one of %s", output, strings.Join(config.OutputFormats, ", "))
+	}
+	return output, nil
+}
+
+// describeDependency reports a dependency along with its cached docs, if any.
+// Nothing is fetched, so listing stays fast and works offline.
+func describeDependency(dep *parser.Dependency) listedDependency {
+	listed := listedDependency{
+		Name:        dep.Name,
+		Version:     dep.Version,
+		Type:        dep.Type,
+		ProjectType: parser.ProjectTypeFor(dep.Type),
+		Direct:      !dep.Transitive,
+		Source:      dep.Source,
+		Extras:      dep.Extras,
+		Markers:     dep.Markers,
+		Projects:    dep.Projects,
+	}
+	if entry, ok := docs.Cached(dep); ok {
+		listed.Cached = true
+		listed.DocPath = entry.Path
+		if url, err := docs.URL(entry, ""); err == nil {
+			listed.DocURL = url
+		}
+	}
+	return listed
+}
+
+func writeListJSON(w io.Writer, listed []listedDependency) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(listed); err != nil {
+		return fmt.Errorf("failed to encode dependencies: %w", err)
+	}
+	return nil
+}
+
+func writeListNDJSON(w io.Writer, listed []listedDependency) error {
+	encoder := json.NewEncoder(w)
+	for _, dep := range listed {
+		if err := encoder.Encode(dep); err != nil {
+			return fmt.Errorf("failed to encode dependencies: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeListTSV(w io.Writer, listed []listedDependency) error {
+	fields := func(values ...string) string {
+		for i, value := range values {
+			values[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(value)
+		}
+		return strings.Join(values, "\t") + "\n"
+	}
+
+	if _, err := io.WriteString(w, fields("name", "version", "type", "project_type", "scope", "cached", "doc_url", "projects", "extras", "markers")); err != nil {
+		return err
+	}
+	for _, dep := range listed {
+		line := fields(dep.Name, dep.Version, dep.Type, string(dep.ProjectType), dependencyScope(dep.Direct), fmt.Sprint(dep.Cached), dep.DocURL, strings.Join(dep.Projects, ","), strings.Join(dep.Extras, ","), dep.Markers)
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeListTable prints a table, with the extras requested after each name,
+// the environment markers and the sub-projects using each dependency in a
+// workspace, and the dependencies they use at different versions below
+func writeListTable(w io.Writer, listed []listedDependency, conflicts [][]parser.Dependency) error {
+	workspace := slices.ContainsFunc(listed, func(dep listedDependency) bool { return len(dep.Projects) > 0 })
+	markers := slices.ContainsFunc(listed, func(dep listedDependency) bool { return dep.Markers != "" })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "NAME\tVERSION\tTYPE\tSCOPE\tDOCS"
+	if markers {
+		header += "\tMARKERS"
+	}
+	if workspace {
+		header += "\tPROJECTS"
+	}
+	fmt.Fprintln(tw, header)
+	for _, dep := range listed {
+		status := "-"
+		if dep.Cached {
+			status = "cached"
+		}
+		name := dep.Name
+		if len(dep.Extras) > 0 {
+			name += "[" + strings.Join(dep.Extras, ",") + "]"
+		}
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", name, dep.Version, dep.Type, dependencyScope(dep.Direct), status)
+		if markers {
+			row += "\t" + cmp.Or(dep.Markers, "-")
+		}
+		if workspace {
+			row += "\t" + strings.Join(dep.Projects, ", ")
+		}
+		fmt.Fprintln(tw, row)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nVersions differing between projects:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, group := range conflicts {
+		row := "  " + group[0].Name
+		for _, dep := range group {
+			row += fmt.Sprintf("\t%s (%s)", dep.Version, strings.Join(dep.Projects, ", "))
+		}
+		fmt.Fprintln(tw, row)
+	}
+	return tw.Flush()
+}
+
+func dependencyScope(direct bool) string {
+	if direct {
+		return "direct"
+	}
+	return "transitive"
+}
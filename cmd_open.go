@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/selector"
+	"github.com/heycomputer/pudding/internal/tui"
+)
+
+// runOpen implements `pd open`, also run by `pd [query] [keyword]`, and
+// returns the process exit code
+func runOpen(args []string) int {
+	// Parse command line flags
+	flags := flag.NewFlagSet("pd open", flag.ContinueOnError)
+	query := flags.String("q", "", "Query/filter for dependency name")
+	includeTransitive := flags.Bool("a", false, "Include transitive dependencies")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	// If there's a positional argument, use it as the query
+	if len(positional) > 0 {
+		*query = positional[0]
+	}
+
+	// Get optional search keyword from second positional argument
+	var searchKeyword string
+	if len(positional) > 1 {
+		searchKeyword = positional[1]
+	}
+
+	// Get current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	// Parse project dependencies
+	ws, err := loadWorkspace(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	deps := ws.Dependencies
+
+	// Sort dependencies by name for better UX
+	sortDependencies(deps)
+
+	// Hide transitive dependencies unless asked for
+	visibleDeps := deps
+	if !*includeTransitive {
+		visibleDeps = selector.DirectDependencies(deps)
+	}
+
+	if len(visibleDeps) == 0 {
+		fmt.Fprintf(os.Stderr, "No dependencies found in project\n")
+		return 1
+	}
+
+	// Filter dependencies if query is provided, skipping the UI on an exact match
+	var selectedDep *parser.Dependency
+	if *query != "" {
+		candidates, name := qualifiedDependencies(visibleDeps, *query)
+		filteredDeps := selector.FilterDependencies(candidates, name)
+		exact := 0
+		for i := range filteredDeps {
+			if strings.EqualFold(filteredDeps[i].Name, name) {
+				if exact == 0 {
+					selectedDep = &filteredDeps[i]
+				}
+				exact++
+			}
+		}
+		// Sub-projects using different versions, or ecosystems sharing the
+		// name, pick one from the list
+		if exact > 1 {
+			selectedDep = nil
+		}
+
+		// A module, class or function opens straight to its docs
+		if exact == 0 && docs.LooksLikeSymbol(*query) {
+			matches, err := docs.FindSymbol(deps, *query)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+			if len(matches) > 0 {
+				return openSymbol(*query, matches)
+			}
+		}
+
+		if len(filteredDeps) == 0 {
+			fmt.Fprintf(os.Stderr, "No dependencies matching '%s' found\n", *query)
+			return 1
+		}
+	}
+
+	// Let user select a dependency
+	if selectedDep == nil {
+		selection, err := tui.Run(tui.Options{
+			Deps:           deps,
+			ProjectRoot:    ws.Root,
+			Query:          *query,
+			ShowTransitive: *includeTransitive,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if selection == nil {
+			fmt.Fprintf(os.Stderr, "Selection cancelled\n")
+			return 1
+		}
+		selectedDep = selection.Dep
+		if searchKeyword == "" {
+			searchKeyword = selection.Keywords
+		}
+	}
+
+	// Fetch and open documentation
+	fmt.Printf("Opening documentation for %s %s...\n", selectedDep.Name, selectedDep.Version)
+	if err := docs.FetchAndOpen(selectedDep, searchKeyword); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open documentation: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openSymbol opens the docs for a symbol, asking which one is meant when it
+// matches several, and returns the process exit code
+func openSymbol(symbol string, matches []docs.SymbolMatch) int {
+	match := matches[0]
+	if len(matches) > 1 {
+		choices := make([]tui.Choice, len(matches))
+		for i, m := range matches {
+			choices[i] = tui.Choice{
+				Title:  m.Name,
+				Detail: fmt.Sprintf("%s %s %s", m.Kind, m.Dep.Name, m.Dep.Version),
+			}
+		}
+		chosen, err := tui.Pick(fmt.Sprintf("%d matches for %s", len(matches), symbol), choices)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if chosen < 0 {
+			fmt.Fprintf(os.Stderr, "Selection cancelled\n")
+			return 1
+		}
+		match = matches[chosen]
+	}
+
+	fmt.Printf("Opening documentation for %s in %s %s...\n", match.Name, match.Dep.Name, match.Dep.Version)
+	if err := docs.OpenSymbol(match); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/search"
+	"github.com/heycomputer/pudding/internal/selector"
+)
+
+// runSearch implements `pd search` and returns the process exit code
+func runSearch(args []string) int {
+	flags := flag.NewFlagSet("pd search", flag.ContinueOnError)
+	includeTransitive := flags.Bool("a", false, "Include transitive dependencies")
+	limit := flags.Int("n", 20, "Maximum number of results (0 for all)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	query := strings.Join(flags.Args(), " ")
+	if query == "" {
+		fmt.Fprintf(os.Stderr, "Usage: pd search [-a] [-n limit] <term>...\n")
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if !*includeTransitive {
+		deps = selector.DirectDependencies(deps)
+	}
+
+	index, err := search.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Only search the doc sets for the versions the project has locked
+	segments := []*search.Segment{}
+	missing := 0
+	for i := range deps {
+		entry, ok := docs.Cached(&deps[i])
+		if !ok {
+			// pd sync skips the language itself, so it wouldn't help there
+			if !deps[i].IsLanguage() {
+				missing++
+			}
+			continue
+		}
+		segment, built, err := index.Segment(*entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		if built {
+			fmt.Fprintf(os.Stderr, "Indexed %s %s\n", entry.Name, entry.Version)
+		}
+		segments = append(segments, segment)
+	}
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "%d dependencies have no local docs yet; run `pd sync` to fetch them\n", missing)
+	}
+
+	hits := search.Search(segments, query, *limit)
+	if len(hits) == 0 {
+		fmt.Fprintf(os.Stderr, "No results for '%s'\n", query)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tDEPENDENCY\tVERSION\tLOCATION")
+	for _, hit := range hits {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", hit.Name, hit.Kind, hit.Dep, hit.Version, hit.Location)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/search"
+	"github.com/heycomputer/pudding/internal/server"
+)
+
+// runServe implements `pd serve` and returns the process exit code
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("pd serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:6464", "Address to listen on")
+	open := flags.Bool("open", false, "Open the index page in the browser")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	// Outside a project the server lists every cached doc set instead
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		deps = nil
+	}
+	sortDependencies(deps)
+
+	store, err := cache.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	index, err := search.OpenDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to listen on %s: %v\n", *addr, err)
+		return 1
+	}
+	baseURL := "http://" + listener.Addr().String()
+
+	fmt.Printf("Serving docs on %s\n", baseURL)
+	fmt.Printf("Set %s=%s to open docs here instead of from files\n", docs.ServerEnv, baseURL)
+	if *open {
+		if err := docs.OpenURL(baseURL + "/"); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if err := http.Serve(listener, server.New(store, index, deps)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
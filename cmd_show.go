@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/termdoc"
+)
+
+// runShow implements `pd show` and returns the process exit code
+func runShow(args []string) int {
+	flags := flag.NewFlagSet("pd show", flag.ContinueOnError)
+	noPager := flags.Bool("no-pager", false, "Print to stdout instead of paging")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		fmt.Fprintf(os.Stderr, "Usage: pd show [--no-pager] <dep> [Module.function]\n")
+		return 2
+	}
+	var symbol string
+	if len(positional) == 2 {
+		symbol = positional[1]
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	dep, err := resolveDependency(deps, positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	page, err := docs.LocatePage(dep, symbol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	file, err := os.Open(page.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read docs: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	tty := term.IsTerminal(os.Stdout.Fd())
+	width := 80
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		width = min(w, 100)
+	}
+	text, err := termdoc.Render(file, termdoc.Options{
+		Width:    width,
+		Color:    tty && os.Getenv("NO_COLOR") == "",
+		Fragment: page.Fragment,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !tty || *noPager {
+		fmt.Print(text)
+		return 0
+	}
+	if err := pageText(text); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// pageText shows text through $PAGER, or less, keeping colors and quitting
+// straight away when it fits on one screen
+func pageText(text string) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		// The shell exits with 127 when the pager isn't installed, in which
+		// case printing is better than nothing
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 127 {
+			fmt.Print(text)
+			return nil
+		}
+		return fmt.Errorf("failed to run pager %q: %w", pager, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/selector"
+)
+
+// runSync implements `pd sync` and returns the process exit code
+func runSync(args []string) int {
+	flags := flag.NewFlagSet("pd sync", flag.ContinueOnError)
+	includeTransitive := flags.Bool("a", false, "Include transitive dependencies")
+	workers := flags.Int("j", runtime.NumCPU(), "Number of dependencies to fetch concurrently")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if !*includeTransitive {
+		deps = selector.DirectDependencies(deps)
+	}
+	// Languages aren't packages with docs to fetch
+	deps = slices.DeleteFunc(deps, parser.Dependency.IsLanguage)
+	if len(deps) == 0 {
+		fmt.Fprintf(os.Stderr, "No dependencies found in project\n")
+		return 1
+	}
+
+	fmt.Printf("Syncing docs for %d dependencies...\n", len(deps))
+
+	finished := 0
+	results := docs.Sync(deps, *workers, func(result docs.SyncResult) {
+		finished++
+		fmt.Printf("[%*d/%d] %-7s %s %s\n", len(fmt.Sprint(len(deps))), finished, len(deps), result.Status, result.Dep.Name, result.Dep.Version)
+	})
+
+	counts := map[docs.SyncStatus]int{}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	if counts[docs.SyncFailed] > 0 {
+		fmt.Fprintf(os.Stderr, "\nFailed to sync:\n")
+		for _, result := range results {
+			if result.Status == docs.SyncFailed {
+				fmt.Fprintf(os.Stderr, "  %s %s: %v\n", result.Dep.Name, result.Dep.Version, result.Err)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d fetched, %d already cached, %d failed\n", counts[docs.SyncFetched], counts[docs.SyncCached], counts[docs.SyncFailed])
+
+	if counts[docs.SyncFailed] > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/selector"
+)
+
+// runURL implements `pd url` and returns the process exit code. Only the URL
+// goes to stdout so the output can be piped or captured by editors.
+func runURL(args []string) int {
+	flags := flag.NewFlagSet("pd url", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: pd url <dep> [keywords]\n")
+		return 2
+	}
+	name := flags.Arg(0)
+	keywords := strings.Join(flags.Args()[1:], " ")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
+		return 1
+	}
+
+	deps, err := projectDependencies(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	dep, err := resolveDependency(deps, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	url, err := docs.ResolveURL(dep, keywords)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Println(url)
+	return 0
+}
+
+// resolveDependency finds the dependency a name refers to without asking:
+// an exact name wins, otherwise the filter has to match exactly one. The name
+// may be qualified with its type, e.g. gem/json, when ecosystems share it.
+func resolveDependency(deps []parser.Dependency, name string) (*parser.Dependency, error) {
+	deps, name = qualifiedDependencies(deps, name)
+	matches := selector.FilterDependencies(deps, name)
+	exact := []*parser.Dependency{}
+	for i := range matches {
+		if strings.EqualFold(matches[i].Name, name) {
+			exact = append(exact, &matches[i])
+		}
+	}
+	switch len(exact) {
+	case 0:
+	case 1:
+		return exact[0], nil
+	default:
+		types := []string{}
+		for _, dep := range exact {
+			if !slices.Contains(types, dep.Type) {
+				types = append(types, dep.Type)
+			}
+		}
+		if len(types) > 1 {
+			return nil, fmt.Errorf("'%s' is a dependency of several ecosystems: %s; qualify it as type/name, e.g. %s/%s", name, strings.Join(types, ", "), types[0], name)
+		}
+		versions := []string{}
+		for _, dep := range exact {
+			versions = append(versions, fmt.Sprintf("%s (%s)", dep.Version, strings.Join(dep.Projects, ", ")))
+		}
+		return nil, fmt.Errorf("'%s' is used at several versions: %s; pick a project with --project", name, strings.Join(versions, ", "))
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no dependencies matching '%s' found", name)
+	case 1:
+		return &matches[0], nil
+	default:
+		candidates := []string{}
+		for _, dep := range matches {
+			candidates = append(candidates, dep.Name)
+		}
+		return nil, fmt.Errorf("'%s' matches several dependencies: %s", name, strings.Join(candidates, ", "))
+	}
+}
+
+// qualifiedDependencies narrows deps to one type when name is qualified with
+// it, as in gem/json, and returns the bare name
+func qualifiedDependencies(deps []parser.Dependency, name string) ([]parser.Dependency, string) {
+	depType, bare, ok := strings.Cut(name, "/")
+	if !ok || bare == "" || parser.ProjectTypeFor(depType) == parser.ProjectTypeUnknown {
+		return deps, name
+	}
+	result := []parser.Dependency{}
+	for _, dep := range deps {
+		if dep.Type == depType {
+			result = append(result, dep)
+		}
+	}
+	return result, bare
+}
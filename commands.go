@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+)
+
+// version, commit and date are set at build time by goreleaser
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+// command is a pd subcommand
+type command struct {
+	name        string
+	aliases     []string
+	args        string // argument synopsis shown in the help
+	summary     string
+	subcommands []string // completed after the command's name
+	dependency  bool     // whether the first argument names a dependency, for completion
+	hidden      bool     // left out of the help and completion
+	plugins     bool     // loads the provider plugins first, to read projects, open their docs or check the config
+	run         func(args []string) int
+}
+
+// commands lists pd's subcommands in the order the help shows them. It's a
+// function rather than a variable because the help command refers back to it.
+func commands() []command {
+	return []command{
+		{name: "open", args: "[-a] [query] [keyword]", summary: "Open docs for a dependency, picking it from a list unless query names one", dependency: true, plugins: true, run: runOpen},
+		{name: "-", summary: "Reopen the last docs you viewed", plugins: true, run: func([]string) int { return runReopen() }},
+		{name: "list", args: "[-a] [-o format]", summary: "List dependencies and their cached docs", plugins: true, run: runList},
+		{name: "url", args: "<dep> [keyword]", summary: "Print the docs URL for a dependency", dependency: true, plugins: true, run: runURL},
+		{name: "show", args: "<dep> [symbol]", summary: "Read docs in the terminal", dependency: true, plugins: true, run: runShow},
+		{name: "search", args: "[-a] [-n limit] <term>...", summary: "Search the docs of every dependency", plugins: true, run: runSearch},
+		{name: "sync", args: "[-a] [-j workers]", summary: "Fetch docs for every direct dependency", plugins: true, run: runSync},
+		{name: "cache", args: "<command>", summary: "Manage the docs cache", subcommands: []string{"list", "size", "prune", "clear"}, plugins: true, run: runCache},
+		{name: "serve", args: "[-addr host:port] [-open]", summary: "Serve cached docs over HTTP", plugins: true, run: runServe},
+		{name: "fav", args: "<command>", summary: "Manage favorite dependencies", subcommands: []string{"list", "add", "remove"}, plugins: true, run: runFav},
+		{name: "history", args: "[command]", summary: "List and reopen docs you viewed", subcommands: []string{"list", "open", "remove", "clear"}, plugins: true, run: runHistory},
+		{name: "config", args: "<command>", summary: "Show and change settings", subcommands: []string{"list", "get", "set", "path"}, plugins: true, run: runConfig},
+		{name: "doctor", summary: "Check the tools, settings and network pd needs", plugins: true, run: runDoctor},
+		{name: "completion", args: "<bash|zsh|fish>", summary: "Print a shell completion script", subcommands: completionShells, run: runCompletion},
+		{name: "version", aliases: []string{"--version"}, summary: "Print the version", run: runVersion},
+		{name: "help", aliases: []string{"-h", "--help"}, args: "[command]", summary: "Show help for pd or a command", run: runHelp},
+		{name: "__complete", hidden: true, run: runComplete},
+	}
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, returning the positional ones. Everything after "--" is
+// positional.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// lookupCommand finds a command by name or alias
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// runHelp implements `pd help` and returns the process exit code
+func runHelp(args []string) int {
+	if len(args) > 0 {
+		cmd, ok := lookupCommand(args[0])
+		if !ok || cmd.hidden {
+			fmt.Fprintf(os.Stderr, "Unknown command %q\n", args[0])
+			return 2
+		}
+		fmt.Printf("Usage: pd %s %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+		return 0
+	}
+
+	fmt.Print(`pd opens the docs for your project's dependencies at the versions it uses.
+
+Usage:
+  pd [query] [keyword]    Same as pd open
+  pd <command> [arguments]
+
+Commands:
+`)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands() {
+		if !cmd.hidden {
+			fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+		}
+	}
+	w.Flush()
+	fmt.Print(`
+Flags for every command:
+  --browser <command>     Open docs with command, or "print" to print URLs
+  --project <path>        Use one project of a monorepo or umbrella
+
+A dependency named like a command opens with pd open <dep>.
+Run pd <command> -h for a command's flags.
+`)
+	return 0
+}
+
+// runVersion implements `pd version` and returns the process exit code
+func runVersion(args []string) int {
+	v := version
+	// Builds from `go install` carry their module version instead
+	if info, ok := debug.ReadBuildInfo(); ok && v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+	if commit == "none" {
+		fmt.Printf("pd %s\n", v)
+	} else {
+		fmt.Printf("pd %s (%s, %s)\n", v, commit, date)
+	}
+	return 0
+}
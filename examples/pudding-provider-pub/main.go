@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type request struct {
+	Method     string      `json:"method"`
+	Dir        string      `json:"dir"`
+	Dependency *dependency `json:"dependency"`
+	Keywords   string      `json:"keywords"`
+}
+
+type dependency struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Source      string `json:"source,omitempty"`
+	Dir         string `json:"dir,omitempty"`
+	Transitive  bool   `json:"transitive,omitempty"`
+	ProjectRoot string `json:"project_root,omitempty"`
+}
+
+// main answers one request of pd, which runs the plugin once per request
+// with it on stdin, see internal/external for the protocol. This plugin
+// teaches pd Dart projects and their pub packages.
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fail(fmt.Errorf("failed to decode the request: %w", err))
+	}
+	resp, err := handle(req)
+	if err != nil {
+		fail(err)
+	}
+	json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+// fail answers with an error, which pd shows to the user
+func fail(err error) {
+	json.NewEncoder(os.Stdout).Encode(map[string]string{"error": err.Error()})
+	os.Exit(1)
+}
+
+func handle(req request) (any, error) {
+	if (req.Method == "fetch" || req.Method == "url") && req.Dependency == nil {
+		return nil, fmt.Errorf("%s needs a dependency", req.Method)
+	}
+	switch req.Method {
+	case "describe":
+		return map[string]any{
+			"protocol":        1,
+			"project_type":    "dart",
+			"dependency_type": "pub",
+			"label":           "dartdoc",
+			"manifests":       []string{"pubspec.yaml"},
+			"lockfiles":       []string{"pubspec.lock"},
+		}, nil
+	case "dependencies":
+		deps, err := parseLockfile(req.Dir)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]dependency{"dependencies": deps}, nil
+	case "fetch":
+		path, err := fetchDocs(req.Dependency)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"path": path}, nil
+	case "url":
+		if req.Dependency.Source != "" && req.Dependency.Source != "hosted" {
+			return nil, fmt.Errorf("%s is a %s package, not on pub.dev", req.Dependency.Name, req.Dependency.Source)
+		}
+		return map[string]string{"url": fmt.Sprintf("https://pub.dev/documentation/%s/%s/",
+			url.PathEscape(req.Dependency.Name), url.PathEscape(req.Dependency.Version))}, nil
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+}
+
+// lockfile is the part of pubspec.lock listing the resolved packages
+type lockfile struct {
+	Packages map[string]struct {
+		Dependency  string    `yaml:"dependency"` // "direct main", "direct dev" or "transitive"
+		Description yaml.Node `yaml:"description"`
+		Source      string    `yaml:"source"` // hosted, git, path or sdk
+		Version     string    `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+func parseLockfile(dir string) ([]dependency, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "pubspec.lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pubspec.lock, run `dart pub get`: %w", err)
+	}
+	var lock lockfile
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.lock: %w", err)
+	}
+
+	deps := []dependency{}
+	for name, pkg := range lock.Packages {
+		// Packages of the Flutter SDK come with it rather than from pub
+		if pkg.Source == "sdk" {
+			continue
+		}
+		var description struct {
+			Path string `yaml:"path"`
+			URL  string `yaml:"url"`
+		}
+		pkg.Description.Decode(&description)
+
+		dep := dependency{Name: name, Version: pkg.Version, Source: pkg.Source, Transitive: pkg.Dependency == "transitive"}
+		switch pkg.Source {
+		case "path":
+			dep.Dir = description.Path
+			if !filepath.IsAbs(dep.Dir) {
+				dep.Dir = filepath.Join(dir, dep.Dir)
+			}
+		case "hosted":
+			host := strings.TrimPrefix(strings.TrimPrefix(description.URL, "https://"), "http://")
+			if host == "" {
+				host = "pub.dev"
+			}
+			host, _, _ = strings.Cut(host, "/")
+			dep.Dir = filepath.Join(pubCache(), "hosted", host, name+"-"+pkg.Version)
+		}
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, nil
+}
+
+func pubCache() string {
+	if dir := os.Getenv("PUB_CACHE"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".pub-cache")
+}
+
+// fetchDocs returns the dartdoc a package ships, or renders its README. A
+// fuller plugin would run `dart doc` in a resolved copy of the package.
+func fetchDocs(dep *dependency) (string, error) {
+	dir := dep.Dir
+	if dir == "" {
+		dir = filepath.Join(pubCache(), "hosted", "pub.dev", dep.Name+"-"+dep.Version)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "doc", "api", "index.html")); err == nil {
+		return filepath.Join(dir, "doc", "api"), nil
+	}
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	if err != nil {
+		return "", fmt.Errorf("no docs for %s %s in %s, run `dart pub get`", dep.Name, dep.Version, dir)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	out := filepath.Join(cacheDir, "pudding-provider-pub", dep.Name+"-"+dep.Version)
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	page := fmt.Sprintf("<!DOCTYPE html>\n<title>%[1]s %[2]s</title>\n<h1 id=\"%[1]s\">%[1]s %[2]s</h1>\n<pre>%[3]s</pre>\n",
+		html.EscapeString(dep.Name), html.EscapeString(dep.Version), html.EscapeString(string(readme)))
+	if err := os.WriteFile(filepath.Join(out, "index.html"), []byte(page), 0644); err != nil {
+		return "", fmt.Errorf("failed to write the docs of %s: %w", dep.Name, err)
+	}
+	return out, nil
+}
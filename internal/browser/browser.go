@@ -0,0 +1,239 @@
+package browser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/config"
+)
+
+// PrintOnly is the browser setting that prints URLs instead of opening them
+const PrintOnly = "print"
+
+// command is a way of opening URLs
+type command struct {
+	template string // %s stands for the URL, which is appended otherwise
+	// Platform openers such as xdg-open exit once the browser has the URL and
+	// their output explains failures. The user's commands are started in the
+	// background, except for text browsers such as lynx, which run in the
+	// terminal.
+	opener bool
+}
+
+// textBrowsers are the browsers that take over the terminal until they quit
+var textBrowsers = []string{"lynx", "w3m", "links", "links2", "elinks", "browsh", "carbonyl"}
+
+// Launcher opens URLs with the first of its commands that is installed
+type Launcher struct {
+	Out       io.Writer // where print-only mode writes URLs
+	commands  []command
+	printOnly bool
+	lookPath  func(file string) (string, error)
+}
+
+// New returns the launcher for a browser setting, a command whose %s stands
+// for the URL. An empty setting uses the platform's opener.
+func New(setting string) *Launcher {
+	return newLauncher([]string{setting}, runtime.GOOS, isWSL())
+}
+
+// NewList returns the launcher for a list of commands to try, separated by
+// the path list separator as in $BROWSER
+func NewList(setting string) *Launcher {
+	return newLauncher(filepath.SplitList(setting), runtime.GOOS, isWSL())
+}
+
+func newLauncher(templates []string, goos string, wsl bool) *Launcher {
+	l := &Launcher{Out: os.Stdout, lookPath: exec.LookPath}
+	for _, template := range templates {
+		switch template {
+		case "":
+		case PrintOnly:
+			l.printOnly = true
+		default:
+			l.commands = append(l.commands, command{template: template})
+		}
+	}
+	if len(l.commands) == 0 {
+		l.commands = platformCommands(goos, wsl)
+	}
+	return l
+}
+
+// platformCommands are the default openers of an OS, which hand URLs to the
+// user's default browser
+func platformCommands(goos string, wsl bool) []command {
+	switch goos {
+	case "darwin":
+		return []command{{template: "open", opener: true}}
+	case "windows":
+		return []command{{template: "rundll32 url.dll,FileProtocolHandler", opener: true}}
+	}
+
+	commands := []command{}
+	// Under WSL xdg-open would look for a Linux browser, while wslview
+	// opens the Windows default one
+	if wsl {
+		commands = append(commands, command{template: "wslview", opener: true})
+	}
+	return append(commands,
+		command{template: "xdg-open", opener: true},
+		command{template: "gio open", opener: true},
+	)
+}
+
+// isWSL reports whether this is Linux running under the Windows Subsystem
+// for Linux
+func isWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// Open opens url, or prints it in print-only mode
+func (l *Launcher) Open(url string) error {
+	if l.printOnly {
+		_, err := fmt.Fprintln(l.Out, url)
+		return err
+	}
+
+	path, args, opener, err := l.find(url)
+	if err != nil {
+		return err
+	}
+	return run(path, args, opener)
+}
+
+// Command returns the command Open would run, or "print" in print-only mode
+func (l *Launcher) Command() (string, error) {
+	if l.printOnly {
+		return PrintOnly, nil
+	}
+	path, _, _, err := l.find("")
+	return path, err
+}
+
+// find returns the first installed command with its arguments for url
+func (l *Launcher) find(url string) (path string, args []string, opener bool, err error) {
+	tried := []string{}
+	for _, c := range l.commands {
+		args := expand(c.template, url)
+		if len(args) == 0 {
+			continue
+		}
+		path, err := l.lookPath(args[0])
+		if err != nil {
+			tried = append(tried, args[0])
+			continue
+		}
+		return path, args, c.opener, nil
+	}
+	return "", nil, false, fmt.Errorf("no browser launcher found (tried %s): set $BROWSER, browser in the config file or --browser, or use --browser %s to print URLs",
+		strings.Join(tried, ", "), PrintOnly)
+}
+
+func run(path string, args []string, opener bool) error {
+	cmd := exec.Command(path, args[1:]...)
+	if opener {
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to run %s: %w (output: %s)", args[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	if isTextBrowser(path) {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run %s: %w", args[0], err)
+		}
+		return nil
+	}
+
+	// Graphical browsers may run until they're closed, so pd leaves them be
+	// without the terminal, whose output they'd clutter
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return cmd.Process.Release()
+}
+
+// isTextBrowser reports whether the program at path runs in the terminal
+func isTextBrowser(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), ".exe")
+	return slices.Contains(textBrowsers, name)
+}
+
+// expand splits a command template into arguments, substituting the URL for
+// %s or appending it when there's no placeholder. %% is a literal %.
+func expand(template, url string) []string {
+	args := splitCommand(template)
+	replacer := strings.NewReplacer("%s", url, "%%", "%")
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "%s") {
+			substituted = true
+		}
+		args[i] = replacer.Replace(arg)
+	}
+	if !substituted && len(args) > 0 {
+		args = append(args, url)
+	}
+	return args
+}
+
+// splitCommand splits a command on spaces, keeping quoted arguments such as
+// "/Applications/Google Chrome.app" together
+func splitCommand(s string) []string {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// Default returns the launcher for the browser setting of the config, which
+// is a list of commands when it comes from $BROWSER
+func Default() (*Launcher, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Source("browser") == "$BROWSER" {
+		return NewList(cfg.Browser), nil
+	}
+	return New(cfg.Browser), nil
+}
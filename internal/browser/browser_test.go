@@ -0,0 +1,199 @@
+package browser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func templates(l *Launcher) []string {
+	result := []string{}
+	for _, c := range l.commands {
+		result = append(result, c.template)
+	}
+	return result
+}
+
+func TestNew_Setting(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    []string
+	}{
+		{"firefox", []string{"firefox"}},
+		{"chromium --incognito %s", []string{"chromium --incognito %s"}},
+		// Only $BROWSER is a list, so a colon can be part of the command
+		{"open -a 'Safari' http://localhost:8080/%s", []string{"open -a 'Safari' http://localhost:8080/%s"}},
+	}
+	for _, tt := range tests {
+		if got := templates(New(tt.setting)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.setting, tt.want, got)
+		}
+	}
+}
+
+func TestNewList(t *testing.T) {
+	if got := templates(NewList("lynx:w3m")); !reflect.DeepEqual(got, []string{"lynx", "w3m"}) {
+		t.Errorf("Expected both commands, got %v", got)
+	}
+	if got := templates(newLauncher(nil, "linux", false)); !reflect.DeepEqual(got, []string{"xdg-open", "gio open"}) {
+		t.Errorf("Expected the platform opener for an empty setting, got %v", got)
+	}
+}
+
+func TestPlatformCommands(t *testing.T) {
+	tests := []struct {
+		goos string
+		wsl  bool
+		want []string
+	}{
+		{"darwin", false, []string{"open"}},
+		{"linux", false, []string{"xdg-open", "gio open"}},
+		{"linux", true, []string{"wslview", "xdg-open", "gio open"}},
+		{"freebsd", false, []string{"xdg-open", "gio open"}},
+		{"windows", false, []string{"rundll32 url.dll,FileProtocolHandler"}},
+	}
+	for _, tt := range tests {
+		got := templates(newLauncher(nil, tt.goos, tt.wsl))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s (wsl=%v): expected %v, got %v", tt.goos, tt.wsl, tt.want, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	url := "file:///docs/index.html"
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"xdg-open", []string{"xdg-open", url}},
+		{"firefox --new-tab %s", []string{"firefox", "--new-tab", url}},
+		{`"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome" %s`, []string{"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", url}},
+		{"open -a 'Google Chrome'", []string{"open", "-a", "Google Chrome", url}},
+		{"echo 100%% %s", []string{"echo", "100%", url}},
+	}
+	for _, tt := range tests {
+		if got := expand(tt.template, url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestOpen_PrintOnly(t *testing.T) {
+	var out bytes.Buffer
+	l := newLauncher([]string{PrintOnly}, "linux", false)
+	l.Out = &out
+
+	if err := l.Open("file:///docs/index.html"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if out.String() != "file:///docs/index.html\n" {
+		t.Errorf("Expected the URL to be printed, got %q", out.String())
+	}
+}
+
+func TestOpen_NoLauncher(t *testing.T) {
+	l := newLauncher(nil, "linux", false)
+	l.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	err := l.Open("file:///docs/index.html")
+	if err == nil || !strings.Contains(err.Error(), "no browser launcher found (tried xdg-open, gio)") {
+		t.Errorf("Expected a no launcher error, got %v", err)
+	}
+}
+
+func TestOpen_RunsFirstInstalledCommand(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "opener")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLauncher(nil, "linux", false)
+	l.commands = []command{{template: "missing-opener"}, {template: script + " --flag %s", opener: true}}
+
+	if err := l.Open("file:///docs/index.html"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Expected the opener to run: %v", err)
+	}
+	if string(args) != "--flag file:///docs/index.html\n" {
+		t.Errorf("Unexpected arguments %q", args)
+	}
+}
+
+func TestOpen_ReportsOpenerFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "opener")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho 'no method available' >&2\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLauncher(nil, "linux", false)
+	l.commands = []command{{template: script, opener: true}}
+
+	err := l.Open("file:///docs/index.html")
+	if err == nil || !strings.Contains(err.Error(), "no method available") {
+		t.Errorf("Expected the opener's output in the error, got %v", err)
+	}
+}
+
+func TestOpen_ReportsTextBrowserFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "lynx")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLauncher([]string{script + " %s"}, "linux", false)
+	if err := l.Open("file:///docs/index.html"); err == nil || !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("Expected the browser's failure to be reported, got %v", err)
+	}
+}
+
+func TestOpen_StartsBrowserInBackground(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "firefox")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\nsleep 5\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := newLauncher([]string{script}, "linux", false).Open("file:///docs/index.html"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected Open to return without waiting for the browser, took %v", elapsed)
+	}
+	for time.Since(start) < 3*time.Second {
+		if args, err := os.ReadFile(argsFile); err == nil && len(args) > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expected the browser to be started")
+}
+
+func TestCommand(t *testing.T) {
+	l := newLauncher(nil, "linux", false)
+	l.lookPath = func(file string) (string, error) {
+		if file == "gio" {
+			return "/usr/bin/gio", nil
+		}
+		return "", errors.New("not found")
+	}
+	if path, err := l.Command(); err != nil || path != "/usr/bin/gio" {
+		t.Errorf("Command() = %q, %v, want /usr/bin/gio", path, err)
+	}
+
+	if path, err := newLauncher([]string{PrintOnly}, "linux", false).Command(); err != nil || path != PrintOnly {
+		t.Errorf("Command() in print-only mode = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,356 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/config"
+)
+
+// Entry records where the docs for one dependency version live
+type Entry struct {
+	Ecosystem string    `json:"ecosystem"` // dependency type, e.g. "elixir", "gem"
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Path      string    `json:"path"`   // directory holding the docs
+	Source    string    `json:"source"` // how the docs were produced, e.g. "mix hex.docs"
+	FetchedAt time.Time `json:"fetched_at"`
+	Size      int64     `json:"size"`
+}
+
+// Key identifies an entry in the index
+func (e Entry) Key() string {
+	return Key(e.Ecosystem, e.Name, e.Version)
+}
+
+// Key builds the index key for an ecosystem/name/version triple
+func Key(ecosystem, name, version string) string {
+	return ecosystem + "/" + name + "/" + version
+}
+
+// Store is a pudding-owned docs cache backed by an index file
+type Store struct {
+	dir string
+}
+
+// indexMu serializes index updates within the process, e.g. during a parallel sync
+var indexMu sync.Mutex
+
+const (
+	indexFile = "index.json"
+	// lockFile is flocked around index updates so that concurrent pd
+	// processes don't drop each other's entries
+	lockFile = "index.lock"
+)
+
+// DefaultDir returns the cache directory: cache_dir from the config, or
+// pudding under $XDG_CACHE_HOME or the platform default
+func DefaultDir() (string, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		return "", err
+	}
+	if dir := cfg.CacheDirectory(); dir != "" {
+		return dir, nil
+	}
+
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "pudding"), nil
+}
+
+// Open opens (creating if needed) the cache in dir
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// OpenDefault opens the cache in DefaultDir
+func OpenDefault() (*Store, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return Open(dir)
+}
+
+// Dir returns the cache directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// DocsDir returns the directory pudding writes docs it renders itself to
+func (s *Store) DocsDir() string {
+	return filepath.Join(s.dir, "docs")
+}
+
+// Get returns the entry for a dependency version if its docs still exist on disk
+func (s *Store) Get(ecosystem, name, version string) (*Entry, bool) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, false
+	}
+	entry, ok := entries[Key(ecosystem, name, version)]
+	if !ok {
+		return nil, false
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put records an entry, measuring its size and stamping the fetch time if unset
+func (s *Store) Put(entry Entry) error {
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+	if entry.Size == 0 {
+		entry.Size = dirSize(entry.Path)
+	}
+
+	return s.update(func(entries map[string]Entry) {
+		entries[entry.Key()] = entry
+	})
+}
+
+// List returns all entries sorted by name and version
+func (s *Store) List() ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedEntries(entries), nil
+}
+
+// Size returns the total size of all cached docs
+func (s *Store) Size() (int64, error) {
+	entries, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return total, nil
+}
+
+// Prune removes entries fetched before now minus age and returns them
+func (s *Store) Prune(age time.Duration) ([]Entry, error) {
+	cutoff := time.Now().Add(-age)
+	return s.remove(func(entry Entry) bool {
+		return entry.FetchedAt.Before(cutoff)
+	})
+}
+
+// Clear removes every entry for a dependency name, limited to one version
+// when version is not empty, and returns the removed entries
+func (s *Store) Clear(name, version string) ([]Entry, error) {
+	return s.remove(func(entry Entry) bool {
+		return entry.Name == name && (version == "" || entry.Version == version)
+	})
+}
+
+// ClearAll removes every entry and returns them
+func (s *Store) ClearAll() ([]Entry, error) {
+	return s.remove(func(Entry) bool { return true })
+}
+
+// remove drops matching entries from the index and deletes the docs pudding
+// owns. Docs living outside the cache directory (e.g. generated by
+// `mix hex.docs` or rdoc) are left in place.
+func (s *Store) remove(match func(Entry) bool) ([]Entry, error) {
+	removed := []Entry{}
+	err := s.update(func(entries map[string]Entry) {
+		for key, entry := range entries {
+			if match(entry) {
+				removed = append(removed, entry)
+				delete(entries, key)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range removed {
+		if s.owns(entry.Path) {
+			if err := os.RemoveAll(entry.Path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+			}
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Key() < removed[j].Key() })
+	return removed, nil
+}
+
+// owns reports whether path lives inside the cache directory
+func (s *Store) owns(path string) bool {
+	rel, err := filepath.Rel(s.dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+func (s *Store) load() (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	content, err := os.ReadFile(filepath.Join(s.dir, indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+
+	var list []Entry
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse cache index: %w", err)
+	}
+	for _, entry := range list {
+		entries[entry.Key()] = entry
+	}
+	return entries, nil
+}
+
+// update applies fn to the index and writes it back atomically
+func (s *Store) update(fn func(map[string]Entry)) error {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(entries)
+
+	content, err := json.MarshalIndent(sortedEntries(entries), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, indexFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, indexFile)); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	return nil
+}
+
+// lock takes an exclusive lock on the index across processes, blocking
+// until any other holder releases it
+func (s *Store) lock() (func(), error) {
+	file, err := os.OpenFile(filepath.Join(s.dir, lockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cache index: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock cache index: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+func sortedEntries(entries map[string]Entry) []Entry {
+	list := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		if list[i].Version != list[j].Version {
+			return list[i].Version < list[j].Version
+		}
+		return list[i].Ecosystem < list[j].Ecosystem
+	})
+	return list
+}
+
+// dirSize returns the total size of the files under path
+func dirSize(path string) int64 {
+	var total int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// ParseAge parses durations such as "90m", "12h", "30d" or "2w"
+func ParseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, found := strings.CutSuffix(s, suffix); found {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// FormatSize renders a byte count in human readable units
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
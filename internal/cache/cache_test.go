@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func writeDocs(t *testing.T, dir string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), make([]byte, size), 0644); err != nil {
+		t.Fatalf("Failed to write docs: %v", err)
+	}
+}
+
+func TestStore_PutAndGet(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	docs := filepath.Join(store.DocsDir(), "npm", "express@4.18.2")
+	writeDocs(t, docs, 100)
+
+	if err := store.Put(Entry{Ecosystem: "npm", Name: "express", Version: "4.18.2", Path: docs, Source: "node_modules"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	entry, ok := store.Get("npm", "express", "4.18.2")
+	if !ok {
+		t.Fatal("Expected cache hit, got miss")
+	}
+	if entry.Size != 100 {
+		t.Errorf("Expected size 100, got %d", entry.Size)
+	}
+	if entry.FetchedAt.IsZero() {
+		t.Error("Expected FetchedAt to be set")
+	}
+
+	if _, ok := store.Get("npm", "express", "4.17.0"); ok {
+		t.Error("Expected cache miss for other version")
+	}
+}
+
+func TestStore_GetMissingPath(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if err := store.Put(Entry{Ecosystem: "elixir", Name: "phoenix", Version: "1.7.0", Path: "/nonexistent/phoenix"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if _, ok := store.Get("elixir", "phoenix", "1.7.0"); ok {
+		t.Error("Expected cache miss when docs no longer exist")
+	}
+}
+
+func TestStore_PruneAndSize(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	oldDocs := filepath.Join(store.DocsDir(), "old")
+	newDocs := filepath.Join(store.DocsDir(), "new")
+	writeDocs(t, oldDocs, 10)
+	writeDocs(t, newDocs, 20)
+
+	store.Put(Entry{Ecosystem: "gem", Name: "old", Version: "1.0.0", Path: oldDocs, FetchedAt: time.Now().Add(-48 * time.Hour)})
+	store.Put(Entry{Ecosystem: "gem", Name: "new", Version: "1.0.0", Path: newDocs})
+
+	size, err := store.Size()
+	if err != nil || size != 30 {
+		t.Errorf("Expected size 30, got %d (err: %v)", size, err)
+	}
+
+	removed, err := store.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Name != "old" {
+		t.Fatalf("Expected only old to be pruned, got %+v", removed)
+	}
+	if _, err := os.Stat(oldDocs); !os.IsNotExist(err) {
+		t.Error("Expected pruned docs owned by the cache to be deleted")
+	}
+
+	entries, err := store.List()
+	if err != nil || len(entries) != 1 || entries[0].Name != "new" {
+		t.Errorf("Expected only new to remain, got %+v (err: %v)", entries, err)
+	}
+}
+
+func TestStore_ClearKeepsExternalDocs(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	external := t.TempDir()
+	store.Put(Entry{Ecosystem: "elixir", Name: "phoenix", Version: "1.7.0", Path: external})
+	store.Put(Entry{Ecosystem: "elixir", Name: "phoenix", Version: "1.6.0", Path: external})
+	store.Put(Entry{Ecosystem: "elixir", Name: "ecto", Version: "3.11.0", Path: external})
+
+	removed, err := store.Clear("phoenix", "1.7.0")
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("Expected one entry removed, got %+v (err: %v)", removed, err)
+	}
+
+	removed, err = store.Clear("phoenix", "")
+	if err != nil || len(removed) != 1 || removed[0].Version != "1.6.0" {
+		t.Fatalf("Expected phoenix 1.6.0 removed, got %+v (err: %v)", removed, err)
+	}
+
+	if _, err := os.Stat(external); err != nil {
+		t.Error("Expected docs outside the cache directory to be left in place")
+	}
+
+	entries, _ := store.List()
+	if len(entries) != 1 || entries[0].Name != "ecto" {
+		t.Errorf("Expected only ecto to remain, got %+v", entries)
+	}
+}
+
+func TestStore_PutWaitsForIndexLock(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	// Hold the lock through a separate open file, as another pd process would
+	lock, err := os.OpenFile(filepath.Join(store.Dir(), lockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open lock file: %v", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatalf("Failed to take lock: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- store.Put(Entry{Ecosystem: "npm", Name: "express", Version: "4.18.2", Path: t.TempDir()})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Expected Put to wait for the index lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_UN); err != nil {
+		t.Fatalf("Failed to release lock: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := store.Get("npm", "express", "4.18.2"); !ok {
+		t.Error("Expected the entry to be written once the lock was released")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"soon", 0, true},
+		{"-3d", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseAge(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		2048:            "2.0 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for input, expected := range tests {
+		if got := FormatSize(input); got != expected {
+			t.Errorf("FormatSize(%d) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// ProjectFile is the per-project config file, looked up from the working
+// directory towards the filesystem root
+const ProjectFile = ".pudding.toml"
+
+// OutputFormats are the formats `pd list` can print
+var OutputFormats = []string{"table", "json", "ndjson", "tsv"}
+
+// DocsSources are where docs for an ecosystem can come from: built or
+// fetched locally, or the hosted docs of the package
+var DocsSources = []string{"local", "online"}
+
+// globalKeys can't be set by a project's .pudding.toml, as a cloned
+// repository shouldn't pick the command pd runs or where packages come from
+var globalKeys = []string{"browser", "mirrors"}
+
+// Config holds pudding's settings, merged from every layer
+type Config struct {
+	Browser  string            `toml:"browser"`   // command opening URLs, see browser.New
+	CacheDir string            `toml:"cache_dir"` // where docs are cached
+	Output   string            `toml:"output"`    // default format of `pd list`
+	Docs     map[string]string `toml:"docs"`      // docs source by dependency type, e.g. "online" for "gem"
+	Exclude  []string          `toml:"exclude"`   // dependencies never listed, as name or type/name
+	Extra    []string          `toml:"extra"`     // dependencies always listed, as type/name@version
+	Mirrors  Mirrors           `toml:"mirrors"`
+
+	sources  map[string]string // where each key was last set
+	warnings []error           // settings that were ignored
+}
+
+// Mirrors replace the public package registries
+type Mirrors struct {
+	Hex      string `toml:"hex"`      // Hex repository, passed to mix as HEX_MIRROR
+	HexAPI   string `toml:"hex_api"`  // Hex API, e.g. https://hex.example.com/api, passed to mix as HEX_API_URL
+	RubyGems string `toml:"rubygems"` // RubyGems server, e.g. https://gems.example.com
+	GoProxy  string `toml:"goproxy"`  // Go module proxy, passed to go as GOPROXY
+}
+
+// Setting is a key with its value and where that value comes from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // e.g. a file path, $PUDDING_OUTPUT or --browser; "default" when unset
+}
+
+// Keys lists every setting, in the order `pd config list` shows them
+func Keys() []string {
+	keys := []string{"browser", "cache_dir", "output", "exclude", "extra"}
+	for _, ecosystem := range docsTypes() {
+		keys = append(keys, "docs."+ecosystem)
+	}
+	return append(keys, "mirrors.hex", "mirrors.hex_api", "mirrors.rubygems", "mirrors.goproxy")
+}
+
+// docsTypes are the dependency types with a docs.<type> setting, those of
+// every registered ecosystem including provider plugins
+func docsTypes() []string {
+	types := []string{}
+	for _, ecosystem := range parser.Ecosystems() {
+		types = append(types, ecosystem.DepType)
+	}
+	return types
+}
+
+// GlobalOnly reports whether key can only be set in the global config,
+// environment variables and flags
+func GlobalOnly(key string) bool {
+	section, _, _ := strings.Cut(key, ".")
+	return slices.Contains(globalKeys, section)
+}
+
+// DefaultPath returns the global config file, honouring $XDG_CONFIG_HOME
+// before the platform default
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "pudding", "config.toml"), nil
+}
+
+// FindProjectFile returns the closest .pudding.toml at or above dir
+func FindProjectFile(dir string) (string, bool) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(current, ProjectFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+// Load merges, from lowest to highest precedence, the global config file,
+// the project's .pudding.toml, PUDDING_* environment variables and the
+// given overrides, which come from command line flags. The project file
+// can't set the browser or mirrors, see Warnings.
+func Load(dir string, overrides map[string]string) (*Config, error) {
+	cfg := &Config{sources: map[string]string{}}
+
+	globalPath, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.loadFile(globalPath, false); err != nil {
+		return nil, err
+	}
+	if projectPath, ok := FindProjectFile(dir); ok {
+		if err := cfg.loadFile(projectPath, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// $BROWSER is the usual way to pick a browser, but PUDDING_BROWSER wins
+	if value := os.Getenv("BROWSER"); value != "" {
+		if err := cfg.setFrom("$BROWSER", "browser", value); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range Keys() {
+		if value, ok := os.LookupEnv(EnvName(key)); ok {
+			if err := cfg.setFrom("$"+EnvName(key), key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if err := cfg.setFrom("--"+strings.ReplaceAll(key, "_", "-"), key, overrides[key]); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// EnvName returns the environment variable overriding a key, e.g.
+// PUDDING_DOCS_GEM for docs.gem
+func EnvName(key string) string {
+	return "PUDDING_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Config
+	overrides = map[string]string{}
+)
+
+// Override sets a key for the rest of the run, over every other layer. It
+// must be called before the first call to Current.
+func Override(key, value string) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	overrides[key] = value
+}
+
+// Current returns the config for the working directory, loaded on first use
+func Current() (*Config, error) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current != nil {
+		return current, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	cfg, err := Load(cwd, overrides)
+	if err != nil {
+		return nil, err
+	}
+	current = cfg
+	return current, nil
+}
+
+// loadFile merges a config file, ignoring the global-only keys of a project
+// file
+func (c *Config) loadFile(path string, project bool) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// Decoding into the merged config keeps whatever this file doesn't set
+	browser, mirrors := c.Browser, c.Mirrors
+	md, err := toml.Decode(string(content), c)
+	if err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown config key %q in %s", undecoded[0].String(), path)
+	}
+	if project {
+		c.Browser, c.Mirrors = browser, mirrors
+	}
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for _, key := range md.Keys() {
+		if key.String() == "mirrors" {
+			// The table itself, whose keys follow
+			continue
+		}
+		if project && GlobalOnly(key.String()) {
+			c.warnings = append(c.warnings, fmt.Errorf("ignoring %s in %s, it can only be set in the global config", key, path))
+			continue
+		}
+		c.sources[key.String()] = path
+	}
+	return nil
+}
+
+// Warnings returns the settings Load ignored
+func (c *Config) Warnings() []error {
+	return c.warnings
+}
+
+func (c *Config) setFrom(source, key, value string) error {
+	if err := c.set(key, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", source, err)
+	}
+	c.sources[key] = source
+	return nil
+}
+
+// set parses value into key; lists are comma-separated
+func (c *Config) set(key, value string) error {
+	switch key {
+	case "browser":
+		c.Browser = value
+	case "cache_dir":
+		c.CacheDir = value
+	case "output":
+		c.Output = value
+	case "exclude":
+		c.Exclude = splitList(value)
+	case "extra":
+		c.Extra = splitList(value)
+	case "mirrors.hex":
+		c.Mirrors.Hex = value
+	case "mirrors.hex_api":
+		c.Mirrors.HexAPI = value
+	case "mirrors.rubygems":
+		c.Mirrors.RubyGems = value
+	case "mirrors.goproxy":
+		c.Mirrors.GoProxy = value
+	default:
+		ecosystem, ok := strings.CutPrefix(key, "docs.")
+		if !ok || !slices.Contains(docsTypes(), ecosystem) {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if c.Docs == nil {
+			c.Docs = map[string]string{}
+		}
+		c.Docs[ecosystem] = value
+	}
+	return c.validate()
+}
+
+// Get returns the value of key, with lists comma-separated
+func (c *Config) Get(key string) (string, error) {
+	switch key {
+	case "browser":
+		return c.Browser, nil
+	case "cache_dir":
+		return c.CacheDir, nil
+	case "output":
+		return c.Output, nil
+	case "exclude":
+		return strings.Join(c.Exclude, ","), nil
+	case "extra":
+		return strings.Join(c.Extra, ","), nil
+	case "mirrors.hex":
+		return c.Mirrors.Hex, nil
+	case "mirrors.hex_api":
+		return c.Mirrors.HexAPI, nil
+	case "mirrors.rubygems":
+		return c.Mirrors.RubyGems, nil
+	case "mirrors.goproxy":
+		return c.Mirrors.GoProxy, nil
+	}
+	if ecosystem, ok := strings.CutPrefix(key, "docs."); ok && slices.Contains(docsTypes(), ecosystem) {
+		return c.Docs[ecosystem], nil
+	}
+	return "", fmt.Errorf("unknown config key %q", key)
+}
+
+// List returns every setting with its value and source
+func (c *Config) List() []Setting {
+	settings := []Setting{}
+	for _, key := range Keys() {
+		value, _ := c.Get(key)
+		settings = append(settings, Setting{Key: key, Value: value, Source: c.Source(key)})
+	}
+	return settings
+}
+
+// Source returns where the value of key comes from, see Setting
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return "default"
+}
+
+func (c *Config) validate() error {
+	if c.Output != "" && !slices.Contains(OutputFormats, c.Output) {
+		return fmt.Errorf("output must be one of %s, got %q", strings.Join(OutputFormats, ", "), c.Output)
+	}
+	types := docsTypes()
+	for ecosystem, source := range c.Docs {
+		if !slices.Contains(types, ecosystem) {
+			return fmt.Errorf("unknown dependency type docs.%s, expected one of %s", ecosystem, strings.Join(types, ", "))
+		}
+		if source != "" && !slices.Contains(DocsSources, source) {
+			return fmt.Errorf("docs.%s must be one of %s, got %q", ecosystem, strings.Join(DocsSources, ", "), source)
+		}
+	}
+	for _, extra := range c.Extra {
+		if _, err := parseExtra(extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheDirectory returns cache_dir with a leading ~ expanded, or "" when unset
+func (c *Config) CacheDirectory() string {
+	if rest, ok := strings.CutPrefix(c.CacheDir, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return c.CacheDir
+}
+
+// PrefersOnline reports whether docs for a dependency type should be the
+// package's hosted docs rather than local ones
+func (c *Config) PrefersOnline(depType string) bool {
+	return c.Docs[depType] == "online"
+}
+
+// Dependencies drops the excluded dependencies and adds the extra ones
+func (c *Config) Dependencies(deps []parser.Dependency) []parser.Dependency {
+	result := []parser.Dependency{}
+	projectRoot := ""
+	for _, dep := range deps {
+		if projectRoot == "" {
+			projectRoot = dep.ProjectRoot
+		}
+		if !c.excludes(dep) {
+			result = append(result, dep)
+		}
+	}
+	for _, extra := range c.Extra {
+		dep, err := parseExtra(extra)
+		if err != nil {
+			continue
+		}
+		dep.ProjectRoot = projectRoot
+		result = append(result, dep)
+	}
+	return result
+}
+
+func (c *Config) excludes(dep parser.Dependency) bool {
+	for _, pattern := range c.Exclude {
+		if strings.EqualFold(pattern, dep.Name) || strings.EqualFold(pattern, dep.Type+"/"+dep.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExtra reads an extra dependency written as type/name@version, e.g.
+// gem/rails@7.1.3 or npm/@types/node@20.11.0
+func parseExtra(extra string) (parser.Dependency, error) {
+	depType, rest, _ := strings.Cut(extra, "/")
+	at := strings.LastIndex(rest, "@")
+	if parser.ProjectTypeFor(depType) == parser.ProjectTypeUnknown || at <= 0 || at == len(rest)-1 {
+		return parser.Dependency{}, fmt.Errorf("extra dependency %q must look like type/name@version, e.g. gem/rails@7.1.3", extra)
+	}
+	return parser.Dependency{Name: rest[:at], Version: rest[at+1:], Type: depType, Source: "config"}, nil
+}
+
+// Set writes key = value to the config file at path, creating it if needed.
+// An empty value removes the key.
+func Set(path, key, value string) error {
+	// Unknown keys can still be removed, to repair a config that fails to load
+	if value != "" {
+		if err := (&Config{}).set(key, value); err != nil {
+			return err
+		}
+	}
+
+	data := map[string]any{}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if _, err := toml.Decode(string(content), &data); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	table := data
+	name := key
+	if section, rest, ok := strings.Cut(key, "."); ok {
+		sub, _ := data[section].(map[string]any)
+		if sub == nil {
+			sub = map[string]any{}
+			data[section] = sub
+		}
+		table, name = sub, rest
+	}
+	switch {
+	case value == "":
+		delete(table, name)
+	case key == "exclude" || key == "extra":
+		table[name] = splitList(value)
+	default:
+		table[name] = value
+	}
+	for section, sub := range data {
+		if sub, ok := sub.(map[string]any); ok && len(sub) == 0 {
+			delete(data, section)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
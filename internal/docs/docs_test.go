@@ -2,6 +2,11 @@ package docs
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -9,9 +14,30 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/config"
 	"github.com/heycomputer/pudding/internal/parser"
 )
 
+// testHome holds the files tests share, removed once they're done
+var testHome string
+
+// TestMain points the docs cache and config at a temporary directory so
+// tests never read or write the real user cache.
+func TestMain(m *testing.M) {
+	cacheHome, err := os.MkdirTemp("", "pudding-docs-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheHome)
+	os.Setenv("XDG_CONFIG_HOME", cacheHome)
+	testHome = cacheHome
+
+	code := m.Run()
+	os.RemoveAll(cacheHome)
+	os.Exit(code)
+}
+
 // -----------------------------------------------------------------------------
 // Mocks
 // -----------------------------------------------------------------------------
@@ -52,19 +78,18 @@ func (m *BrowserOpenerMock) Open(url string) error {
 // Helper to keep call sites clean.
 func callFetchAndOpen(
 	dep *parser.Dependency,
-	projectType parser.ProjectType,
 	keywords string,
 	cmd *CommandRunnerMock,
 	browser *BrowserOpenerMock,
 ) error {
-	return fetchAndOpenWithFuncs(dep, projectType, keywords, cmd.Run, browser.Open)
+	return fetchAndOpenWithFuncs(dep, keywords, cmd.Run, browser.Open)
 }
 
 // -----------------------------------------------------------------------------
 // Tests
 // -----------------------------------------------------------------------------
 
-func TestFetchAndOpen_UnsupportedProjectType(t *testing.T) {
+func TestFetchAndOpen_UnsupportedDependencyType(t *testing.T) {
 	cmdMock := &CommandRunnerMock{}
 	browserMock := &BrowserOpenerMock{}
 
@@ -74,9 +99,9 @@ func TestFetchAndOpen_UnsupportedProjectType(t *testing.T) {
 		Type:    "unknown",
 	}
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeUnknown, "", cmdMock, browserMock)
-	require.Error(t, err, "Expected error for unsupported project type")
-	assert.Contains(t, strings.ToLower(err.Error()), "unsupported project type")
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err, "Expected error for unsupported dependency type")
+	assert.Contains(t, strings.ToLower(err.Error()), "unsupported dependency type")
 
 	// Should not run any commands or open browser
 	assert.Len(t, cmdMock.Calls, 0, "expected no commands to be run")
@@ -110,7 +135,7 @@ func TestFetchElixirDocs_Success_NoKeywords(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeElixir, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.NoError(t, err)
 
 	cmdMock.AssertExpectations(t)
@@ -145,7 +170,7 @@ func TestFetchElixirDocs_Success_WithKeywords(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeElixir, keywords, cmdMock, browserMock)
+	err := callFetchAndOpen(dep, keywords, cmdMock, browserMock)
 	require.NoError(t, err)
 
 	cmdMock.AssertExpectations(t)
@@ -172,7 +197,7 @@ func TestFetchElixirDocs_CommandFailure(t *testing.T) {
 		Return([]byte(nil), cmdErr).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeElixir, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch docs for phoenix")
 
@@ -199,7 +224,7 @@ func TestFetchElixirDocs_InvalidOutput_NoPath(t *testing.T) {
 		Return([]byte("no docs path here"), nil).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeElixir, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to extract docs path for phoenix")
 
@@ -242,7 +267,7 @@ func TestFetchRubyDocs_Success_NoKeywords(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeRuby, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.NoError(t, err)
 
 	cmdMock.AssertExpectations(t)
@@ -283,7 +308,7 @@ func TestFetchRubyDocs_Success_WithKeywords(t *testing.T) {
 		Return(nil).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeRuby, keywords, cmdMock, browserMock)
+	err := callFetchAndOpen(dep, keywords, cmdMock, browserMock)
 	require.NoError(t, err)
 
 	cmdMock.AssertExpectations(t)
@@ -309,7 +334,7 @@ func TestFetchRubyDocs_RdocFailure(t *testing.T) {
 		Return([]byte(nil), cmdErr).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeRuby, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to generate rdoc for rails")
 
@@ -343,7 +368,7 @@ func TestFetchRubyDocs_GemEnvFailure(t *testing.T) {
 		Return([]byte(nil), envErr).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeRuby, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get gem env home")
 
@@ -386,10 +411,209 @@ func TestFetchRubyDocs_BrowserFailure(t *testing.T) {
 		Return(browserErr).
 		Once()
 
-	err := callFetchAndOpen(dep, parser.ProjectTypeRuby, "", cmdMock, browserMock)
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open rdoc for rails")
 
 	cmdMock.AssertExpectations(t)
 	browserMock.AssertExpectations(t)
 }
+
+func TestFetchAndOpen_CacheHitSkipsFetch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	store, err := cache.OpenDefault()
+	require.NoError(t, err)
+	require.NoError(t, store.Put(cache.Entry{
+		Ecosystem: "elixir",
+		Name:      "phoenix",
+		Version:   "1.7.0",
+		Path:      docPath,
+		Source:    "mix hex.docs",
+	}))
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "phoenix",
+		Version: "1.7.0",
+		Type:    "elixir",
+	}
+
+	browserMock.
+		On("Open", "file://"+docPath+"/search.html?q=plug").
+		Return(nil).
+		Once()
+
+	err = callFetchAndOpen(dep, "plug", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	assert.Len(t, cmdMock.Calls, 0, "expected no commands to be run on a cache hit")
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchAndOpen_RecordsFetchedDocs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "ecto",
+		Version: "3.11.0",
+		Type:    "elixir",
+	}
+
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto", "3.11.0").
+		Return([]byte("Docs fetched: "+docPath+"\n"), nil).
+		Once()
+
+	browserMock.
+		On("Open", "file://"+docPath+"/index.html").
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	store, err := cache.OpenDefault()
+	require.NoError(t, err)
+	entry, ok := store.Get("elixir", "ecto", "3.11.0")
+	require.True(t, ok, "expected fetched docs to be cached")
+	assert.Equal(t, docPath, entry.Path)
+	assert.Equal(t, "mix hex.docs", entry.Source)
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+}
+
+func TestResolveURL_DoesNotOpenBrowser(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "plug",
+		Version: "1.15.0",
+		Type:    "elixir",
+	}
+
+	docPath := "/tmp/plug-docs"
+	cmdMock.
+		On("Run",
+			"mix",
+			"hex.docs", "fetch", "plug", "1.15.0",
+		).
+		Return([]byte("Docs fetched: "+docPath+"\n"), nil).
+		Once()
+
+	url, err := resolveURLWithFuncs(dep, "conn", cmdMock.Run, printWarning)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+docPath+"/search.html?q=conn", url)
+
+	cmdMock.AssertExpectations(t)
+}
+
+func TestResolveURL_UnsupportedDependencyType(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+
+	dep := &parser.Dependency{Name: "test", Version: "1.0.0", Type: "unknown"}
+
+	_, err := resolveURLWithFuncs(dep, "", cmdMock.Run, printWarning)
+	require.Error(t, err)
+	assert.Len(t, cmdMock.Calls, 0, "expected no commands to be run")
+}
+
+func TestFetchAndOpen_MixedEcosystems(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	// A Phoenix app with a Gemfile for tooling: each dependency goes to its
+	// own ecosystem's backend
+	plug := &parser.Dependency{Name: "plug_mixed", Version: "1.15.0", Type: "elixir"}
+	rubocop := &parser.Dependency{Name: "rubocop_mixed", Version: "1.60.0", Type: "gem"}
+
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "plug_mixed", "1.15.0").
+		Return([]byte("Docs fetched: /tmp/plug-docs\n"), nil).
+		Once()
+	cmdMock.
+		On("Run", "rdoc", "rubocop_mixed", "--rdoc", "--version", "1.60.0").
+		Return([]byte("rdoc ok"), nil).
+		Once()
+	cmdMock.
+		On("Run", "sh", "-c", "gem env home").
+		Return([]byte("/home/user/.gem\n"), nil).
+		Once()
+
+	browserMock.On("Open", "file:///tmp/plug-docs/index.html").Return(nil).Once()
+	browserMock.On("Open", "file:///home/user/.gem/doc/rubocop_mixed-1.60.0/rdoc/table_of_contents.html").Return(nil).Once()
+
+	require.NoError(t, callFetchAndOpen(plug, "", cmdMock, browserMock))
+	require.NoError(t, callFetchAndOpen(rubocop, "", cmdMock, browserMock))
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchAndOpen_HexDocsUnavailable(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	// Without mix, the Hex API picks the hosted docs to open instead
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/packages/plug_nomix/releases/1.15.0", r.URL.Path)
+		w.Write([]byte(`{"version":"1.15.0","has_docs":true,"docs_html_url":"https://hexdocs.example.com/plug_nomix/1.15.0/"}`))
+	}))
+	defer server.Close()
+	t.Setenv("HEX_API_URL", server.URL+"/api/")
+
+	dep := &parser.Dependency{Name: "plug_nomix", Version: "1.15.0", Type: "elixir"}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "plug_nomix", "1.15.0").
+		Return(nil, fmt.Errorf("failed to run mix: %w", exec.ErrNotFound)).
+		Once()
+	browserMock.On("Open", "https://hexdocs.example.com/plug_nomix/1.15.0/search.html?q=conn").Return(nil).Once()
+
+	require.NoError(t, callFetchAndOpen(dep, "conn", cmdMock, browserMock))
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+
+	// Other failures are the dependency's, and still reported
+	other := &parser.Dependency{Name: "plug_missing", Version: "9.9.9", Type: "elixir"}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "plug_missing", "9.9.9").
+		Return(nil, errors.New("No package with name plug_missing")).
+		Once()
+	assert.Error(t, callFetchAndOpen(other, "", cmdMock, browserMock))
+}
+
+func TestHexDocsUnavailable(t *testing.T) {
+	assert.True(t, hexDocsUnavailable(fmt.Errorf("failed to run mix: %w", exec.ErrNotFound)))
+	assert.True(t, hexDocsUnavailable(errors.New(`** (Mix) The task "hex.docs" could not be found`)))
+	assert.False(t, hexDocsUnavailable(errors.New("No package with name nope")))
+}
+
+func TestFetchAndOpen_NonHexElixirDependency(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	// mix hex.docs would fetch whatever Hex package shares the name
+	for _, dep := range []*parser.Dependency{
+		{Name: "heroicons", Version: "v2.1.1", Type: "elixir", Source: "git"},
+		{Name: "local_lib", Type: "elixir", Source: "path"},
+	} {
+		err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no hex docs for "+dep.Source+" dependency "+dep.Name)
+
+		_, err = hexSearchURL(dep, "", config.Mirrors{})
+		assert.Error(t, err)
+	}
+
+	cmdMock.AssertNotCalled(t, "Run", mock.Anything, mock.Anything)
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
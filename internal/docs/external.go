@@ -0,0 +1,80 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/external"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// RegisterExternal registers the provider plugins found on PATH. Plugins that
+// fail to describe themselves or clash with a registered ecosystem are left
+// out, and their errors returned.
+func RegisterExternal() []error {
+	var errs []error
+	for _, path := range external.Discover(os.Getenv("PATH")) {
+		if err := registerExternal(path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to load the provider plugin %s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+func registerExternal(path string) error {
+	p, err := external.Load(path)
+	if err != nil {
+		return err
+	}
+	return Register(externalPlugin(p))
+}
+
+// externalPlugin asks the executable for everything but the page lookups,
+// which work on the HTML it produced like the rendered backends
+func externalPlugin(p *external.Plugin) Plugin {
+	label := p.Label
+	if label == "" {
+		label = p.DependencyType + " docs"
+	}
+	return Plugin{
+		Ecosystem: parser.Ecosystem{
+			ProjectType: parser.ProjectType(p.ProjectType),
+			DepType:     p.DependencyType,
+			Manifests:   p.Manifests,
+			Lockfiles:   p.Lockfiles,
+			Parser:      externalParser{p},
+		},
+		Provider: &docsBackend{
+			source: filepath.Base(p.Path),
+			label:  label,
+			fetch: func(dep *parser.Dependency, _ CommandRunner) (string, error) {
+				return p.Fetch(dep)
+			},
+			url: renderedPageURL,
+			search: func(dep *parser.Dependency, keywords string, _ config.Mirrors) (string, error) {
+				return p.URL(dep, keywords)
+			},
+		},
+	}
+}
+
+// externalParser detects projects by the plugin's manifests without running
+// it, as detection looks at every directory of a workspace
+type externalParser struct {
+	plugin *external.Plugin
+}
+
+func (e externalParser) CanParse(projectRoot string) bool {
+	for _, manifest := range e.plugin.Manifests {
+		if fileExists(filepath.Join(projectRoot, manifest)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e externalParser) Parse(projectRoot string) ([]parser.Dependency, error) {
+	return e.plugin.Dependencies(projectRoot)
+}
@@ -0,0 +1,77 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/external"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// goBuildCache is looked up before TestMain moves the user cache, which
+// would leave the plugin to build from scratch
+var goBuildCache = func() string {
+	output, _ := exec.Command("go", "env", "GOCACHE").Output()
+	return strings.TrimSpace(string(output))
+}()
+
+// registerPub builds the reference plugin and registers it once per test
+// binary, as ecosystems can't be unregistered
+var registerPub = sync.OnceValues(func() (string, error) {
+	path := filepath.Join(testHome, "bin", external.Prefix+"pub")
+	build := exec.Command("go", "build", "-o", path, "../../examples/pudding-provider-pub")
+	build.Env = append(os.Environ(), "GOCACHE="+goBuildCache)
+	if output, err := build.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to build the reference plugin: %w\n%s", err, output)
+	}
+	return path, registerExternal(path)
+})
+
+var pubConformanceCase = conformanceCase{
+	dep:      parser.Dependency{Name: "conformance_http", Version: "1.2.1", Type: "pub", Source: "hosted"},
+	keywords: "client",
+	setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+		dep.Dir = t.TempDir()
+		writeTestFile(t, filepath.Join(dep.Dir, "README.md"), "# http\n\nMake requests with a client.\n")
+	},
+}
+
+func TestRegisterExternal(t *testing.T) {
+	path, err := registerPub()
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "pubspec.yaml"), "name: app\n")
+	writeTestFile(t, filepath.Join(root, "pubspec.lock"), "packages:\n  http:\n    dependency: \"direct main\"\n    source: hosted\n    version: \"1.2.1\"\n")
+	deps, projectTypes, err := parser.ParseProjectDependencies(root)
+	require.NoError(t, err)
+	assert.Equal(t, []parser.ProjectType{"dart"}, projectTypes)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "pub", deps[0].Type)
+
+	t.Run("conformance", func(t *testing.T) {
+		provider, err := providerFor("pub")
+		require.NoError(t, err)
+		assert.Equal(t, "dartdoc", provider.Label())
+		testProviderConformance(t, provider, pubConformanceCase)
+	})
+
+	// Plugins are discovered on PATH, and the broken or clashing ones reported
+	broken := filepath.Join(t.TempDir(), external.Prefix+"broken")
+	writeTestFile(t, broken, "#!/bin/sh\necho '{\"error\":\"broken\"}'\n")
+	require.NoError(t, os.Chmod(broken, 0755))
+	t.Setenv("PATH", filepath.Dir(path)+string(os.PathListSeparator)+filepath.Dir(broken))
+
+	errs := RegisterExternal()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "already registered")
+	assert.True(t, strings.HasSuffix(errs[1].Error(), "broken describe: broken"), errs[1].Error())
+}
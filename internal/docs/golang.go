@@ -0,0 +1,173 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// goPackageMarker separates packages in the rendered `go doc` output
+const goPackageMarker = "@@pudding-package "
+
+// fetchGoDocs renders the docs for a module, or the standard library
+// package index for the Go toolchain itself
+func fetchGoDocs(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+	if dep.Name == "go" {
+		return renderGoToolchainDocs(dep, "", cmdRunner)
+	}
+	return renderGoModuleDocs(dep, cmdRunner)
+}
+
+// resolveGoPackageDocs renders the docs for a single standard library
+// package or symbol on demand, rather than the whole standard library
+func resolveGoPackageDocs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (string, bool, error) {
+	if dep.Name != "go" || keywords == "" {
+		return "", false, nil
+	}
+	pageURL, err := goPackageDocsURL(dep, keywords, cmdRunner)
+	return pageURL, true, err
+}
+
+// goPackageDocsURL renders the toolchain page for a standard library package
+// and returns its URL
+func goPackageDocsURL(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (string, error) {
+	docDir, err := renderGoToolchainDocs(dep, keywords, cmdRunner)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("file://%s/%s", docDir, goPackagePageName(keywords)), nil
+}
+
+// renderGoModuleDocs renders `go doc -all` for every package of a module
+// version found in the local module cache
+func renderGoModuleDocs(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+	moduleDir := dep.Dir
+	if moduleDir == "" {
+		modCacheOutput, err := cmdRunner("go", "env", "GOMODCACHE")
+		if err != nil {
+			return "", fmt.Errorf("failed to get go module cache: %w", err)
+		}
+		modCache := strings.TrimSpace(string(modCacheOutput))
+		moduleDir = filepath.Join(modCache, escapeModulePath(dep.Name)+"@"+escapeModulePath(dep.Version))
+
+		// Only download when the module isn't in the cache yet
+		if _, err := os.Stat(moduleDir); err != nil {
+			if _, err := cmdRunner("go", "mod", "download", dep.Name+"@"+dep.Version); err != nil {
+				return "", fmt.Errorf("%s@%s is not downloaded, and failed to download it: %w", dep.Name, dep.Version, err)
+			}
+		}
+	}
+	if _, err := os.Stat(moduleDir); err != nil {
+		return "", fmt.Errorf("%s@%s is not downloaded to %s, run `go mod download` in your project", dep.Name, dep.Version, moduleDir)
+	}
+
+	// The module cache is read-only, so go must neither update go.mod nor
+	// download the module's own dependencies, which go doc can do without
+	script := fmt.Sprintf(
+		"cd %s && for pkg in $(GOWORK=off GOFLAGS=-mod=readonly GOPROXY=off go list -e ./...); do echo \"%s$pkg\"; GOWORK=off GOFLAGS=-mod=readonly GOPROXY=off go doc -all \"$pkg\" || true; done",
+		shellQuote(moduleDir), goPackageMarker)
+	docOutput, err := cmdRunner("sh", "-c", script)
+	if err != nil {
+		return "", fmt.Errorf("failed to render go docs for %s: %w", dep.Name, err)
+	}
+
+	sections := splitGoDocOutput(string(docOutput))
+	if len(sections) == 0 {
+		return "", fmt.Errorf("no go packages found for %s in %s", dep.Name, moduleDir)
+	}
+
+	docsDir, err := renderedDocsDir()
+	if err != nil {
+		return "", err
+	}
+	docDir := filepath.Join(docsDir, "go", escapeModulePath(dep.Name)+"@"+dep.Version)
+	if err := writeDocsPage(filepath.Join(docDir, "index.html"), fmt.Sprintf("%s %s", dep.Name, dep.Version), sections); err != nil {
+		return "", err
+	}
+
+	return docDir, nil
+}
+
+// renderGoToolchainDocs renders the standard library package index, or the
+// docs for a single standard library package or symbol when keywords are
+// given, and returns the toolchain docs directory
+func renderGoToolchainDocs(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (string, error) {
+	// Any toolchain but the local one would be downloaded, so an exact
+	// release other than it is left to the hosted docs
+	env := "GOTOOLCHAIN=local "
+	if strings.Count(dep.Version, ".") == 2 {
+		output, err := cmdRunner("sh", "-c", env+"go env GOVERSION")
+		if err != nil {
+			return "", &toolUnavailableError{tool: "go", err: err}
+		}
+		// e.g. "go1.24.4 X:nodwarf5" with experiments enabled
+		local, _, _ := strings.Cut(strings.TrimSpace(string(output)), " ")
+		if local != "go"+dep.Version {
+			return "", &toolUnavailableError{tool: "go" + dep.Version, err: fmt.Errorf("the local toolchain is %s", local)}
+		}
+	}
+
+	title := fmt.Sprintf("Go %s standard library", dep.Version)
+	fileName := "index.html"
+	script := env + "go list -f '{{.ImportPath}}: {{.Doc}}' std"
+	if keywords != "" {
+		title = fmt.Sprintf("%s (Go %s)", keywords, dep.Version)
+		fileName = goPackagePageName(keywords)
+		script = fmt.Sprintf("%sgo doc -all %s", env, shellQuote(keywords))
+	}
+
+	docOutput, err := cmdRunner("sh", "-c", script)
+	if err != nil {
+		return "", fmt.Errorf("failed to render go docs for %s: %w", title, err)
+	}
+
+	docsDir, err := renderedDocsDir()
+	if err != nil {
+		return "", err
+	}
+	docDir := filepath.Join(docsDir, "go", "go@"+dep.Version)
+	sections := []pageSection{{ID: "docs", Title: title, Body: string(docOutput)}}
+	if err := writeDocsPage(filepath.Join(docDir, fileName), title, sections); err != nil {
+		return "", err
+	}
+
+	return docDir, nil
+}
+
+// goPackagePageName returns the file name for a standard library package page
+func goPackagePageName(target string) string {
+	return escapeModulePath(strings.ReplaceAll(target, "/", "_")) + ".html"
+}
+
+// splitGoDocOutput splits the combined `go doc` output into one section per package
+func splitGoDocOutput(output string) []pageSection {
+	sections := []pageSection{}
+	for _, chunk := range strings.Split(output, goPackageMarker)[1:] {
+		pkg, body, _ := strings.Cut(chunk, "\n")
+		pkg = strings.TrimSpace(pkg)
+		if pkg == "" {
+			continue
+		}
+		sections = append(sections, pageSection{ID: pkg, Title: pkg, Body: strings.TrimSpace(body)})
+	}
+	return sections
+}
+
+// escapeModulePath applies the module cache case encoding, where each
+// upper-case letter is replaced by '!' followed by its lower-case form
+func escapeModulePath(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			sb.WriteByte('!')
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,206 @@
+package docs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func goDocScript(dir string) string {
+	return "cd " + shellQuote(dir) + " && for pkg in $(GOWORK=off GOFLAGS=-mod=readonly GOPROXY=off go list -e ./...); do echo \"" +
+		goPackageMarker + "$pkg\"; GOWORK=off GOFLAGS=-mod=readonly GOPROXY=off go doc -all \"$pkg\" || true; done"
+}
+
+func TestFetchGoDocs_Success_FromModuleCache(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	modCache := t.TempDir()
+	moduleDir := filepath.Join(modCache, "github.com", "!burnt!sushi", "toml@v1.4.0")
+	require.NoError(t, os.MkdirAll(moduleDir, 0755))
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "github.com/BurntSushi/toml",
+		Version: "v1.4.0",
+		Type:    "go",
+	}
+
+	cmdMock.
+		On("Run", "go", "env", "GOMODCACHE").
+		Return([]byte(modCache+"\n"), nil).
+		Once()
+
+	docOutput := goPackageMarker + "github.com/BurntSushi/toml\npackage toml\n\nfunc Decode(data string, v any) (MetaData, error)\n"
+	cmdMock.
+		On("Run", "sh", "-c", goDocScript(moduleDir)).
+		Return([]byte(docOutput), nil).
+		Once()
+
+	expectedPath := filepath.Join(cacheHome, "pudding", "docs", "go", "github.com", "!burnt!sushi", "toml@v1.4.0", "index.html")
+	browserMock.
+		On("Open", "file://"+expectedPath+"#:~:text=Decode").
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "Decode", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	page, err := os.ReadFile(expectedPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "func Decode(data string, v any) (MetaData, error)")
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchGoDocs_DownloadsMissingModule(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	modCache := t.TempDir()
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "golang.org/x/text",
+		Version: "v0.24.0",
+		Type:    "go",
+	}
+
+	cmdMock.
+		On("Run", "go", "env", "GOMODCACHE").
+		Return([]byte(modCache+"\n"), nil).
+		Once()
+
+	cmdMock.
+		On("Run", "go", "mod", "download", "golang.org/x/text@v0.24.0").
+		Return([]byte(nil), errors.New("mock download error")).
+		Once()
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "golang.org/x/text@v0.24.0 is not downloaded, and failed to download it")
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
+
+func TestFetchGoDocs_LocalReplacement(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "example.com/local",
+		Version: "v0.0.0",
+		Type:    "go",
+		Source:  "path",
+		Dir:     t.TempDir(),
+	}
+
+	// No packages in the output => nothing to render
+	cmdMock.
+		On("Run", "sh", "-c", goDocScript(dep.Dir)).
+		Return([]byte(""), nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no go packages found for example.com/local")
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
+
+func TestFetchGoDocs_NotDownloaded(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cmdMock := &CommandRunnerMock{}
+	dep := &parser.Dependency{
+		Name:    "example.com/local",
+		Version: "v0.0.0",
+		Type:    "go",
+		Source:  "path",
+		Dir:     filepath.Join(t.TempDir(), "missing"),
+	}
+
+	_, err := fetchWithFuncs(dep, cmdMock.Run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "example.com/local@v0.0.0 is not downloaded")
+	assert.Contains(t, err.Error(), "go mod download")
+	cmdMock.AssertNotCalled(t, "Run", mock.Anything, mock.Anything)
+}
+
+func TestFetchGoDocs_Toolchain(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "go",
+		Version: "1.24.4",
+		Type:    "go",
+	}
+
+	cmdMock.
+		On("Run", "sh", "-c", "GOTOOLCHAIN=local go env GOVERSION").
+		Return([]byte("go1.24.4\n"), nil).
+		Once()
+	cmdMock.
+		On("Run", "sh", "-c", "GOTOOLCHAIN=local go doc -all 'net/http'").
+		Return([]byte("package http // import \"net/http\"\n"), nil).
+		Once()
+
+	expectedPath := filepath.Join(cacheHome, "pudding", "docs", "go", "go@1.24.4", "net_http.html")
+	browserMock.
+		On("Open", "file://"+expectedPath).
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "net/http", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+}
+
+func TestResolveURL_OtherGoToolchain(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cmdMock := &CommandRunnerMock{}
+	dep := &parser.Dependency{Name: "go", Version: "1.22.1", Type: "go"}
+
+	// Rendering offline only uses the local toolchain, so another release
+	// opens the hosted docs rather than downloading it
+	cmdMock.
+		On("Run", "sh", "-c", "GOTOOLCHAIN=local go env GOVERSION").
+		Return([]byte("go1.24.4\n"), nil).
+		Once()
+
+	var warnings []error
+	url, err := resolveURLWithFuncs(dep, "net/http", cmdMock.Run, func(err error) {
+		warnings = append(warnings, err)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://pkg.go.dev/net/http@go1.22.1", url)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Error(), "the local toolchain is go1.24.4")
+	cmdMock.AssertExpectations(t)
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	assert.Equal(t, "github.com/!burnt!sushi/toml", escapeModulePath("github.com/BurntSushi/toml"))
+	assert.Equal(t, "golang.org/x/text", escapeModulePath("golang.org/x/text"))
+}
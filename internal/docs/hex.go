@@ -0,0 +1,217 @@
+package docs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HexAPIClient interacts with the Hex API of hex.pm or a self-hosted repository
+type HexAPIClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHexAPIClient creates a new Hex API client for hex.pm
+func NewHexAPIClient() *HexAPIClient {
+	return &HexAPIClient{
+		baseURL: "https://hex.pm/api",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// HexPackage represents a package and its releases from the API
+type HexPackage struct {
+	Name                string                   `json:"name"`
+	Repository          string                   `json:"repository"`
+	URL                 string                   `json:"url"`
+	HTMLURL             string                   `json:"html_url"`
+	DocsHTMLURL         string                   `json:"docs_html_url"` // docs of the latest release
+	Meta                HexPackageMeta           `json:"meta"`
+	Releases            []HexReleaseSummary      `json:"releases"`
+	Retirements         map[string]HexRetirement `json:"retirements"` // by version
+	LatestVersion       string                   `json:"latest_version"`
+	LatestStableVersion string                   `json:"latest_stable_version"`
+	Downloads           map[string]int           `json:"downloads"` // e.g. "all", "week"
+}
+
+// HexPackageMeta is what a package's mix.exs says about it
+type HexPackageMeta struct {
+	Description string            `json:"description"`
+	Licenses    []string          `json:"licenses"`
+	Links       map[string]string `json:"links"` // by name, e.g. "GitHub"
+	Maintainers []string          `json:"maintainers"`
+}
+
+// HexReleaseSummary is a release as listed with its package
+type HexReleaseSummary struct {
+	Version    string `json:"version"`
+	URL        string `json:"url"`
+	HasDocs    bool   `json:"has_docs"`
+	InsertedAt string `json:"inserted_at"`
+}
+
+// HexRelease represents a specific release of a package from the API
+type HexRelease struct {
+	Version      string                    `json:"version"`
+	URL          string                    `json:"url"`
+	HTMLURL      string                    `json:"html_url"`
+	PackageURL   string                    `json:"package_url"`
+	HasDocs      bool                      `json:"has_docs"`
+	DocsHTMLURL  string                    `json:"docs_html_url"`
+	Retirement   *HexRetirement            `json:"retirement"` // nil unless retired
+	Requirements map[string]HexRequirement `json:"requirements"`
+	Meta         HexReleaseMeta            `json:"meta"`
+	Downloads    int                       `json:"downloads"`
+	InsertedAt   string                    `json:"inserted_at"`
+}
+
+// HexRetirement says why a release shouldn't be used any more
+type HexRetirement struct {
+	Reason  string `json:"reason"` // "other", "invalid", "security", "deprecated" or "renamed"
+	Message string `json:"message"`
+}
+
+// HexRequirement is a dependency of a release
+type HexRequirement struct {
+	App         string `json:"app"`
+	Optional    bool   `json:"optional"`
+	Requirement string `json:"requirement"`
+}
+
+// HexReleaseMeta describes how a release is built
+type HexReleaseMeta struct {
+	App        string   `json:"app"`
+	BuildTools []string `json:"build_tools"`
+	Elixir     string   `json:"elixir"`
+}
+
+// GetPackage fetches a package with its metadata and releases
+func (c *HexAPIClient) GetPackage(name string) (*HexPackage, error) {
+	var pkg HexPackage
+	if err := c.get(fmt.Sprintf("/packages/%s", url.PathEscape(name)), &pkg); err != nil {
+		return nil, fmt.Errorf("failed to fetch package %s: %w", name, err)
+	}
+	return &pkg, nil
+}
+
+// GetRelease fetches a specific release of a package
+func (c *HexAPIClient) GetRelease(name, version string) (*HexRelease, error) {
+	var release HexRelease
+	if err := c.get(fmt.Sprintf("/packages/%s/releases/%s", url.PathEscape(name), url.PathEscape(version)), &release); err != nil {
+		return nil, fmt.Errorf("failed to fetch package %s version %s: %w", name, version, err)
+	}
+	return &release, nil
+}
+
+func (c *HexAPIClient) get(path string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	// The Hex API turns away requests without a user agent
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "pudding")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errHexNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// errHexNotFound is the API not knowing a package or release, as opposed to
+// failing to answer
+var errHexNotFound = errors.New("not found")
+
+// docsLinkNames are the package links that lead to docs, best first
+var docsLinkNames = []string{"docs", "documentation", "hexdocs", "homepage", "website", "github", "gitlab", "source", "repository"}
+
+// GetDocumentationURL attempts to find the best documentation URL for a
+// release. It tries multiple sources in order of preference:
+// 1. The release's docs, when it published some
+// 2. A package link to its docs, homepage or source, in that order
+// 3. The release's page on Hex
+// 4. Default to the release's docs on hexdocs.pm
+// It fails when the API can't be reached or knows neither the package nor
+// the release.
+func (c *HexAPIClient) GetDocumentationURL(name, version string) (string, error) {
+	release, err := c.GetRelease(name, version)
+	if err != nil && !errors.Is(err, errHexNotFound) {
+		return "", err
+	}
+	docsURL, _, err := c.documentationURL(name, version, release)
+	return docsURL, err
+}
+
+// documentationURL picks the docs of a release already fetched, nil when the
+// API doesn't know it, and reports whether they're ExDoc docs, which can be
+// searched
+func (c *HexAPIClient) documentationURL(name, version string, release *HexRelease) (string, bool, error) {
+	if release != nil && release.HasDocs && release.DocsHTMLURL != "" {
+		return release.DocsHTMLURL, true, nil
+	}
+
+	pkg, err := c.GetPackage(name)
+	if err != nil && !errors.Is(err, errHexNotFound) {
+		return "", false, err
+	}
+	if pkg != nil {
+		if link := pkg.docsLink(); link != "" {
+			return link, false, nil
+		}
+		if release == nil && pkg.HTMLURL != "" {
+			return pkg.HTMLURL, false, nil
+		}
+	}
+	if release != nil && release.HTMLURL != "" {
+		return release.HTMLURL, false, nil
+	}
+	if release == nil && pkg == nil {
+		return "", false, fmt.Errorf("no package %s version %s on Hex", name, version)
+	}
+
+	return fmt.Sprintf("https://hexdocs.pm/%s/%s/", url.PathEscape(name), url.PathEscape(version)), true, nil
+}
+
+// docsLink returns the package link most likely to document it, matching
+// link names case-insensitively
+func (p *HexPackage) docsLink() string {
+	names := make([]string, 0, len(p.Meta.Links))
+	for name := range p.Meta.Links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, want := range docsLinkNames {
+		for _, name := range names {
+			if strings.EqualFold(name, want) {
+				return p.Meta.Links[name]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+package docs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packages/ecto" {
+			t.Errorf("Expected path /packages/ecto, got %s", r.URL.Path)
+		}
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("Expected a User-Agent header")
+		}
+
+		w.Write([]byte(`{
+			"name": "ecto",
+			"html_url": "https://hex.pm/packages/ecto",
+			"docs_html_url": "https://hexdocs.pm/ecto/",
+			"meta": {
+				"description": "A toolkit for data mapping and language integrated query for Elixir",
+				"licenses": ["Apache-2.0"],
+				"links": {"GitHub": "https://github.com/elixir-ecto/ecto"}
+			},
+			"releases": [
+				{"version": "3.11.0", "has_docs": true, "url": "https://hex.pm/api/packages/ecto/releases/3.11.0"},
+				{"version": "3.0.0", "has_docs": false, "url": "https://hex.pm/api/packages/ecto/releases/3.0.0"}
+			],
+			"retirements": {"3.0.0": {"reason": "security", "message": "Upgrade to 3.0.1"}},
+			"latest_version": "3.11.0",
+			"latest_stable_version": "3.11.0",
+			"downloads": {"all": 1000}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	pkg, err := client.GetPackage("ecto")
+	if err != nil {
+		t.Fatalf("GetPackage failed: %v", err)
+	}
+
+	if pkg.LatestVersion != "3.11.0" || len(pkg.Releases) != 2 || !pkg.Releases[0].HasDocs || pkg.Releases[1].HasDocs {
+		t.Errorf("Expected two releases, the latest with docs, got %+v", pkg)
+	}
+	if !reflect.DeepEqual(pkg.Meta.Licenses, []string{"Apache-2.0"}) {
+		t.Errorf("Expected the Apache-2.0 license, got %v", pkg.Meta.Licenses)
+	}
+	if pkg.Meta.Links["GitHub"] != "https://github.com/elixir-ecto/ecto" {
+		t.Errorf("Expected the GitHub link, got %v", pkg.Meta.Links)
+	}
+	if pkg.Retirements["3.0.0"].Reason != "security" {
+		t.Errorf("Expected 3.0.0 to be retired, got %v", pkg.Retirements)
+	}
+}
+
+func TestGetPackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"message":"Page not found"}`))
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	_, err := client.GetPackage("nonexistent")
+	if err == nil {
+		t.Error("Expected error for non-existent package, got nil")
+	}
+}
+
+func TestGetRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packages/plug/releases/1.15.0" {
+			t.Errorf("Expected path /packages/plug/releases/1.15.0, got %s", r.URL.Path)
+		}
+
+		response := HexRelease{
+			Version:     "1.15.0",
+			HasDocs:     true,
+			DocsHTMLURL: "https://hexdocs.pm/plug/1.15.0/",
+			HTMLURL:     "https://hex.pm/packages/plug/1.15.0",
+			Retirement:  &HexRetirement{Reason: "deprecated", Message: "Use 1.15.1"},
+			Requirements: map[string]HexRequirement{
+				"mime":      {App: "mime", Requirement: "~> 1.0 or ~> 2.0"},
+				"telemetry": {App: "telemetry", Optional: true, Requirement: "~> 1.0"},
+			},
+			Meta: HexReleaseMeta{App: "plug", BuildTools: []string{"mix"}, Elixir: "~> 1.10"},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	release, err := client.GetRelease("plug", "1.15.0")
+	if err != nil {
+		t.Fatalf("GetRelease failed: %v", err)
+	}
+
+	if !release.HasDocs || release.DocsHTMLURL != "https://hexdocs.pm/plug/1.15.0/" {
+		t.Errorf("Expected docs at https://hexdocs.pm/plug/1.15.0/, got %+v", release)
+	}
+	if release.Retirement == nil || release.Retirement.Reason != "deprecated" {
+		t.Errorf("Expected the release to be retired, got %+v", release.Retirement)
+	}
+	if !release.Requirements["telemetry"].Optional || release.Requirements["mime"].Optional {
+		t.Errorf("Expected only telemetry to be optional, got %+v", release.Requirements)
+	}
+}
+
+func TestHexGetDocumentationURL_ReleaseDocs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/packages/phoenix/releases/1.7.10" {
+			w.Write([]byte(`{"version":"1.7.10","has_docs":true,"docs_html_url":"https://hexdocs.pm/phoenix/1.7.10/"}`))
+			return
+		}
+		t.Errorf("Unexpected request for %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	url, err := client.GetDocumentationURL("phoenix", "1.7.10")
+	if err != nil {
+		t.Fatalf("GetDocumentationURL failed: %v", err)
+	}
+
+	if url != "https://hexdocs.pm/phoenix/1.7.10/" {
+		t.Errorf("Expected https://hexdocs.pm/phoenix/1.7.10/, got %s", url)
+	}
+}
+
+func TestHexGetDocumentationURL_PackageLinkFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages/internal_lib/releases/0.3.0":
+			// Released without docs
+			w.Write([]byte(`{"version":"0.3.0","has_docs":false,"html_url":"https://hex.example.com/packages/internal_lib/0.3.0"}`))
+		case "/packages/internal_lib":
+			w.Write([]byte(`{"name":"internal_lib","meta":{"links":{"github":"https://github.com/acme/internal_lib","Documentation":"https://docs.acme.dev/internal_lib"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	url, err := client.GetDocumentationURL("internal_lib", "0.3.0")
+	if err != nil {
+		t.Fatalf("GetDocumentationURL failed: %v", err)
+	}
+
+	if url != "https://docs.acme.dev/internal_lib" {
+		t.Errorf("Expected https://docs.acme.dev/internal_lib, got %s", url)
+	}
+}
+
+func TestHexGetDocumentationURL_ReleasePageFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages/internal_lib/releases/0.3.0":
+			w.Write([]byte(`{"version":"0.3.0","has_docs":false,"html_url":"https://hex.example.com/packages/internal_lib/0.3.0"}`))
+		case "/packages/internal_lib":
+			w.Write([]byte(`{"name":"internal_lib","html_url":"https://hex.example.com/packages/internal_lib","meta":{"links":{}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	url, err := client.GetDocumentationURL("internal_lib", "0.3.0")
+	if err != nil {
+		t.Fatalf("GetDocumentationURL failed: %v", err)
+	}
+
+	expected := "https://hex.example.com/packages/internal_lib/0.3.0"
+	if url != expected {
+		t.Errorf("Expected %s, got %s", expected, url)
+	}
+}
+
+func TestHexGetDocumentationURL_HexdocsFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages/jason/releases/1.4.1":
+			w.Write([]byte(`{"version":"1.4.1","has_docs":false}`))
+		case "/packages/jason":
+			w.Write([]byte(`{"name":"jason","meta":{"links":{}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &HexAPIClient{
+		baseURL:    server.URL,
+		httpClient: &http.Client{},
+	}
+
+	url, err := client.GetDocumentationURL("jason", "1.4.1")
+	if err != nil {
+		t.Fatalf("GetDocumentationURL failed: %v", err)
+	}
+
+	expected := "https://hexdocs.pm/jason/1.4.1/"
+	if url != expected {
+		t.Errorf("Expected %s, got %s", expected, url)
+	}
+}
+
+func TestHexGetDocumentationURL_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{"unreachable", http.StatusInternalServerError, "status 500"},
+		{"unknown", http.StatusNotFound, "no package jason version 1.4.1 on Hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &HexAPIClient{
+				baseURL:    server.URL,
+				httpClient: &http.Client{},
+			}
+
+			_, err := client.GetDocumentationURL("jason", "1.4.1")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetDocumentationURL = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// installedPackageJSON holds the parts of an installed package's package.json
+// needed to find its docs
+type installedPackageJSON struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Types   string `json:"types"`
+	Typings string `json:"typings"`
+}
+
+func fetchNodeDocs(dep *parser.Dependency, _ CommandRunner) (string, error) {
+	return renderNodeDocs(dep)
+}
+
+// renderNodeDocs assembles the README, CHANGELOG and TypeScript typings of
+// an installed package into a single page
+func renderNodeDocs(dep *parser.Dependency) (string, error) {
+	pkgDir := dep.Dir
+	if pkgDir == "" {
+		return "", fmt.Errorf("%s %s is not installed; run your package manager's install first", dep.Name, dep.Version)
+	}
+	content, err := os.ReadFile(filepath.Join(pkgDir, "package.json"))
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed in %s; run your package manager's install first", dep.Name, pkgDir)
+	}
+	var pkg installedPackageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return "", fmt.Errorf("failed to parse package.json for %s: %w", dep.Name, err)
+	}
+	if dep.Version != "" && pkg.Version != dep.Version {
+		return "", fmt.Errorf("installed %s is %s but the lockfile has %s; reinstall your dependencies", dep.Name, pkg.Version, dep.Version)
+	}
+
+	sections := []pageSection{}
+	if readme := findDocFile(pkgDir, "readme"); readme != "" {
+		sections = appendFileSection(sections, "readme", "README", readme)
+	}
+	if changelog := findDocFile(pkgDir, "changelog", "history", "changes"); changelog != "" {
+		sections = appendFileSection(sections, "changelog", "CHANGELOG", changelog)
+	}
+
+	typings := pkg.Types
+	if typings == "" {
+		typings = pkg.Typings
+	}
+	if typings == "" && fileExists(filepath.Join(pkgDir, "index.d.ts")) {
+		typings = "index.d.ts"
+	}
+	if typings != "" {
+		sections = appendFileSection(sections, "typings", "Typings ("+typings+")", filepath.Join(pkgDir, typings))
+	} else if typesDir := definitelyTypedDir(pkgDir, dep.Name); fileExists(filepath.Join(typesDir, "index.d.ts")) {
+		// Fall back to a separately installed @types package
+		sections = appendFileSection(sections, "typings", "Typings (@types)", filepath.Join(typesDir, "index.d.ts"))
+	}
+
+	if len(sections) == 0 {
+		return "", fmt.Errorf("no README, CHANGELOG or typings found for %s in %s", dep.Name, pkgDir)
+	}
+
+	docsDir, err := renderedDocsDir()
+	if err != nil {
+		return "", err
+	}
+	docDir := filepath.Join(docsDir, "npm", dep.Name+"@"+pkg.Version)
+	if err := writeDocsPage(filepath.Join(docDir, "index.html"), fmt.Sprintf("%s %s", dep.Name, pkg.Version), sections); err != nil {
+		return "", err
+	}
+
+	return docDir, nil
+}
+
+// findDocFile returns the first file in dir whose lower-cased name starts
+// with one of the given prefixes, preferring markdown files
+func findDocFile(dir string, prefixes ...string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	matches := []string{}
+	for _, prefix := range prefixes {
+		for _, entry := range entries {
+			name := strings.ToLower(entry.Name())
+			if !entry.IsDir() && strings.HasPrefix(name, prefix) {
+				matches = append(matches, entry.Name())
+			}
+		}
+		if len(matches) > 0 {
+			break
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return strings.HasSuffix(strings.ToLower(matches[i]), ".md") && !strings.HasSuffix(strings.ToLower(matches[j]), ".md")
+	})
+	return filepath.Join(dir, matches[0])
+}
+
+// definitelyTypedDir returns where the @types package for name would be installed
+func definitelyTypedDir(pkgDir, name string) string {
+	nodeModules := filepath.Dir(pkgDir)
+	if strings.HasPrefix(name, "@") {
+		nodeModules = filepath.Dir(nodeModules)
+	}
+	typesName := strings.ReplaceAll(strings.TrimPrefix(name, "@"), "/", "__")
+	return filepath.Join(nodeModules, "@types", typesName)
+}
+
+// appendFileSection adds the content of path as a section, skipping unreadable files
+func appendFileSection(sections []pageSection, id, title, path string) []pageSection {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return sections
+	}
+	return append(sections, pageSection{ID: id, Title: title, Body: string(content)})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,106 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFetchNodeDocs_Success(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	pkgDir := filepath.Join(t.TempDir(), "node_modules", "@acme", "widgets")
+	writeTestFile(t, filepath.Join(pkgDir, "package.json"), `{"name":"@acme/widgets","version":"2.1.0","types":"dist/index.d.ts"}`)
+	writeTestFile(t, filepath.Join(pkgDir, "README.md"), "# Widgets\n\nUse <Widget> everywhere.\n")
+	writeTestFile(t, filepath.Join(pkgDir, "CHANGELOG.md"), "## 2.1.0\n\n- Added Widget.render\n")
+	writeTestFile(t, filepath.Join(pkgDir, "dist", "index.d.ts"), "export declare function render(): void;\n")
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "@acme/widgets",
+		Version: "2.1.0",
+		Type:    "npm",
+		Dir:     pkgDir,
+	}
+
+	expectedPath := filepath.Join(cacheHome, "pudding", "docs", "npm", "@acme", "widgets@2.1.0", "index.html")
+	browserMock.
+		On("Open", "file://"+expectedPath+"#:~:text=render").
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "render", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	page, err := os.ReadFile(expectedPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Use &lt;Widget&gt; everywhere.")
+	assert.Contains(t, string(page), "Added Widget.render")
+	assert.Contains(t, string(page), "export declare function render(): void;")
+
+	assert.Len(t, cmdMock.Calls, 0, "expected no commands to be run")
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchNodeDocs_DefinitelyTyped(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	nodeModules := filepath.Join(t.TempDir(), "node_modules")
+	pkgDir := filepath.Join(nodeModules, "express")
+	writeTestFile(t, filepath.Join(pkgDir, "package.json"), `{"name":"express","version":"4.18.2"}`)
+	writeTestFile(t, filepath.Join(nodeModules, "@types", "express", "index.d.ts"), "declare function e(): core.Express;\n")
+
+	dep := &parser.Dependency{Name: "express", Version: "4.18.2", Type: "npm", Dir: pkgDir}
+
+	docDir, err := renderNodeDocs(dep)
+	require.NoError(t, err)
+
+	page, err := os.ReadFile(filepath.Join(docDir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Typings (@types)")
+	assert.Contains(t, string(page), "declare function e(): core.Express;")
+}
+
+func TestFetchNodeDocs_NotInstalled(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "express",
+		Version: "4.18.2",
+		Type:    "npm",
+		Dir:     filepath.Join(t.TempDir(), "node_modules", "express"),
+	}
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "express is not installed")
+
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
+
+func TestFetchNodeDocs_VersionMismatch(t *testing.T) {
+	pkgDir := filepath.Join(t.TempDir(), "node_modules", "express")
+	writeTestFile(t, filepath.Join(pkgDir, "package.json"), `{"name":"express","version":"4.17.0"}`)
+
+	dep := &parser.Dependency{Name: "express", Version: "4.18.2", Type: "npm", Dir: pkgDir}
+
+	_, err := renderNodeDocs(dep)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "installed express is 4.17.0 but the lockfile has 4.18.2")
+}
@@ -0,0 +1,114 @@
+package docs
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// onlineURL returns the hosted docs of a dependency when the config prefers
+// them to local docs for its type
+func onlineURL(dep *parser.Dependency, keywords string) (string, bool, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		return "", false, err
+	}
+	if !cfg.PrefersOnline(dep.Type) {
+		return "", false, nil
+	}
+	docsURL, err := onlineDocsURL(dep, keywords, cfg.Mirrors)
+	return docsURL, true, err
+}
+
+// onlineDocsURL returns the docs a package publishes, searching them for
+// keywords where the site supports it
+func onlineDocsURL(dep *parser.Dependency, keywords string, mirrors config.Mirrors) (string, error) {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return "", fmt.Errorf("no online docs for %s dependencies", dep.Type)
+	}
+	return provider.Search(dep, keywords, mirrors)
+}
+
+func hexSearchURL(dep *parser.Dependency, keywords string, _ config.Mirrors) (string, error) {
+	if err := hexPackage(dep); err != nil {
+		return "", err
+	}
+	docsURL := fmt.Sprintf("https://hexdocs.pm/%s/%s/", url.PathEscape(dep.Name), url.PathEscape(dep.Version))
+	if keywords != "" {
+		docsURL += "search.html?q=" + url.QueryEscape(keywords)
+	}
+	return docsURL, nil
+}
+
+// hexFallbackURL asks the Hex API for the best docs of a release, from
+// mirrors.hex_api or the HEX_API_URL mix uses, warning when it's retired
+func hexFallbackURL(dep *parser.Dependency, keywords string, mirrors config.Mirrors, warn func(error)) (string, error) {
+	if err := hexPackage(dep); err != nil {
+		return "", err
+	}
+	client := NewHexAPIClient()
+	if base := cmp.Or(mirrors.HexAPI, os.Getenv("HEX_API_URL")); base != "" {
+		client.baseURL = strings.TrimSuffix(base, "/")
+	}
+
+	release, err := client.GetRelease(dep.Name, dep.Version)
+	if err != nil && !errors.Is(err, errHexNotFound) {
+		return "", err
+	}
+	if release != nil && release.Retirement != nil {
+		warn(fmt.Errorf("%s %s is retired (%s): %s", dep.Name, dep.Version, release.Retirement.Reason, release.Retirement.Message))
+	}
+
+	docsURL, exDoc, err := client.documentationURL(dep.Name, dep.Version, release)
+	if err != nil {
+		return "", err
+	}
+	if exDoc && keywords != "" {
+		docsURL = strings.TrimSuffix(docsURL, "/") + "/search.html?q=" + url.QueryEscape(keywords)
+	}
+	return docsURL, nil
+}
+
+func gemSearchURL(dep *parser.Dependency, _ string, mirrors config.Mirrors) (string, error) {
+	client := NewRubyGemsAPIClient()
+	if mirrors.RubyGems != "" {
+		client.baseURL = strings.TrimSuffix(mirrors.RubyGems, "/") + "/api/v2"
+	}
+	return client.GetDocumentationURL(dep.Name, dep.Version)
+}
+
+func goSearchURL(dep *parser.Dependency, keywords string, _ config.Mirrors) (string, error) {
+	version := url.PathEscape(dep.Version)
+	// The standard library is one package per page
+	if dep.Name == "go" {
+		if keywords != "" {
+			return fmt.Sprintf("https://pkg.go.dev/%s@go%s", keywords, version), nil
+		}
+		return fmt.Sprintf("https://pkg.go.dev/std@go%s", version), nil
+	}
+	return fmt.Sprintf("https://pkg.go.dev/%s@%s", dep.Name, version), nil
+}
+
+func npmSearchURL(dep *parser.Dependency, _ string, _ config.Mirrors) (string, error) {
+	return fmt.Sprintf("https://www.npmjs.com/package/%s/v/%s", dep.Name, url.PathEscape(dep.Version)), nil
+}
+
+func pypiSearchURL(dep *parser.Dependency, _ string, _ config.Mirrors) (string, error) {
+	return fmt.Sprintf("https://pypi.org/project/%s/%s/", url.PathEscape(dep.Name), url.PathEscape(dep.Version)), nil
+}
+
+func crateSearchURL(dep *parser.Dependency, keywords string, _ config.Mirrors) (string, error) {
+	name := url.PathEscape(dep.Name)
+	docsURL := fmt.Sprintf("https://docs.rs/%s/%s/%s/", name, url.PathEscape(dep.Version), strings.ReplaceAll(name, "-", "_"))
+	if keywords != "" {
+		docsURL += "?search=" + url.QueryEscape(keywords)
+	}
+	return docsURL, nil
+}
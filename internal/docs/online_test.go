@@ -0,0 +1,114 @@
+package docs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func TestOnlineDocsURL(t *testing.T) {
+	tests := []struct {
+		dep      parser.Dependency
+		keywords string
+		want     string
+	}{
+		{parser.Dependency{Name: "ecto", Version: "3.11.0", Type: "elixir"}, "", "https://hexdocs.pm/ecto/3.11.0/"},
+		{parser.Dependency{Name: "ecto", Version: "3.11.0", Type: "elixir"}, "cast", "https://hexdocs.pm/ecto/3.11.0/search.html?q=cast"},
+		{parser.Dependency{Name: "golang.org/x/net", Version: "v0.44.0", Type: "go"}, "", "https://pkg.go.dev/golang.org/x/net@v0.44.0"},
+		{parser.Dependency{Name: "go", Version: "1.24.4", Type: "go"}, "net/http", "https://pkg.go.dev/net/http@go1.24.4"},
+		{parser.Dependency{Name: "@types/node", Version: "20.11.0", Type: "npm"}, "", "https://www.npmjs.com/package/@types/node/v/20.11.0"},
+		{parser.Dependency{Name: "requests", Version: "2.31.0", Type: "pypi"}, "", "https://pypi.org/project/requests/2.31.0/"},
+		{parser.Dependency{Name: "serde-json", Version: "1.0.0", Type: "crate"}, "Value", "https://docs.rs/serde-json/1.0.0/serde_json/?search=Value"},
+	}
+	for _, tt := range tests {
+		got, err := onlineDocsURL(&tt.dep, tt.keywords, config.Mirrors{})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := onlineDocsURL(&parser.Dependency{Name: "x", Version: "1", Type: "cobol"}, "", config.Mirrors{})
+	assert.Error(t, err)
+}
+
+func TestOnlineDocsURL_RubyGemsMirror(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/rubygems/rack/versions/3.0.8.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"number":"3.0.8","metadata":{"documentation_uri":"https://gems.example.com/docs/rack"}}`))
+	}))
+	defer server.Close()
+
+	dep := &parser.Dependency{Name: "rack", Version: "3.0.8", Type: "gem"}
+	got, err := onlineDocsURL(dep, "", config.Mirrors{RubyGems: server.URL + "/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://gems.example.com/docs/rack", got)
+}
+
+func TestHexFallbackURL_SelfHostedRepo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/billing/releases/2.0.0":
+			w.Write([]byte(`{"version":"2.0.0","has_docs":false,"retirement":{"reason":"security","message":"Upgrade to 2.0.1"}}`))
+		case "/api/packages/billing":
+			w.Write([]byte(`{"name":"billing","meta":{"links":{"Docs":"https://docs.acme.dev/billing"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("HEX_API_URL", "http://unused.example.com")
+
+	// mirrors.hex_api wins over HEX_API_URL, and packages without docs link
+	// to where they're documented
+	dep := &parser.Dependency{Name: "billing", Version: "2.0.0", Type: "elixir"}
+	var warnings []error
+	got, err := hexFallbackURL(dep, "charge", config.Mirrors{HexAPI: server.URL + "/api"}, func(err error) {
+		warnings = append(warnings, err)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://docs.acme.dev/billing", got)
+	require.Len(t, warnings, 1)
+	assert.EqualError(t, warnings[0], "billing 2.0.0 is retired (security): Upgrade to 2.0.1")
+}
+
+func TestHexFallbackURL_HexdocsDefault(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/packages/jason/releases/1.4.1":
+			w.Write([]byte(`{"version":"1.4.1","has_docs":false}`))
+		case "/packages/jason":
+			w.Write([]byte(`{"name":"jason","meta":{"links":{}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The release is fetched once, and the keywords kept in the guessed docs
+	dep := &parser.Dependency{Name: "jason", Version: "1.4.1", Type: "elixir"}
+	got, err := hexFallbackURL(dep, "decode", config.Mirrors{HexAPI: server.URL}, printWarning)
+	require.NoError(t, err)
+	assert.Equal(t, "https://hexdocs.pm/jason/1.4.1/search.html?q=decode", got)
+	assert.Equal(t, 2, requests)
+}
+
+func TestHexFallbackURL_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	dep := &parser.Dependency{Name: "jason", Version: "1.4.1", Type: "elixir"}
+	_, err := hexFallbackURL(dep, "", config.Mirrors{HexAPI: server.URL}, printWarning)
+	assert.ErrorContains(t, err, "status 502")
+}
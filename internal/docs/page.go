@@ -0,0 +1,189 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Page is an HTML file in a local doc set and the part of it to show
+type Page struct {
+	Path     string // HTML file on disk
+	Fragment string // id of the element documenting the symbol, empty for the whole page
+}
+
+// LocatePage fetches docs for a dependency if needed and finds the page
+// documenting symbol, such as "Ecto.Changeset.cast/4" or "Rack::Request#get".
+// An empty symbol returns the page FetchAndOpen would open.
+func LocatePage(dep *parser.Dependency, symbol string) (*Page, error) {
+	return locatePageWithFuncs(dep, symbol, defaultCommandRunner)
+}
+
+// locatePageWithFuncs allows dependency injection for testing
+func locatePageWithFuncs(dep *parser.Dependency, symbol string, cmdRunner CommandRunner) (*Page, error) {
+	provider, err := providerFor(dep.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some symbols, like Go standard library packages, get a page of their own
+	if symbol != "" {
+		if pageURL, ok, err := provider.Resolve(dep, symbol, cmdRunner); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			return &Page{Path: strings.TrimPrefix(pageURL, "file://")}, nil
+		}
+	}
+
+	entry, err := fetchWithFuncs(dep, cmdRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol == "" {
+		return startPage(entry, provider)
+	}
+	return provider.Locate(entry, symbol)
+}
+
+var metaRefreshRegex = regexp.MustCompile(`(?i)<meta\s+http-equiv="refresh"\s+content="\d+;\s*url=([^"]+)"`)
+
+// startPage returns the landing page of a doc set, following the meta
+// refresh ExDoc's index.html uses to redirect to the main module
+func startPage(entry *cache.Entry, provider Provider) (*Page, error) {
+	pageURL := strings.TrimPrefix(provider.URL(entry.Path, ""), "file://")
+	path, fragment, _ := strings.Cut(pageURL, "#")
+	if strings.HasSuffix(path, "/") {
+		path += "index.html"
+	}
+
+	if content, err := os.ReadFile(path); err == nil {
+		if match := metaRefreshRegex.FindSubmatch(content); match != nil {
+			target := string(match[1])
+			path, fragment, _ = strings.Cut(filepath.Join(filepath.Dir(path), target), "#")
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to find the start page of %s %s: %w", entry.Name, entry.Version, err)
+	}
+	return &Page{Path: path, Fragment: fragment}, nil
+}
+
+// locateExDocPage finds a module page, e.g. Ecto.Changeset.html, and the
+// section for a function, type or callback on it
+func locateExDocPage(dir, symbol string) (*Page, error) {
+	name, arity := symbol, ""
+	if i := strings.LastIndex(symbol, "/"); i > 0 {
+		if _, err := strconv.Atoi(symbol[i+1:]); err == nil {
+			name, arity = symbol[:i], symbol[i+1:]
+		}
+	}
+
+	if arity == "" && fileExists(filepath.Join(dir, name+".html")) {
+		return &Page{Path: filepath.Join(dir, name+".html")}, nil
+	}
+
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return nil, fmt.Errorf("no module named '%s' in these docs", name)
+	}
+	module, function := name[:i], name[i+1:]
+	path := filepath.Join(dir, module+".html")
+	if !fileExists(path) {
+		return nil, fmt.Errorf("no module named '%s' in these docs", module)
+	}
+
+	// Sections are ids like "cast/4", "t:t/0" or "c:init/1"
+	for _, id := range pageIDs(path) {
+		bare := id
+		if kind, rest, ok := strings.Cut(id, ":"); ok && len(kind) == 1 {
+			bare = rest
+		}
+		if arity != "" && bare == function+"/"+arity || arity == "" && strings.HasPrefix(bare, function+"/") {
+			return &Page{Path: path, Fragment: id}, nil
+		}
+	}
+	return nil, fmt.Errorf("no function '%s' in %s", strings.TrimPrefix(symbol, module+"."), module)
+}
+
+// locateRDocPage finds a class page, e.g. Rack/Request.html, and the
+// section for an instance (#get) or class (.new, ::new) method on it
+func locateRDocPage(dir, symbol string) (*Page, error) {
+	classPage := func(class string) string {
+		return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(class, "::", "/"))+".html")
+	}
+
+	if class, method, ok := strings.Cut(symbol, "#"); ok {
+		return rdocMethodPage(classPage(class), class, method, "i")
+	}
+	if path := classPage(symbol); fileExists(path) {
+		return &Page{Path: path}, nil
+	}
+
+	// A trailing lowercase name after . or :: is a class method
+	i := max(strings.LastIndex(symbol, "."), strings.LastIndex(symbol, "::"))
+	if i < 0 {
+		return nil, fmt.Errorf("no class or module named '%s' in these docs", symbol)
+	}
+	class, method := symbol[:i], strings.TrimLeft(symbol[i:], ".:")
+	return rdocMethodPage(classPage(class), class, method, "c")
+}
+
+func rdocMethodPage(path, class, method, kind string) (*Page, error) {
+	if !fileExists(path) {
+		return nil, fmt.Errorf("no class or module named '%s' in these docs", class)
+	}
+	id := "method-" + kind + "-" + rdocAnchor(method)
+	if !pageHasID(path, id) {
+		return nil, fmt.Errorf("no method '%s' in %s", method, class)
+	}
+	return &Page{Path: path, Fragment: id}, nil
+}
+
+// rdocAnchor escapes a method name the way RDoc does in anchors, e.g.
+// "empty?" becomes "empty-3F"
+func rdocAnchor(method string) string {
+	var sb strings.Builder
+	for _, r := range method {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			sb.WriteRune(r)
+		} else {
+			for _, b := range []byte(string(r)) {
+				fmt.Fprintf(&sb, "-%02X", b)
+			}
+		}
+	}
+	return sb.String()
+}
+
+var idAttrRegex = regexp.MustCompile(`\bid="([^"]+)"`)
+
+// pageIDs returns the id attributes in an HTML file, in order
+func pageIDs(path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	ids := []string{}
+	for _, match := range idAttrRegex.FindAllSubmatch(content, -1) {
+		ids = append(ids, string(match[1]))
+	}
+	return ids
+}
+
+func pageHasID(path, id string) bool {
+	for _, candidate := range pageIDs(path) {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
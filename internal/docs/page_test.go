@@ -0,0 +1,119 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func exDocFixture(t *testing.T) string {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"index.html":          `<html><head><meta http-equiv="refresh" content="0; url=Ecto.html"></head></html>`,
+		"Ecto.html":           `<div id="content"><h1>Ecto</h1></div>`,
+		"Ecto.Changeset.html": `<section class="detail" id="t:t/1"></section><section class="detail" id="cast/3"></section><section class="detail" id="cast/4"></section><section class="detail" id="c:init/1"></section>`,
+	})
+	return dir
+}
+
+func TestLocateExDocPage(t *testing.T) {
+	dir := exDocFixture(t)
+
+	tests := []struct {
+		symbol   string
+		file     string
+		fragment string
+	}{
+		{"Ecto.Changeset", "Ecto.Changeset.html", ""},
+		{"Ecto.Changeset.cast/4", "Ecto.Changeset.html", "cast/4"},
+		{"Ecto.Changeset.cast", "Ecto.Changeset.html", "cast/3"},
+		{"Ecto.Changeset.t/1", "Ecto.Changeset.html", "t:t/1"},
+		{"Ecto.Changeset.init", "Ecto.Changeset.html", "c:init/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			page, err := locateExDocPage(dir, tt.symbol)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.file), page.Path)
+			assert.Equal(t, tt.fragment, page.Fragment)
+		})
+	}
+
+	_, err := locateExDocPage(dir, "Ecto.Changeset.cast/9")
+	assert.ErrorContains(t, err, "no function 'cast/9' in Ecto.Changeset")
+	_, err = locateExDocPage(dir, "Ecto.Query.from")
+	assert.ErrorContains(t, err, "no module named 'Ecto.Query'")
+}
+
+func TestLocateRDocPage(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Rack/Request.html": `<div id="method-c-new" class="method-detail"></div><div id="method-i-get" class="method-detail"></div><div id="method-i-xhr-3F" class="method-detail"></div>`,
+	})
+
+	tests := []struct {
+		symbol   string
+		fragment string
+	}{
+		{"Rack::Request", ""},
+		{"Rack::Request#get", "method-i-get"},
+		{"Rack::Request#xhr?", "method-i-xhr-3F"},
+		{"Rack::Request.new", "method-c-new"},
+		{"Rack::Request::new", "method-c-new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			page, err := locateRDocPage(dir, tt.symbol)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, "Rack", "Request.html"), page.Path)
+			assert.Equal(t, tt.fragment, page.Fragment)
+		})
+	}
+
+	_, err := locateRDocPage(dir, "Rack::Request#post")
+	assert.ErrorContains(t, err, "no method 'post' in Rack::Request")
+	_, err = locateRDocPage(dir, "Rack::Response")
+	assert.Error(t, err)
+}
+
+func TestRDocAnchor(t *testing.T) {
+	assert.Equal(t, "save-21", rdocAnchor("save!"))
+	assert.Equal(t, "-5B-5D", rdocAnchor("[]"))
+	assert.Equal(t, "to_s", rdocAnchor("to_s"))
+}
+
+func TestLocatePage_FollowsExDocRedirect(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	dir := exDocFixture(t)
+
+	dep := &parser.Dependency{Name: "ecto_show", Version: "3.11.0", Type: "elixir"}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto_show", "3.11.0").
+		Return([]byte("Docs fetched: "+dir+"\n"), nil).
+		Once()
+
+	page, err := locatePageWithFuncs(dep, "", cmdMock.Run)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "Ecto.html"), page.Path)
+
+	// The second lookup is served from the cache
+	page, err = locatePageWithFuncs(dep, "Ecto.Changeset.cast/4", cmdMock.Run)
+	require.NoError(t, err)
+	assert.Equal(t, "cast/4", page.Fragment)
+
+	cmdMock.AssertExpectations(t)
+}
@@ -0,0 +1,184 @@
+package docs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Provider produces and addresses the documentation of one ecosystem's
+// dependencies. Every provider must pass the conformance suite in
+// provider_test.go.
+type Provider interface {
+	// Source says how docs are produced, recorded in the cache
+	Source() string
+	// Label is what the docs are called in messages, e.g. "rdoc"
+	Label() string
+	// Resolve returns the URL of docs produced for one lookup rather than a
+	// whole doc set, such as a Go standard library package. ok is false
+	// when the lookup is answered from the doc set.
+	Resolve(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (url string, ok bool, err error)
+	// Fetch produces the doc set of a dependency and returns its directory
+	Fetch(dep *parser.Dependency, cmdRunner CommandRunner) (string, error)
+	// Current reports whether a cached doc set still documents version
+	Current(docPath, version string) bool
+	// URL links to a doc set, searching it for keywords when given
+	URL(docPath, keywords string) string
+	// Locate finds the page of a doc set documenting symbol
+	Locate(entry *cache.Entry, symbol string) (*Page, error)
+	// Search returns the hosted docs of a dependency, searched for keywords
+	// where the site supports it
+	Search(dep *parser.Dependency, keywords string, mirrors config.Mirrors) (string, error)
+}
+
+// FallbackProvider is a Provider choosing the hosted docs to open when Fetch
+// fails for want of the tool producing docs, see toolUnavailableError, and
+// passing what the user should know about them to warn. Providers that
+// don't implement it fall back to Search.
+type FallbackProvider interface {
+	Fallback(dep *parser.Dependency, keywords string, mirrors config.Mirrors, warn func(error)) (string, error)
+}
+
+// Plugin teaches pudding an ecosystem: how to find and parse its projects,
+// and how to document their dependencies
+type Plugin struct {
+	parser.Ecosystem
+	Provider Provider
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		"elixir": &docsBackend{source: "mix hex.docs", label: "docs", fetch: fetchHexDocs, url: hexDocsURL,
+			locate: locateExDocPage, search: hexSearchURL, fallback: hexFallbackURL},
+		"gem": &docsBackend{source: "rdoc", label: "rdoc", fetch: fetchGemDocs, url: gemDocsURL,
+			locate: locateRDocPage, search: gemSearchURL},
+		"go": &docsBackend{source: "go doc", label: "go docs", fetch: fetchGoDocs, url: renderedPageURL,
+			resolve: resolveGoPackageDocs, search: goSearchURL},
+		"npm": &docsBackend{source: "node_modules", label: "npm docs", fetch: fetchNodeDocs, url: renderedPageURL,
+			search: npmSearchURL},
+		"pypi": &docsBackend{source: "dist-info", label: "python docs", fetch: fetchPythonDocs, url: renderedPageURL,
+			search: pypiSearchURL},
+		"crate": &docsBackend{source: "cargo doc", label: "rustdoc", fetch: fetchRustDocs, url: rustDocsURL,
+			current: rustDocsGenerated, search: crateSearchURL},
+	}
+)
+
+// Register adds an ecosystem: its projects are detected after the ones
+// already registered, and its dependencies documented by its provider
+func Register(plugin Plugin) error {
+	if plugin.Provider == nil {
+		return fmt.Errorf("ecosystem %q needs a docs provider", plugin.ProjectType)
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, ok := providers[plugin.DepType]; ok {
+		return fmt.Errorf("ecosystem %q is already registered", plugin.ProjectType)
+	}
+	if err := parser.Register(plugin.Ecosystem); err != nil {
+		return err
+	}
+	providers[plugin.DepType] = plugin.Provider
+	return nil
+}
+
+// Plugins returns every registered ecosystem with its provider, in detection
+// order
+func Plugins() []Plugin {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	plugins := []Plugin{}
+	for _, e := range parser.Ecosystems() {
+		if provider, ok := providers[e.DepType]; ok {
+			plugins = append(plugins, Plugin{Ecosystem: e, Provider: provider})
+		}
+	}
+	return plugins
+}
+
+// providerFor returns the provider for a dependency type, such as "gem". Each
+// dependency carries its own type, so one project can mix ecosystems.
+func providerFor(depType string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[depType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported dependency type: %q", depType)
+	}
+	return provider, nil
+}
+
+// docsBackend is the Provider of the built-in ecosystems, each one a set of
+// functions. Only source, label, fetch and url are required.
+type docsBackend struct {
+	source   string // how docs are produced, recorded in the cache
+	label    string // what the docs are called in error messages
+	fetch    func(dep *parser.Dependency, cmdRunner CommandRunner) (string, error)
+	url      func(docPath, keywords string) string
+	resolve  func(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (string, bool, error)
+	current  func(docPath, version string) bool // check that cached docs are still current
+	locate   func(dir, symbol string) (*Page, error)
+	search   func(dep *parser.Dependency, keywords string, mirrors config.Mirrors) (string, error)
+	fallback func(dep *parser.Dependency, keywords string, mirrors config.Mirrors, warn func(error)) (string, error) // defaults to search
+}
+
+func (b *docsBackend) Source() string { return b.source }
+
+func (b *docsBackend) Label() string { return b.label }
+
+func (b *docsBackend) Resolve(dep *parser.Dependency, keywords string, cmdRunner CommandRunner) (string, bool, error) {
+	if b.resolve == nil {
+		return "", false, nil
+	}
+	return b.resolve(dep, keywords, cmdRunner)
+}
+
+func (b *docsBackend) Fetch(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+	return b.fetch(dep, cmdRunner)
+}
+
+func (b *docsBackend) Current(docPath, version string) bool {
+	return b.current == nil || b.current(docPath, version)
+}
+
+func (b *docsBackend) URL(docPath, keywords string) string {
+	return b.url(docPath, keywords)
+}
+
+// Locate uses the backend's own lookup, or else finds a section named after
+// the symbol on the start page, as rendered pages name them after packages
+// and modules
+func (b *docsBackend) Locate(entry *cache.Entry, symbol string) (*Page, error) {
+	if b.locate != nil {
+		return b.locate(entry.Path, symbol)
+	}
+	page, err := startPage(entry, b)
+	if err != nil {
+		return nil, err
+	}
+	if !pageHasID(page.Path, symbol) {
+		return nil, fmt.Errorf("no section '%s' in the %s docs for %s", symbol, b.label, entry.Name)
+	}
+	page.Fragment = symbol
+	return page, nil
+}
+
+func (b *docsBackend) Search(dep *parser.Dependency, keywords string, mirrors config.Mirrors) (string, error) {
+	if b.search == nil {
+		return "", fmt.Errorf("no online docs for %s dependencies", dep.Type)
+	}
+	return b.search(dep, keywords, mirrors)
+}
+
+func (b *docsBackend) Fallback(dep *parser.Dependency, keywords string, mirrors config.Mirrors, warn func(error)) (string, error) {
+	if b.fallback == nil {
+		return b.Search(dep, keywords, mirrors)
+	}
+	return b.fallback(dep, keywords, mirrors, warn)
+}
@@ -0,0 +1,268 @@
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// -----------------------------------------------------------------------------
+// Conformance suite
+// -----------------------------------------------------------------------------
+
+// conformanceCase is a dependency a provider can document once setup has
+// mocked its commands and written the files they would produce
+type conformanceCase struct {
+	dep      parser.Dependency
+	keywords string // a plain word, not a symbol
+	setup    func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock)
+}
+
+// failingRunner is a system where every command fails
+func failingRunner(name string, args ...string) ([]byte, error) {
+	return nil, errors.New("command failed")
+}
+
+// testProviderConformance checks the behaviour every provider must share,
+// driving it directly and through the registry
+func testProviderConformance(t *testing.T, provider Provider, c conformanceCase) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dep := c.dep
+
+	assert.NotEmpty(t, provider.Source(), "Source")
+	assert.NotEmpty(t, provider.Label(), "Label")
+
+	// Missing docs are an error naming the dependency, not a panic
+	broken := dep
+	broken.Dir, broken.ProjectRoot = t.TempDir(), t.TempDir()
+	t.Setenv("CARGO_TARGET_DIR", t.TempDir())
+	_, err := provider.Fetch(&broken, failingRunner)
+	if assert.Error(t, err, "Fetch without docs") {
+		assert.Contains(t, err.Error(), dep.Name)
+	}
+
+	cmdMock := &CommandRunnerMock{}
+	c.setup(t, &dep, cmdMock)
+
+	// Lookups without keywords are always answered from the doc set
+	_, ok, err := provider.Resolve(&dep, "", cmdMock.Run)
+	assert.False(t, ok, "Resolve without keywords")
+	assert.NoError(t, err)
+
+	docPath, err := provider.Fetch(&dep, cmdMock.Run)
+	require.NoError(t, err, "Fetch")
+	assert.DirExists(t, docPath)
+	assert.True(t, provider.Current(docPath, dep.Version), "Current right after Fetch")
+
+	start := provider.URL(docPath, "")
+	assert.True(t, strings.HasPrefix(start, "file://"+docPath), "URL %s outside %s", start, docPath)
+	search := provider.URL(docPath, c.keywords)
+	assert.NotEqual(t, start, search, "URL with keywords")
+	assert.Contains(t, search, c.keywords)
+
+	entry := &cache.Entry{Ecosystem: dep.Type, Name: dep.Name, Version: dep.Version, Path: docPath, Source: provider.Source()}
+	page, err := startPage(entry, provider)
+	if assert.NoError(t, err, "start page") {
+		assert.FileExists(t, page.Path)
+	}
+	_, err = provider.Locate(entry, "NoSuchSymbol")
+	assert.Error(t, err, "Locate of a missing symbol")
+
+	// Hosted docs are linked or fail cleanly, never point at local files
+	closed := httptest.NewServer(nil)
+	closed.Close()
+	mirrors := config.Mirrors{Hex: closed.URL, HexAPI: closed.URL, RubyGems: closed.URL, GoProxy: closed.URL}
+	if hosted, err := provider.Search(&dep, c.keywords, mirrors); err == nil {
+		assert.True(t, strings.HasPrefix(hosted, "https://") || strings.HasPrefix(hosted, "http://"), "Search = %s", hosted)
+	}
+	if fallback, ok := provider.(FallbackProvider); ok {
+		if hosted, err := fallback.Fallback(&dep, c.keywords, mirrors, func(error) {}); err == nil {
+			assert.True(t, strings.HasPrefix(hosted, "https://") || strings.HasPrefix(hosted, "http://"), "Fallback = %s", hosted)
+		}
+	}
+
+	// The registry routes the dependency to the provider by its type
+	browserMock := &BrowserOpenerMock{}
+	browserMock.On("Open", search).Return(nil).Once()
+	require.NoError(t, callFetchAndOpen(&dep, c.keywords, cmdMock, browserMock))
+	browserMock.AssertExpectations(t)
+}
+
+// conformanceCases set up a dependency of every built-in ecosystem
+var conformanceCases = map[string]conformanceCase{
+	"elixir": {
+		dep:      parser.Dependency{Name: "conformance_plug", Version: "1.15.0", Type: "elixir"},
+		keywords: "conn",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			docPath := t.TempDir()
+			writeTestFile(t, filepath.Join(docPath, "index.html"), `<meta http-equiv="refresh" content="0; url=Plug.html">`)
+			writeTestFile(t, filepath.Join(docPath, "Plug.html"), "<h1>Plug</h1>")
+			cmdMock.On("Run", "mix", "hex.docs", "fetch", dep.Name, dep.Version).
+				Return([]byte("Docs fetched: "+docPath+"\n"), nil)
+		},
+	},
+	"gem": {
+		dep:      parser.Dependency{Name: "conformance_rack", Version: "3.0.8", Type: "gem"},
+		keywords: "request",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			gemHome := t.TempDir()
+			writeTestFile(t, filepath.Join(gemHome, "doc", dep.Name+"-"+dep.Version, "rdoc", "table_of_contents.html"), "<h1>Contents</h1>")
+			cmdMock.On("Run", "rdoc", dep.Name, "--rdoc", "--version", dep.Version).Return([]byte("ok"), nil)
+			cmdMock.On("Run", "sh", "-c", "gem env home").Return([]byte(gemHome+"\n"), nil)
+		},
+	},
+	"go": {
+		dep:      parser.Dependency{Name: "example.com/conformance", Version: "v1.0.0", Type: "go"},
+		keywords: "Decode",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			dep.Dir = t.TempDir()
+			cmdMock.On("Run", "sh", "-c", goDocScript(dep.Dir)).
+				Return([]byte(goPackageMarker+"example.com/conformance\npackage conformance\n\nfunc Decode() error\n"), nil)
+		},
+	},
+	"npm": {
+		dep:      parser.Dependency{Name: "conformance-widgets", Version: "2.1.0", Type: "npm"},
+		keywords: "render",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			dep.Dir = filepath.Join(t.TempDir(), "node_modules", dep.Name)
+			writeTestFile(t, filepath.Join(dep.Dir, "package.json"), `{"name":"conformance-widgets","version":"2.1.0"}`)
+			writeTestFile(t, filepath.Join(dep.Dir, "README.md"), "# Widgets\n\nCall render.\n")
+		},
+	},
+	"pypi": {
+		dep:      parser.Dependency{Name: "conformance-requests", Version: "2.31.0", Type: "pypi"},
+		keywords: "Session",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			dep.Dir = t.TempDir()
+			distInfo := filepath.Join(dep.Dir, "conformance_requests-2.31.0.dist-info")
+			writeTestFile(t, filepath.Join(distInfo, "METADATA"), "Name: conformance-requests\nVersion: 2.31.0\n\nUse a Session.\n")
+			writeTestFile(t, filepath.Join(distInfo, "top_level.txt"), "conformance_requests\n")
+			writeTestFile(t, filepath.Join(dep.Dir, "conformance_requests", "__init__.py"), "\"\"\"Requests with a Session.\"\"\"\n")
+		},
+	},
+	"crate": {
+		dep:      parser.Dependency{Name: "conformance-serde", Version: "1.0.197", Type: "crate"},
+		keywords: "from_str",
+		setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+			dep.ProjectRoot = t.TempDir()
+			targetDir := t.TempDir()
+			t.Setenv("CARGO_TARGET_DIR", targetDir)
+			cmdMock.On("Run", "cargo", "doc", "--manifest-path", filepath.Join(dep.ProjectRoot, "Cargo.toml"), "--no-deps", "--package", dep.Name+"@"+dep.Version).
+				Run(func(mock.Arguments) {
+					writeTestFile(t, filepath.Join(targetDir, "doc", "conformance_serde", "index.html"), `<span class="version">1.0.197</span>`)
+				}).
+				Return([]byte(""), nil)
+		},
+	},
+}
+
+func TestProviders_Conformance(t *testing.T) {
+	for _, plugin := range Plugins() {
+		t.Run(plugin.DepType, func(t *testing.T) {
+			c, ok := conformanceCases[plugin.DepType]
+			if registered, isTest := testPluginCases[plugin.DepType]; isTest {
+				c, ok = registered, true
+			}
+			if !ok {
+				t.Fatalf("No conformance case for %s dependencies", plugin.DepType)
+			}
+			testProviderConformance(t, plugin.Provider, c)
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Registering an ecosystem
+// -----------------------------------------------------------------------------
+
+// fakeParser reads an OCaml-like project listing name@version lines
+type fakeParser struct{}
+
+func (fakeParser) CanParse(projectRoot string) bool {
+	_, err := os.Stat(filepath.Join(projectRoot, "deps.txt"))
+	return err == nil
+}
+
+func (fakeParser) Parse(projectRoot string) ([]parser.Dependency, error) {
+	content, err := os.ReadFile(filepath.Join(projectRoot, "deps.txt"))
+	if err != nil {
+		return nil, err
+	}
+	deps := []parser.Dependency{}
+	for _, line := range strings.Fields(string(content)) {
+		name, version, _ := strings.Cut(line, "@")
+		deps = append(deps, parser.Dependency{Name: name, Version: version, Type: "opam"})
+	}
+	return deps, nil
+}
+
+// fakePlugin documents dependencies with an `odig` command printing the
+// directory of the docs it generated
+var fakePlugin = Plugin{
+	Ecosystem: parser.Ecosystem{ProjectType: "ocaml", DepType: "opam", Manifests: []string{"deps.txt"}, Parser: fakeParser{}},
+	Provider: &docsBackend{
+		source: "odig",
+		label:  "odoc",
+		fetch: func(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+			output, err := cmdRunner("odig", "odoc", dep.Name)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate odoc for %s: %w", dep.Name, err)
+			}
+			return strings.TrimSpace(string(output)), nil
+		},
+		url: renderedPageURL,
+	},
+}
+
+var fakeConformanceCase = conformanceCase{
+	dep:      parser.Dependency{Name: "lwt", Version: "5.7.0", Type: "opam"},
+	keywords: "bind",
+	setup: func(t *testing.T, dep *parser.Dependency, cmdMock *CommandRunnerMock) {
+		docPath := t.TempDir()
+		writeTestFile(t, filepath.Join(docPath, "index.html"), `<h2 id="Lwt">Lwt</h2>`)
+		cmdMock.On("Run", "odig", "odoc", dep.Name).Return([]byte(docPath+"\n"), nil)
+	},
+}
+
+// testPluginCases set up a dependency of the ecosystems registered by tests
+var testPluginCases = map[string]conformanceCase{
+	fakePlugin.DepType: fakeConformanceCase,
+	"pub":              pubConformanceCase,
+}
+
+// registerFake registers fakePlugin once per test binary, as ecosystems
+// can't be unregistered
+var registerFake = sync.OnceValue(func() error { return Register(fakePlugin) })
+
+func TestRegister(t *testing.T) {
+	require.NoError(t, registerFake())
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "deps.txt"), "lwt@5.7.0\n")
+	deps, projectTypes, err := parser.ParseProjectDependencies(root)
+	require.NoError(t, err)
+	assert.Equal(t, []parser.ProjectType{"ocaml"}, projectTypes)
+	require.Len(t, deps, 1)
+
+	t.Run("conformance", func(t *testing.T) {
+		testProviderConformance(t, fakePlugin.Provider, fakeConformanceCase)
+	})
+
+	assert.Error(t, Register(fakePlugin), "registering twice")
+	assert.Error(t, Register(Plugin{Ecosystem: parser.Ecosystem{ProjectType: "ocaml2", DepType: "gem", Parser: fakeParser{}}, Provider: fakePlugin.Provider}))
+	assert.Error(t, Register(Plugin{Ecosystem: parser.Ecosystem{ProjectType: "ocaml2", DepType: "opam2", Parser: fakeParser{}}}))
+}
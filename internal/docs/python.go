@@ -0,0 +1,198 @@
+package docs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// pythonDocstringRegex matches a module docstring at the top of a file,
+// after any comments, encoding lines and blank lines
+var pythonDocstringRegex = regexp.MustCompile(`^(?:\s*#[^\n]*\n|\s*\n)*\s*[rRuU]?("""|''')((?s:.*?))("""|''')`)
+
+func fetchPythonDocs(dep *parser.Dependency, _ CommandRunner) (string, error) {
+	return renderPythonDocs(dep)
+}
+
+// renderPythonDocs builds a page from the installed distribution's METADATA
+// long description and the docstrings of its top-level modules
+func renderPythonDocs(dep *parser.Dependency) (string, error) {
+	if dep.Dir == "" {
+		return "", fmt.Errorf("no virtualenv found for %s; activate it or create .venv in the project", dep.Name)
+	}
+
+	distInfo := findDistInfo(dep.Dir, dep.Name, dep.Version)
+	if distInfo == "" {
+		return "", fmt.Errorf("%s %s is not installed in %s", dep.Name, dep.Version, dep.Dir)
+	}
+
+	sections := []pageSection{}
+	if description, err := readMetadataDescription(filepath.Join(distInfo, "METADATA")); err == nil && description != "" {
+		sections = append(sections, pageSection{ID: "description", Title: "Description", Body: description})
+	}
+
+	for _, module := range topLevelModules(distInfo) {
+		for _, file := range moduleFiles(dep.Dir, module) {
+			docstring := readModuleDocstring(file)
+			if docstring == "" {
+				continue
+			}
+			name := moduleName(dep.Dir, file)
+			sections = append(sections, pageSection{ID: name, Title: name, Body: docstring})
+		}
+	}
+
+	if len(sections) == 0 {
+		return "", fmt.Errorf("no description or docstrings found for %s in %s", dep.Name, distInfo)
+	}
+
+	docsDir, err := renderedDocsDir()
+	if err != nil {
+		return "", err
+	}
+	docDir := filepath.Join(docsDir, "pypi", parser.NormalizePythonName(dep.Name)+"@"+dep.Version)
+	if err := writeDocsPage(filepath.Join(docDir, "index.html"), fmt.Sprintf("%s %s", dep.Name, dep.Version), sections); err != nil {
+		return "", err
+	}
+
+	return docDir, nil
+}
+
+// findDistInfo finds the <name>-<version>.dist-info directory for a
+// distribution, comparing normalized names
+func findDistInfo(sitePackages, name, version string) string {
+	matches, _ := filepath.Glob(filepath.Join(sitePackages, "*.dist-info"))
+	for _, match := range matches {
+		base := strings.TrimSuffix(filepath.Base(match), ".dist-info")
+		idx := strings.LastIndex(base, "-")
+		if idx == -1 {
+			continue
+		}
+		if parser.NormalizePythonName(base[:idx]) == parser.NormalizePythonName(name) && base[idx+1:] == version {
+			return match
+		}
+	}
+	return ""
+}
+
+// readMetadataDescription returns the long description from a METADATA file,
+// which is either the message body or the legacy Description header
+func readMetadataDescription(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+	headers, body, _ := strings.Cut(text, "\n\n")
+	if body = strings.TrimSpace(body); body != "" {
+		return body, nil
+	}
+
+	var description []string
+	inDescription := false
+	for _, line := range strings.Split(headers, "\n") {
+		if value, found := strings.CutPrefix(line, "Description: "); found {
+			inDescription = true
+			description = append(description, value)
+			continue
+		}
+		if inDescription {
+			// Continuation lines are indented with spaces or "|"
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "|") {
+				description = append(description, strings.TrimLeft(strings.TrimPrefix(strings.TrimSpace(line), "|"), " "))
+				continue
+			}
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(description, "\n")), nil
+}
+
+// topLevelModules reads top_level.txt, falling back to the packages listed in RECORD
+func topLevelModules(distInfo string) []string {
+	modules := []string{}
+	seen := map[string]bool{}
+	add := func(module string) {
+		if module != "" && !seen[module] && !strings.HasSuffix(module, ".dist-info") && module != "__pycache__" && module != ".." {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+
+	if file, err := os.Open(filepath.Join(distInfo, "top_level.txt")); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			add(strings.TrimSpace(scanner.Text()))
+		}
+		if len(modules) > 0 {
+			return modules
+		}
+	}
+
+	if file, err := os.Open(filepath.Join(distInfo, "RECORD")); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			path, _, _ := strings.Cut(scanner.Text(), ",")
+			first, rest, nested := strings.Cut(path, "/")
+			if nested && strings.HasSuffix(rest, ".py") {
+				add(first)
+			} else if !nested && strings.HasSuffix(first, ".py") {
+				add(strings.TrimSuffix(first, ".py"))
+			}
+		}
+	}
+	return modules
+}
+
+// moduleFiles returns the source files documenting a top-level module: the
+// package __init__.py and its direct submodules, or a single module file
+func moduleFiles(sitePackages, module string) []string {
+	pkgDir := filepath.Join(sitePackages, module)
+	if fileExists(filepath.Join(pkgDir, "__init__.py")) {
+		files := []string{filepath.Join(pkgDir, "__init__.py")}
+		submodules, _ := filepath.Glob(filepath.Join(pkgDir, "*.py"))
+		sort.Strings(submodules)
+		for _, submodule := range submodules {
+			if filepath.Base(submodule) != "__init__.py" {
+				files = append(files, submodule)
+			}
+		}
+		return files
+	}
+	if file := pkgDir + ".py"; fileExists(file) {
+		return []string{file}
+	}
+	return nil
+}
+
+// moduleName converts a source file path into a dotted module name
+func moduleName(sitePackages, file string) string {
+	rel, err := filepath.Rel(sitePackages, file)
+	if err != nil {
+		rel = filepath.Base(file)
+	}
+	rel = strings.TrimSuffix(strings.TrimSuffix(filepath.ToSlash(rel), ".py"), "/__init__")
+	return strings.ReplaceAll(rel, "/", ".")
+}
+
+// readModuleDocstring extracts the module docstring from a Python source file
+func readModuleDocstring(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	matches := pythonDocstringRegex.FindSubmatch(content)
+	if matches == nil || string(matches[1]) != string(matches[3]) {
+		return ""
+	}
+	return strings.TrimSpace(string(matches[2]))
+}
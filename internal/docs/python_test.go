@@ -0,0 +1,93 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func TestFetchPythonDocs_Success(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	sitePackages := t.TempDir()
+	distInfo := filepath.Join(sitePackages, "requests-2.31.0.dist-info")
+	writeTestFile(t, filepath.Join(distInfo, "METADATA"),
+		"Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\n\n# Requests\n\nRequests is a simple HTTP library.\n")
+	writeTestFile(t, filepath.Join(distInfo, "top_level.txt"), "requests\n")
+	writeTestFile(t, filepath.Join(sitePackages, "requests", "__init__.py"),
+		"# -*- coding: utf-8 -*-\n\n\"\"\"\nRequests HTTP Library\n~~~~~~~~~~~~~~~~~~~~~\n\"\"\"\nimport urllib3\n")
+	writeTestFile(t, filepath.Join(sitePackages, "requests", "sessions.py"),
+		"'''\nrequests.sessions\n\nProvides a Session object.\n'''\n")
+	writeTestFile(t, filepath.Join(sitePackages, "requests", "compat.py"), "import sys\n")
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{
+		Name:    "requests",
+		Version: "2.31.0",
+		Type:    "pypi",
+		Dir:     sitePackages,
+	}
+
+	expectedPath := filepath.Join(cacheHome, "pudding", "docs", "pypi", "requests@2.31.0", "index.html")
+	browserMock.
+		On("Open", "file://"+expectedPath+"#:~:text=Session").
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "Session", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	page, err := os.ReadFile(expectedPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Requests is a simple HTTP library.")
+	assert.Contains(t, string(page), "Requests HTTP Library")
+	assert.Contains(t, string(page), "<h2 id=\"requests.sessions\">requests.sessions</h2>")
+	assert.NotContains(t, string(page), "requests.compat")
+
+	assert.Len(t, cmdMock.Calls, 0, "expected no commands to be run")
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchPythonDocs_NotInstalled(t *testing.T) {
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	sitePackages := t.TempDir()
+	writeTestFile(t, filepath.Join(sitePackages, "requests-2.30.0.dist-info", "METADATA"), "Name: requests\n")
+
+	dep := &parser.Dependency{Name: "requests", Version: "2.31.0", Type: "pypi", Dir: sitePackages}
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requests 2.31.0 is not installed")
+
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
+
+func TestReadMetadataDescription_LegacyHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "METADATA")
+	writeTestFile(t, path, "Metadata-Version: 1.1\nName: six\nDescription: Six is a compatibility library.\n        |\n        |It supports Python 2 and 3.\nPlatform: UNKNOWN\n")
+
+	description, err := readMetadataDescription(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Six is a compatibility library.\n\nIt supports Python 2 and 3.", description)
+}
+
+func TestFindDistInfo_NormalizesNames(t *testing.T) {
+	sitePackages := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "Flask_SQLAlchemy-3.1.1.dist-info"), 0755))
+
+	assert.Equal(t,
+		filepath.Join(sitePackages, "Flask_SQLAlchemy-3.1.1.dist-info"),
+		findDistInfo(sitePackages, "flask-sqlalchemy", "3.1.1"))
+	assert.Empty(t, findDistInfo(sitePackages, "flask-sqlalchemy", "3.0.0"))
+}
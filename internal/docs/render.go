@@ -0,0 +1,76 @@
+package docs
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+// pageSection is a titled block of preformatted text on a rendered docs page
+type pageSection struct {
+	ID    string
+	Title string
+	Body  string
+}
+
+// renderedDocsDir returns the directory pudding writes rendered docs to
+func renderedDocsDir() (string, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "docs"), nil
+}
+
+// writeDocsPage renders sections into a single self-contained HTML page
+func writeDocsPage(path, title string, sections []pageSection) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create docs directory: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	sb.WriteString("<style>body{font-family:sans-serif;max-width:60em;margin:2em auto;padding:0 1em}pre{white-space:pre-wrap}</style>\n")
+	fmt.Fprintf(&sb, "</head>\n<body>\n<h1>%s</h1>\n", html.EscapeString(title))
+
+	if len(sections) > 1 {
+		sb.WriteString("<ul>\n")
+		for _, section := range sections {
+			fmt.Fprintf(&sb, "<li><a href=\"#%s\">%s</a></li>\n", html.EscapeString(section.ID), html.EscapeString(section.Title))
+		}
+		sb.WriteString("</ul>\n")
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(&sb, "<h2 id=\"%s\">%s</h2>\n<pre>%s</pre>\n",
+			html.EscapeString(section.ID),
+			html.EscapeString(section.Title),
+			html.EscapeString(section.Body))
+	}
+	sb.WriteString("</body>\n</html>\n")
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write docs page: %w", err)
+	}
+	return nil
+}
+
+// renderedPageURL builds a file:// URL for the index page of rendered docs,
+// using a text fragment to jump to the first occurrence of keywords
+func renderedPageURL(docPath, keywords string) string {
+	pageURL := fmt.Sprintf("file://%s/index.html", docPath)
+	if keywords != "" {
+		pageURL = fmt.Sprintf("%s#:~:text=%s", pageURL, url.PathEscape(keywords))
+	}
+	return pageURL
+}
+
+// shellQuote quotes s for safe use in a POSIX shell command line
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
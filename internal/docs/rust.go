@@ -0,0 +1,62 @@
+package docs
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func rustDocsURL(docPath, keywords string) string {
+	// Construct the local URL to the crate documentation
+	rustDocsURL := fmt.Sprintf("file://%s/index.html", docPath)
+
+	// Append search query if provided
+	if keywords != "" {
+		rustDocsURL = fmt.Sprintf("%s?search=%s", rustDocsURL, url.QueryEscape(keywords))
+	}
+
+	return rustDocsURL
+}
+
+// fetchRustDocs returns target/doc/<crate> for the locked crate version,
+// running `cargo doc` only when it hasn't been generated yet
+func fetchRustDocs(dep *parser.Dependency, cmdRunner CommandRunner) (string, error) {
+	if dep.ProjectRoot == "" {
+		return "", fmt.Errorf("unknown project root for %s", dep.Name)
+	}
+
+	targetDir := os.Getenv("CARGO_TARGET_DIR")
+	if targetDir == "" {
+		targetDir = filepath.Join(dep.ProjectRoot, "target")
+	}
+	// rustdoc names the output directory after the crate, not the package
+	docPath := filepath.Join(targetDir, "doc", strings.ReplaceAll(dep.Name, "-", "_"))
+
+	if rustDocsGenerated(docPath, dep.Version) {
+		return docPath, nil
+	}
+
+	_, err := cmdRunner("cargo", "doc",
+		"--manifest-path", filepath.Join(dep.ProjectRoot, "Cargo.toml"),
+		"--no-deps",
+		"--package", dep.Name+"@"+dep.Version)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate rustdoc for %s: %w", dep.Name, err)
+	}
+
+	return docPath, nil
+}
+
+// rustDocsGenerated reports whether docPath holds rustdoc output for version.
+// rustdoc prints the crate version in the sidebar of the crate index page.
+func rustDocsGenerated(docPath, version string) bool {
+	content, err := os.ReadFile(filepath.Join(docPath, "index.html"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), `class="version">`+version+"<")
+}
@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func TestFetchRustDocs_Success_WithKeywords(t *testing.T) {
+	t.Setenv("CARGO_TARGET_DIR", "")
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	projectRoot := t.TempDir()
+	dep := &parser.Dependency{
+		Name:        "serde-json",
+		Version:     "1.0.115",
+		Type:        "crate",
+		ProjectRoot: projectRoot,
+	}
+
+	cmdMock.
+		On("Run",
+			"cargo", "doc",
+			"--manifest-path", filepath.Join(projectRoot, "Cargo.toml"),
+			"--no-deps",
+			"--package", "serde-json@1.0.115",
+		).
+		Return([]byte(""), nil).
+		Once()
+
+	expectedURL := "file://" + filepath.Join(projectRoot, "target", "doc", "serde_json") + "/index.html?search=from_str"
+
+	browserMock.
+		On("Open", expectedURL).
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "from_str", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	cmdMock.AssertExpectations(t)
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchRustDocs_ReusesGeneratedDocs(t *testing.T) {
+	targetDir := t.TempDir()
+	t.Setenv("CARGO_TARGET_DIR", targetDir)
+
+	docPath := filepath.Join(targetDir, "doc", "serde")
+	writeTestFile(t, filepath.Join(docPath, "index.html"), `<h2 class="location"><a href="#">Crate serde</a></h2><span class="version">1.0.197</span>`)
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	dep := &parser.Dependency{Name: "serde", Version: "1.0.197", Type: "crate", ProjectRoot: t.TempDir()}
+
+	browserMock.
+		On("Open", "file://"+docPath+"/index.html").
+		Return(nil).
+		Once()
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.NoError(t, err)
+
+	assert.Len(t, cmdMock.Calls, 0, "expected cargo doc not to run")
+	browserMock.AssertExpectations(t)
+}
+
+func TestFetchRustDocs_CargoFailure(t *testing.T) {
+	t.Setenv("CARGO_TARGET_DIR", "")
+
+	cmdMock := &CommandRunnerMock{}
+	browserMock := &BrowserOpenerMock{}
+
+	projectRoot := t.TempDir()
+	dep := &parser.Dependency{Name: "serde", Version: "1.0.197", Type: "crate", ProjectRoot: projectRoot}
+
+	cmdMock.
+		On("Run", "cargo", "doc", "--manifest-path", filepath.Join(projectRoot, "Cargo.toml"), "--no-deps", "--package", "serde@1.0.197").
+		Return([]byte(nil), errors.New("mock cargo error")).
+		Once()
+
+	err := callFetchAndOpen(dep, "", cmdMock, browserMock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to generate rustdoc for serde")
+
+	browserMock.AssertNotCalled(t, "Open", mock.Anything)
+}
@@ -0,0 +1,69 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+// ServerEnv names the environment variable holding the base URL of a running
+// `pd serve`, e.g. http://localhost:6464. When it's set FetchAndOpen opens
+// docs on that server instead of file:// URLs.
+const ServerEnv = "PUDDING_SERVER"
+
+// ServedURL maps a file:// URL inside a cached doc set to the same page on a
+// `pd serve` server at base, which is served under /<ecosystem>/<name>/<version>/.
+// It reports false for URLs outside every cached doc set.
+func ServedURL(base string, entries []cache.Entry, fileURL string) (string, bool) {
+	path, ok := strings.CutPrefix(fileURL, "file://")
+	if !ok {
+		return "", false
+	}
+
+	// Prefer the deepest doc set in case one is nested inside another
+	var best *cache.Entry
+	for i := range entries {
+		root := filepath.Clean(entries[i].Path)
+		if path != root && !strings.HasPrefix(path, root+"/") {
+			continue
+		}
+		if best == nil || len(root) > len(filepath.Clean(best.Path)) {
+			best = &entries[i]
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(path, filepath.Clean(best.Path))
+	if rest == "" {
+		rest = "/"
+	}
+	return strings.TrimSuffix(base, "/") + "/" + best.Key() + rest, true
+}
+
+// withServer rewrites url onto the `pd serve` server at base when it points
+// into the cache, leaving it unchanged otherwise
+func withServer(base, url string) string {
+	if store, err := cache.OpenDefault(); err == nil {
+		if entries, err := store.List(); err == nil {
+			if served, ok := ServedURL(base, entries, url); ok {
+				return served
+			}
+		}
+	}
+	return url
+}
+
+// browserOpenerFromEnv returns the opener FetchAndOpen uses
+func browserOpenerFromEnv() BrowserOpener {
+	base := os.Getenv(ServerEnv)
+	if base == "" {
+		return defaultBrowserOpener
+	}
+	return func(url string) error {
+		return defaultBrowserOpener(withServer(base, url))
+	}
+}
@@ -0,0 +1,40 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+func TestServedURL(t *testing.T) {
+	entries := []cache.Entry{
+		{Ecosystem: "elixir", Name: "phoenix", Version: "1.7.14", Path: "/home/me/.hex/docs/hexpm/phoenix/1.7.14"},
+		{Ecosystem: "go", Name: "github.com/spf13/cobra", Version: "v1.8.0", Path: "/cache/pudding/docs/go/cobra"},
+		{Ecosystem: "go", Name: "go", Version: "1.24.4", Path: "/cache/pudding/docs/go/go@1.24.4"},
+	}
+
+	tests := []struct {
+		name    string
+		fileURL string
+		want    string
+		ok      bool
+	}{
+		{"index page", "file:///home/me/.hex/docs/hexpm/phoenix/1.7.14/index.html", "http://localhost:6464/elixir/phoenix/1.7.14/index.html", true},
+		{"search query", "file:///home/me/.hex/docs/hexpm/phoenix/1.7.14/search.html?q=live+view", "http://localhost:6464/elixir/phoenix/1.7.14/search.html?q=live+view", true},
+		{"module path with slashes", "file:///cache/pudding/docs/go/cobra/index.html#Command", "http://localhost:6464/go/github.com/spf13/cobra/v1.8.0/index.html#Command", true},
+		{"doc set root", "file:///cache/pudding/docs/go/go@1.24.4", "http://localhost:6464/go/go/1.24.4/", true},
+		{"sibling directory with a shared prefix", "file:///cache/pudding/docs/go/cobra2/index.html", "", false},
+		{"outside the cache", "file:///tmp/other/index.html", "", false},
+		{"not a file URL", "https://hexdocs.pm/phoenix", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ServedURL("http://localhost:6464/", entries, tt.fileURL)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
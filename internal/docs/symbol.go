@@ -0,0 +1,187 @@
+package docs
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/search"
+)
+
+// SymbolMatch is a module, class or function found in a dependency's docs
+type SymbolMatch struct {
+	Dep  parser.Dependency
+	Name string // e.g. "Ecto.Changeset.cast/4"
+	Kind string // e.g. "function", "method"
+	URL  string // page and anchor documenting the symbol
+}
+
+var symbolRegex = regexp.MustCompile(`^[A-Z]\w*(?:(?:\.|::)\w+[?!=]?)*(?:#\w+[?!=]?)?(?:/\d+)?$`)
+
+// LooksLikeSymbol reports whether s names a module, class or function, such
+// as "Ecto.Changeset.cast/4" or "ActiveRecord::Base#find", rather than a
+// dependency or free-text search
+func LooksLikeSymbol(s string) bool {
+	return symbolRegex.MatchString(s) && strings.ContainsAny(s, ".:#/")
+}
+
+// FindSymbol looks a symbol up in the search data of the project's doc sets.
+// Dependencies named after the symbol's namespace are fetched and tried
+// first; otherwise every doc set that's already cached is searched.
+func FindSymbol(deps []parser.Dependency, symbol string) ([]SymbolMatch, error) {
+	return findSymbolWithFuncs(deps, symbol, defaultCommandRunner)
+}
+
+// findSymbolWithFuncs allows dependency injection for testing
+func findSymbolWithFuncs(deps []parser.Dependency, symbol string, cmdRunner CommandRunner) ([]SymbolMatch, error) {
+	index, err := search.OpenDefault()
+	if err != nil {
+		return nil, err
+	}
+
+	tried := map[string]bool{}
+	for _, name := range namespaceDepNames(symbol) {
+		for i := range deps {
+			dep := &deps[i]
+			if !strings.EqualFold(dep.Name, name) || tried[dep.Name] {
+				continue
+			}
+			tried[dep.Name] = true
+			entry, err := fetchWithFuncs(dep, cmdRunner)
+			if err != nil {
+				printWarning(err)
+				continue
+			}
+			if matches := symbolMatches(index, dep, entry, symbol); len(matches) > 0 {
+				return matches, nil
+			}
+		}
+	}
+
+	matches := []SymbolMatch{}
+	for i := range deps {
+		if tried[deps[i].Name] {
+			continue
+		}
+		if entry, ok := Cached(&deps[i]); ok {
+			matches = append(matches, symbolMatches(index, &deps[i], entry, symbol)...)
+		}
+	}
+	return matches, nil
+}
+
+// OpenSymbol opens the docs for a symbol found by FindSymbol and records the
+// lookup in the history
+func OpenSymbol(match SymbolMatch) error {
+	if err := browserOpenerFromEnv()(match.URL); err != nil {
+		return fmt.Errorf("failed to open docs for %s: %w", match.Name, err)
+	}
+	recordHistory(&match.Dep, match.Name)
+	return nil
+}
+
+func symbolMatches(index *search.Index, dep *parser.Dependency, entry *cache.Entry, symbol string) []SymbolMatch {
+	seg, _, err := index.Segment(*entry)
+	if err != nil {
+		return nil
+	}
+	matches := []SymbolMatch{}
+	for _, doc := range search.FindSymbol(seg, symbol) {
+		matches = append(matches, SymbolMatch{
+			Dep:  *dep,
+			Name: doc.Name,
+			Kind: doc.Kind,
+			URL:  docFileURL(entry.Path, doc.File),
+		})
+	}
+	return matches
+}
+
+// symbolURL returns the page and anchor for keywords that name a symbol in
+// a doc set, so they open exactly instead of as a search
+func symbolURL(entry *cache.Entry, keywords string) (string, bool) {
+	if !LooksLikeSymbol(keywords) {
+		return "", false
+	}
+	index, err := search.OpenDefault()
+	if err != nil {
+		return "", false
+	}
+	seg, _, err := index.Segment(*entry)
+	if err != nil {
+		return "", false
+	}
+	// Every arity of a function lives on the same page, so the first will do
+	if docs := search.FindSymbol(seg, keywords); len(docs) > 0 {
+		return docFileURL(entry.Path, docs[0].File), true
+	}
+	return "", false
+}
+
+// docFileURL builds the URL of a file in a doc set, keeping its #anchor
+func docFileURL(dir, file string) string {
+	path, anchor, hasAnchor := strings.Cut(file, "#")
+	url := "file://" + filepath.Join(dir, path)
+	if hasAnchor {
+		url += "#" + anchor
+	}
+	return url
+}
+
+// namespaceDepNames guesses which dependency a symbol's namespace belongs
+// to, most specific first: Phoenix.LiveView.Socket gives phoenix_live_view,
+// phoenixliveview, phoenix-live-view and then phoenix, and
+// ActiveRecord::Base#find gives active_record_base, ... and activerecord
+func namespaceDepNames(symbol string) []string {
+	if i := strings.IndexAny(symbol, "#/"); i >= 0 {
+		symbol = symbol[:i]
+	}
+	modules := []string{}
+	for _, part := range strings.FieldsFunc(symbol, func(r rune) bool { return r == '.' || r == ':' }) {
+		if !unicode.IsUpper([]rune(part)[0]) {
+			break
+		}
+		modules = append(modules, part)
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for n := len(modules); n >= 1; n-- {
+		snake := []string{}
+		flat := []string{}
+		for _, module := range modules[:n] {
+			snake = append(snake, snakeCase(module))
+			flat = append(flat, strings.ToLower(module))
+		}
+		underscored := strings.Join(snake, "_")
+		for _, name := range []string{underscored, strings.Join(flat, ""), strings.ReplaceAll(underscored, "_", "-")} {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// snakeCase converts a module name such as LiveView or HTTPClient to
+// live_view or http_client
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower && unicode.IsUpper(runes[i-1]) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
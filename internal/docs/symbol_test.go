@@ -0,0 +1,123 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+const exDocSearchData = `searchData={"items":[` +
+	`{"type":"module","doc":"","title":"Ecto.Changeset","ref":"Ecto.Changeset.html"},` +
+	`{"type":"function","doc":"","title":"Ecto.Changeset.cast/3","ref":"Ecto.Changeset.html#cast/3"},` +
+	`{"type":"function","doc":"","title":"Ecto.Changeset.cast/4","ref":"Ecto.Changeset.html#cast/4"}]}`
+
+func TestLooksLikeSymbol(t *testing.T) {
+	for _, s := range []string{"Ecto.Changeset", "Ecto.Changeset.cast/4", "ActiveRecord::Base#find", "Rack::Request#get?"} {
+		assert.True(t, LooksLikeSymbol(s), s)
+	}
+	for _, s := range []string{"ecto", "Ecto", "phoenix_live_view", "golang.org/x/net", "cast changeset"} {
+		assert.False(t, LooksLikeSymbol(s), s)
+	}
+}
+
+func TestNamespaceDepNames(t *testing.T) {
+	assert.Equal(t, []string{
+		"phoenix_live_view", "phoenixliveview", "phoenix-live-view", "phoenix",
+	}, namespaceDepNames("Phoenix.LiveView.mount/3"))
+	assert.Equal(t, []string{
+		"active_record_base", "activerecordbase", "active-record-base",
+		"active_record", "activerecord", "active-record",
+	}, namespaceDepNames("ActiveRecord::Base#find"))
+	assert.Equal(t, "http_client", snakeCase("HTTPClient"))
+}
+
+func TestFindSymbol_FetchesNamespaceDependency(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	writeFiles(t, docPath, map[string]string{"dist/search_data-1A2B.js": exDocSearchData})
+
+	cmdMock := &CommandRunnerMock{}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto", "3.11.0").
+		Return([]byte("Docs fetched: "+docPath+"\n"), nil).
+		Once()
+
+	deps := []parser.Dependency{
+		{Name: "phoenix", Version: "1.7.14", Type: "elixir"},
+		{Name: "ecto", Version: "3.11.0", Type: "elixir"},
+	}
+
+	matches, err := findSymbolWithFuncs(deps, "Ecto.Changeset.cast/4", cmdMock.Run)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "ecto", matches[0].Dep.Name)
+	assert.Equal(t, "Ecto.Changeset.cast/4", matches[0].Name)
+	assert.Equal(t, "file://"+docPath+"/Ecto.Changeset.html#cast/4", matches[0].URL)
+
+	// Without an arity every clause is a candidate
+	matches, err = findSymbolWithFuncs(deps, "Ecto.Changeset.cast", cmdMock.Run)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	cmdMock.AssertExpectations(t)
+}
+
+func TestFindSymbol_SearchesCachedDocs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	writeFiles(t, docPath, map[string]string{"dist/search_data-1A2B.js": exDocSearchData})
+	store, err := cache.OpenDefault()
+	require.NoError(t, err)
+	require.NoError(t, store.Put(cache.Entry{
+		Ecosystem: "elixir",
+		Name:      "ecto_sql",
+		Version:   "3.11.0",
+		Path:      docPath,
+		Source:    "mix hex.docs",
+	}))
+
+	cmdMock := &CommandRunnerMock{}
+	deps := []parser.Dependency{
+		{Name: "ecto_sql", Version: "3.11.0", Type: "elixir"},
+		{Name: "jason", Version: "1.4.0", Type: "elixir"},
+	}
+
+	matches, err := findSymbolWithFuncs(deps, "Ecto.Changeset", cmdMock.Run)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "ecto_sql", matches[0].Dep.Name)
+	assert.Equal(t, "file://"+docPath+"/Ecto.Changeset.html", matches[0].URL)
+
+	assert.Len(t, cmdMock.Calls, 0, "expected only cached docs to be searched")
+}
+
+func TestResolveURL_Symbol(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	writeFiles(t, docPath, map[string]string{"dist/search_data-1A2B.js": exDocSearchData})
+
+	cmdMock := &CommandRunnerMock{}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto", "3.11.0").
+		Return([]byte("Docs fetched: "+docPath+"\n"), nil).
+		Once()
+
+	dep := &parser.Dependency{Name: "ecto", Version: "3.11.0", Type: "elixir"}
+	url, err := resolveURLWithFuncs(dep, "Ecto.Changeset.cast", cmdMock.Run, printWarning)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+docPath+"/Ecto.Changeset.html#cast/3", url)
+
+	// Keywords that aren't a known symbol still search
+	url, err = resolveURLWithFuncs(dep, "Ecto.Query", cmdMock.Run, printWarning)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+docPath+"/search.html?q=Ecto.Query", url)
+
+	cmdMock.AssertExpectations(t)
+}
@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"sync"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// SyncStatus is the outcome of syncing docs for a single dependency
+type SyncStatus int
+
+const (
+	SyncFetched SyncStatus = iota // docs were fetched or generated
+	SyncCached                    // docs were already cached
+	SyncFailed                    // fetching docs failed, see Err
+)
+
+func (s SyncStatus) String() string {
+	switch s {
+	case SyncFetched:
+		return "fetched"
+	case SyncCached:
+		return "cached"
+	default:
+		return "failed"
+	}
+}
+
+// SyncResult reports what happened to one dependency during a sync
+type SyncResult struct {
+	Dep    *parser.Dependency
+	Status SyncStatus
+	Entry  *cache.Entry
+	Err    error
+}
+
+// Sync makes sure docs are available locally for every dependency, using at
+// most workers concurrent fetches. Dependencies that are already cached are
+// skipped. progress, when non-nil, is called once per dependency as it
+// finishes, never concurrently. Results are returned in the order of deps.
+func Sync(deps []parser.Dependency, workers int, progress func(SyncResult)) []SyncResult {
+	return syncWithFuncs(deps, workers, progress, defaultCommandRunner)
+}
+
+// syncWithFuncs allows dependency injection for testing
+func syncWithFuncs(deps []parser.Dependency, workers int, progress func(SyncResult), cmdRunner CommandRunner) []SyncResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]SyncResult, len(deps))
+	jobs := make(chan int)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(deps)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = syncOne(&deps[i], cmdRunner)
+				done <- i
+			}
+		}()
+	}
+
+	go func() {
+		for i := range deps {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	for i := range done {
+		if progress != nil {
+			progress(results[i])
+		}
+	}
+
+	return results
+}
+
+func syncOne(dep *parser.Dependency, cmdRunner CommandRunner) SyncResult {
+	if entry, ok := Cached(dep); ok {
+		return SyncResult{Dep: dep, Status: SyncCached, Entry: entry}
+	}
+
+	entry, err := fetchWithFuncs(dep, cmdRunner)
+	if err != nil {
+		return SyncResult{Dep: dep, Status: SyncFailed, Err: err}
+	}
+	return SyncResult{Dep: dep, Status: SyncFetched, Entry: entry}
+}
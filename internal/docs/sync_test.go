@@ -0,0 +1,83 @@
+package docs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func TestSync_FetchesMissingAndSkipsCached(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cachedPath := t.TempDir()
+	fetchedPath := t.TempDir()
+	store, err := cache.OpenDefault()
+	require.NoError(t, err)
+	require.NoError(t, store.Put(cache.Entry{
+		Ecosystem: "elixir",
+		Name:      "phoenix",
+		Version:   "1.7.0",
+		Path:      cachedPath,
+		Source:    "mix hex.docs",
+	}))
+
+	deps := []parser.Dependency{
+		{Name: "phoenix", Version: "1.7.0", Type: "elixir"},
+		{Name: "ecto", Version: "3.11.0", Type: "elixir"},
+		{Name: "broken", Version: "0.1.0", Type: "elixir"},
+	}
+
+	cmdMock := &CommandRunnerMock{}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto", "3.11.0").
+		Return([]byte("Docs fetched: "+fetchedPath+"\n"), nil).
+		Once()
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "broken", "0.1.0").
+		Return(nil, errors.New("no docs published")).
+		Once()
+
+	var reported []string
+	results := syncWithFuncs(deps, 2, func(result SyncResult) {
+		reported = append(reported, result.Dep.Name)
+	}, cmdMock.Run)
+
+	require.Len(t, results, 3)
+	assert.ElementsMatch(t, []string{"phoenix", "ecto", "broken"}, reported)
+
+	assert.Equal(t, SyncCached, results[0].Status)
+	assert.Equal(t, cachedPath, results[0].Entry.Path)
+
+	assert.Equal(t, SyncFetched, results[1].Status)
+	assert.Equal(t, fetchedPath, results[1].Entry.Path)
+
+	assert.Equal(t, SyncFailed, results[2].Status)
+	assert.ErrorContains(t, results[2].Err, "no docs published")
+
+	cmdMock.AssertExpectations(t)
+}
+
+func TestSync_IsIdempotent(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	docPath := t.TempDir()
+	deps := []parser.Dependency{{Name: "ecto", Version: "3.11.0", Type: "elixir"}}
+
+	cmdMock := &CommandRunnerMock{}
+	cmdMock.
+		On("Run", "mix", "hex.docs", "fetch", "ecto", "3.11.0").
+		Return([]byte("Docs fetched: "+docPath+"\n"), nil).
+		Once()
+
+	first := syncWithFuncs(deps, 4, nil, cmdMock.Run)
+	second := syncWithFuncs(deps, 4, nil, cmdMock.Run)
+
+	assert.Equal(t, SyncFetched, first[0].Status)
+	assert.Equal(t, SyncCached, second[0].Status)
+	cmdMock.AssertExpectations(t)
+}
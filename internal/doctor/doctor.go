@@ -0,0 +1,331 @@
+package doctor
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/browser"
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	OK      Status = iota // nothing to do
+	Warning               // some features won't work, see Fix
+	Failed                // pudding can't work in this project, see Fix
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warning:
+		return "warning"
+	default:
+		return "failed"
+	}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name   string // what was checked, e.g. "bundle" or "cache"
+	Status Status
+	Detail string // what was found
+	Fix    string // how to fix a warning or failure
+}
+
+// Env is how checks look at the system, replaced in tests
+type Env struct {
+	Dir      string // where pd runs
+	Config   func() (*config.Config, error)
+	LookPath func(file string) (string, error)
+	Run      func(dir, name string, args ...string) ([]byte, error)
+	Reach    func(url string) error // whether url answers over HTTP
+	Browser  func() (string, error) // the command docs open with
+	CacheDir func() (string, error)
+	// Plugins are the errors of provider plugins on PATH that failed to load
+	Plugins []error
+}
+
+// DefaultEnv looks at the real system from dir
+func DefaultEnv(dir string) Env {
+	return Env{
+		Dir:      dir,
+		Config:   config.Current,
+		LookPath: exec.LookPath,
+		Run:      run,
+		Reach:    reach,
+		Browser: func() (string, error) {
+			launcher, err := browser.Default()
+			if err != nil {
+				return "", err
+			}
+			return launcher.Command()
+		},
+		CacheDir: cache.DefaultDir,
+	}
+}
+
+func run(dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+func reach(url string) error {
+	resp, err := httpClient.Head(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// tool is a program pudding runs for one kind of project
+type tool struct {
+	name        string
+	projectType parser.ProjectType
+	need        need
+	fix         string
+}
+
+// need is when a missing tool is critical
+type need int
+
+const (
+	withoutLockfile need = iota // when the project has no lockfile, as pd reads the lockfile itself
+	always                      // whenever the project is of its type
+)
+
+var tools = []tool{
+	{"mix", parser.ProjectTypeElixir, withoutLockfile, "Install Elixir, which includes mix: https://elixir-lang.org/install.html"},
+	// Gem docs are generated by rdoc from the gems `gem env home` finds, so
+	// unlike mix, these are needed even with a lockfile
+	{"ruby", parser.ProjectTypeRuby, always, "Install Ruby: https://www.ruby-lang.org/en/documentation/installation/"},
+	{"gem", parser.ProjectTypeRuby, always, "Install RubyGems, which ships with Ruby"},
+	{"bundle", parser.ProjectTypeRuby, withoutLockfile, "Install Bundler with `gem install bundler`"},
+	{"rdoc", parser.ProjectTypeRuby, always, "Install RDoc with `gem install rdoc`"},
+	{"go", parser.ProjectTypeGo, always, "Install Go: https://go.dev/doc/install"},
+	{"cargo", parser.ProjectTypeRust, always, "Install Rust with rustup: https://rustup.rs"},
+}
+
+// installs are how to install the dependencies of the ecosystems whose docs
+// are read from the installed packages
+var installs = map[parser.ProjectType]string{
+	parser.ProjectTypeNode:   "Install the project's packages with `npm install`, or your package manager's equivalent",
+	parser.ProjectTypePython: "Create a virtualenv in .venv, or activate yours, and install the project's packages into it",
+}
+
+// Check runs every check that applies to the project around env.Dir, for each
+// registered ecosystem it mixes. Outside a project the tools of every
+// ecosystem are checked, but none is critical.
+func Check(env Env) []Result {
+	results := []Result{}
+
+	cfg, configErr := env.Config()
+	if configErr != nil {
+		results = append(results, Result{"config", Failed, configErr.Error(), "Fix the setting in the file named above, or remove it with `pd config set <key> \"\"`"})
+		cfg = &config.Config{}
+	} else {
+		results = append(results, Result{Name: "config", Status: OK, Detail: "loaded"})
+		for _, err := range cfg.Warnings() {
+			results = append(results, Result{"config", Warning, err.Error(), "Move the setting to the global config with `pd config set`"})
+		}
+	}
+
+	root, projectTypes, err := parser.DetectProject(env.Dir)
+	inProject := err == nil
+	if !inProject {
+		results = append(results, Result{"project", Warning, err.Error(), "Run pd doctor inside your project to check what it needs"})
+	} else {
+		names := []string{}
+		for _, projectType := range projectTypes {
+			names = append(names, string(projectType))
+		}
+		results = append(results, Result{Name: "project", Status: OK, Detail: fmt.Sprintf("%s project in %s", strings.Join(names, ", "), root)})
+	}
+
+	ecosystems := []parser.Ecosystem{}
+	for _, e := range parser.Ecosystems() {
+		if !inProject || slices.Contains(projectTypes, e.ProjectType) {
+			ecosystems = append(ecosystems, e)
+		}
+	}
+	for _, e := range ecosystems {
+		locked := !inProject || hasLockfile(root, e)
+		for _, t := range tools {
+			if t.projectType == e.ProjectType {
+				results = append(results, checkTool(env, t, inProject, locked))
+			}
+		}
+	}
+	for _, err := range env.Plugins {
+		results = append(results, Result{"plugins", Warning, err.Error(), "Fix or remove the plugin; its ecosystem isn't available until it loads"})
+	}
+	if inProject {
+		for _, e := range ecosystems {
+			results = append(results, checkDependencies(root, e))
+			switch e.ProjectType {
+			case parser.ProjectTypeElixir:
+				results = appendCommandCheck(results, env, root, "hex", "Install Hex with `mix local.hex` to build docs locally; until then they open on hexdocs.pm", "mix", "hex.info")
+			case parser.ProjectTypeRuby:
+				results = appendCommandCheck(results, env, root, "bundle check", "Install the project's gems with `bundle install` to build their docs locally", "bundle", "check")
+			}
+		}
+	}
+
+	// The browser and cache directory come from the config, so they'd only
+	// repeat its error
+	if configErr == nil {
+		results = append(results, checkBrowser(env), checkCache(env))
+	}
+
+	if !inProject || slices.Contains(projectTypes, parser.ProjectTypeElixir) {
+		results = append(results, checkReach(env, "hex.pm", cfg.Mirrors.Hex, "https://hex.pm", "mirrors.hex"))
+	}
+	if !inProject || slices.Contains(projectTypes, parser.ProjectTypeRuby) {
+		results = append(results, checkReach(env, "rubygems.org", cfg.Mirrors.RubyGems, "https://rubygems.org", "mirrors.rubygems"))
+	}
+	return results
+}
+
+// Critical reports whether any check failed
+func Critical(results []Result) bool {
+	for _, result := range results {
+		if result.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTool looks a tool up, where locked is whether the project has a
+// lockfile pd can read without it
+func checkTool(env Env, t tool, inProject, locked bool) Result {
+	path, err := env.LookPath(t.name)
+	if err == nil {
+		return Result{Name: t.name, Status: OK, Detail: path}
+	}
+	status := Warning
+	if inProject && (t.need == always || t.need == withoutLockfile && !locked) {
+		status = Failed
+	}
+	return Result{t.name, status, "not found in $PATH", t.fix}
+}
+
+// hasLockfile reports whether the project at root has one of the ecosystem's
+// lockfiles, or the ecosystem has none
+func hasLockfile(root string, e parser.Ecosystem) bool {
+	for _, lockfile := range e.Lockfiles {
+		if _, err := os.Stat(filepath.Join(root, lockfile)); err == nil {
+			return true
+		}
+	}
+	return len(e.Lockfiles) == 0
+}
+
+// checkDependencies reads the project's dependencies the way every command
+// does, and for the ecosystems documented from installed packages makes sure
+// they're installed
+func checkDependencies(root string, e parser.Ecosystem) Result {
+	name := e.DepType + " dependencies"
+	deps, err := e.Parser.Parse(root)
+	if err != nil {
+		fix := "Install the project's dependencies"
+		if len(e.Lockfiles) > 0 {
+			fix += ", which writes " + strings.Join(e.Lockfiles, " or ")
+		}
+		return Result{name, Failed, err.Error(), fix}
+	}
+
+	if fix, ok := installs[e.ProjectType]; ok {
+		missing := 0
+		for _, dep := range deps {
+			if _, err := os.Stat(dep.Dir); dep.Dir == "" || err != nil {
+				missing++
+			}
+		}
+		if missing > 0 {
+			return Result{name, Warning, fmt.Sprintf("%d of %d aren't installed, so their docs can't be built", missing, len(deps)), fix}
+		}
+	}
+	return Result{Name: name, Status: OK, Detail: fmt.Sprintf("%d found", len(deps))}
+}
+
+// appendCommandCheck runs a command in the project, which warns when it
+// reports a problem. It's skipped when the program is missing, which its
+// own check already reports.
+func appendCommandCheck(results []Result, env Env, dir, name, fix string, command ...string) []Result {
+	if _, err := env.LookPath(command[0]); err != nil {
+		return results
+	}
+	output, err := env.Run(dir, command[0], command[1:]...)
+	if err != nil {
+		detail := fmt.Sprintf("`%s` failed: %v", strings.Join(command, " "), err)
+		if line := firstLine(output); line != "" {
+			detail += ": " + line
+		}
+		return append(results, Result{name, Warning, detail, fix})
+	}
+	return append(results, Result{Name: name, Status: OK, Detail: firstLine(output)})
+}
+
+func checkBrowser(env Env) Result {
+	command, err := env.Browser()
+	if err != nil {
+		return Result{"browser", Failed, err.Error(), "Install xdg-utils, set $BROWSER or `pd config set browser <command>`, or pass --browser print to print URLs instead"}
+	}
+	if command == browser.PrintOnly {
+		return Result{Name: "browser", Status: OK, Detail: "URLs are printed instead of opened"}
+	}
+	return Result{Name: "browser", Status: OK, Detail: command}
+}
+
+// checkCache makes sure docs can be written to the cache directory
+func checkCache(env Env) Result {
+	dir, err := env.CacheDir()
+	if err != nil {
+		return Result{"cache", Failed, err.Error(), "Set XDG_CACHE_HOME or `pd config set cache_dir <dir>`"}
+	}
+	fix := fmt.Sprintf("Make %s writable, or use another directory with `pd config set cache_dir <dir>`", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Result{"cache", Failed, err.Error(), fix}
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return Result{"cache", Failed, fmt.Sprintf("%s is not writable: %v", dir, err), fix}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return Result{Name: "cache", Status: OK, Detail: filepath.Clean(dir)}
+}
+
+// checkReach makes sure a registry, or the mirror replacing it, answers.
+// Cached docs still open offline, so it's never critical.
+func checkReach(env Env, name, mirror, url, mirrorKey string) Result {
+	if mirror != "" {
+		url = mirror
+	}
+	if err := env.Reach(url); err != nil {
+		return Result{name, Warning, fmt.Sprintf("%s is unreachable: %v", url, err),
+			fmt.Sprintf("Check your network or proxy settings, or point %s at a reachable mirror; cached docs still open offline", mirrorKey)}
+	}
+	return Result{Name: name, Status: OK, Detail: url + " is reachable"}
+}
+
+func firstLine(output []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,281 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/config"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// fakeEnv is a system where the installed programs are on the path, every
+// command succeeds and every URL is reachable
+func fakeEnv(t *testing.T, dir string, installed ...string) Env {
+	t.Helper()
+	return Env{
+		Dir:    dir,
+		Config: func() (*config.Config, error) { return &config.Config{}, nil },
+		LookPath: func(file string) (string, error) {
+			if slices.Contains(installed, file) {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("executable file not found in $PATH")
+		},
+		Run:      func(dir, name string, args ...string) ([]byte, error) { return []byte("ok\n"), nil },
+		Reach:    func(url string) error { return nil },
+		Browser:  func() (string, error) { return "/usr/bin/xdg-open", nil },
+		CacheDir: func() (string, error) { return filepath.Join(t.TempDir(), "pudding"), nil },
+	}
+}
+
+// emptyFiles are the contents of the files that must parse, empty otherwise
+var emptyFiles = map[string]string{"mix.lock": "%{}\n", "package.json": "{}\n"}
+
+// writeProject writes empty files, such as a manifest and its lockfile, to
+// a project directory
+func writeProject(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(emptyFiles[file]), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+	return dir
+}
+
+func find(t *testing.T, results []Result, name string) Result {
+	t.Helper()
+	for _, result := range results {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("No %s check in %v", name, results)
+	return Result{}
+}
+
+func names(results []Result) []string {
+	result := []string{}
+	for _, r := range results {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func TestCheck_RubyProject(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "Gemfile", "Gemfile.lock"), "ruby", "gem", "bundle", "rdoc")
+
+	results := Check(env)
+	want := []string{"config", "project", "ruby", "gem", "bundle", "rdoc", "gem dependencies", "bundle check", "browser", "cache", "rubygems.org"}
+	if got := names(results); !slices.Equal(got, want) {
+		t.Errorf("Checks = %v, want %v", got, want)
+	}
+	if Critical(results) {
+		t.Errorf("Expected no critical problems, got %v", results)
+	}
+}
+
+func TestCheck_MixedProject(t *testing.T) {
+	dir := writeProject(t, "mix.exs", "mix.lock", "Gemfile", "Gemfile.lock")
+	env := fakeEnv(t, dir, "mix", "ruby", "gem", "bundle", "rdoc")
+
+	results := Check(env)
+	want := []string{"config", "project", "mix", "ruby", "gem", "bundle", "rdoc", "elixir dependencies", "hex", "gem dependencies", "bundle check", "browser", "cache", "hex.pm", "rubygems.org"}
+	if got := names(results); !slices.Equal(got, want) {
+		t.Errorf("Checks = %v, want %v", got, want)
+	}
+	if project := find(t, results, "project"); !strings.Contains(project.Detail, "elixir, ruby project") {
+		t.Errorf("Unexpected project result %+v", project)
+	}
+}
+
+func TestCheck_MissingToolIsCritical(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "Gemfile", "Gemfile.lock"), "ruby", "gem")
+
+	// Gem docs need rdoc even though the lockfile is read without bundle
+	results := Check(env)
+	if !Critical(results) {
+		t.Error("Expected missing rdoc to be critical with a lockfile")
+	}
+	rdoc := find(t, results, "rdoc")
+	if rdoc.Status != Failed || !strings.Contains(rdoc.Fix, "gem install rdoc") {
+		t.Errorf("Unexpected rdoc result %+v", rdoc)
+	}
+	if bundle := find(t, results, "bundle"); bundle.Status != Warning {
+		t.Errorf("Expected missing bundle to be a warning with a lockfile, got %+v", bundle)
+	}
+
+	env = fakeEnv(t, writeProject(t, "go.mod"))
+	if g := find(t, Check(env), "go"); g.Status != Failed {
+		t.Errorf("Expected missing go to be critical, got %+v", g)
+	}
+}
+
+func TestCheck_LockfileReadWithoutTools(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "mix.exs", "mix.lock", "Gemfile", "Gemfile.lock"), "ruby", "gem", "rdoc")
+
+	results := Check(env)
+	for _, name := range []string{"mix", "bundle"} {
+		if r := find(t, results, name); r.Status != Warning {
+			t.Errorf("Expected missing %s to be a warning with a lockfile, got %+v", name, r)
+		}
+	}
+	if Critical(results) {
+		t.Errorf("Expected no critical problems, got %v", results)
+	}
+}
+
+func TestCheck_InstalledPackages(t *testing.T) {
+	dir := writeProject(t, "package.json")
+	lock := `{"lockfileVersion": 3, "packages": {"": {"dependencies": {"left-pad": "^1.3.0"}}, "node_modules/left-pad": {"version": "1.3.0"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := fakeEnv(t, dir)
+
+	npm := find(t, Check(env), "npm dependencies")
+	if npm.Status != Warning || !strings.Contains(npm.Detail, "1 of 1 aren't installed") || !strings.Contains(npm.Fix, "npm install") {
+		t.Errorf("Unexpected npm result %+v", npm)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules", "left-pad"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if npm := find(t, Check(env), "npm dependencies"); npm.Status != OK || npm.Detail != "1 found" {
+		t.Errorf("Unexpected npm result %+v", npm)
+	}
+}
+
+// stubParser stands in for the parser of a provider plugin
+type stubParser struct{ err error }
+
+func (stubParser) CanParse(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "dune-project"))
+	return err == nil
+}
+
+func (p stubParser) Parse(string) ([]parser.Dependency, error) {
+	return nil, p.err
+}
+
+func TestCheck_RegisteredEcosystem(t *testing.T) {
+	err := parser.Register(parser.Ecosystem{ProjectType: "ocaml", DepType: "opam", Manifests: []string{"dune-project"}, Lockfiles: []string{"app.opam.locked"},
+		Parser: stubParser{errors.New("no app.opam.locked, run `opam lock`")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := fakeEnv(t, writeProject(t, "dune-project"))
+
+	results := Check(env)
+	opam := find(t, results, "opam dependencies")
+	if opam.Status != Failed || !strings.Contains(opam.Detail, "opam lock") || !strings.Contains(opam.Fix, "app.opam.locked") {
+		t.Errorf("Unexpected opam result %+v", opam)
+	}
+}
+
+func TestCheck_ElixirWithoutHex(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "mix.exs"), "mix")
+	env.Run = func(dir, name string, args ...string) ([]byte, error) {
+		return []byte("** (Mix) The task \"hex.info\" could not be found\n"), errors.New("exit status 1")
+	}
+
+	results := Check(env)
+	hex := find(t, results, "hex")
+	if hex.Status != Warning || !strings.Contains(hex.Detail, "could not be found") || !strings.Contains(hex.Fix, "mix local.hex") {
+		t.Errorf("Unexpected hex result %+v", hex)
+	}
+	if slices.Contains(names(results), "rubygems.org") {
+		t.Error("Expected rubygems.org not to be checked for an Elixir project")
+	}
+}
+
+func TestCheck_OutsideProject(t *testing.T) {
+	env := fakeEnv(t, t.TempDir())
+
+	results := Check(env)
+	if Critical(results) {
+		t.Errorf("Expected missing tools to be warnings outside a project, got %v", results)
+	}
+	if project := find(t, results, "project"); project.Status != Warning {
+		t.Errorf("Expected a project warning, got %+v", project)
+	}
+	for _, name := range []string{"mix", "bundle", "hex.pm", "rubygems.org"} {
+		find(t, results, name)
+	}
+}
+
+func TestCheck_BrowserAndCache(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "go.mod"), "go")
+	env.Browser = func() (string, error) { return "", errors.New("no browser launcher found") }
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	env.CacheDir = func() (string, error) { return filepath.Join(blocker, "pudding"), nil }
+
+	results := Check(env)
+	if b := find(t, results, "browser"); b.Status != Failed || !strings.Contains(b.Fix, "--browser print") {
+		t.Errorf("Unexpected browser result %+v", b)
+	}
+	if c := find(t, results, "cache"); c.Status != Failed || !strings.Contains(c.Fix, "cache_dir") {
+		t.Errorf("Unexpected cache result %+v", c)
+	}
+}
+
+func TestCheck_UnreachableMirror(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "mix.exs", "mix.lock"), "mix")
+	env.Config = func() (*config.Config, error) {
+		return &config.Config{Mirrors: config.Mirrors{Hex: "https://hex.example.com"}}, nil
+	}
+	env.Reach = func(url string) error {
+		if url == "https://hex.example.com" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	results := Check(env)
+	hex := find(t, results, "hex.pm")
+	if hex.Status != Warning || !strings.Contains(hex.Detail, "https://hex.example.com") {
+		t.Errorf("Unexpected hex.pm result %+v", hex)
+	}
+	if Critical(results) {
+		t.Error("Expected an unreachable registry not to be critical")
+	}
+}
+
+func TestCheck_BrokenConfig(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "Gemfile"))
+	env.Config = func() (*config.Config, error) { return nil, errors.New(`unknown config key "bogus"`) }
+
+	results := Check(env)
+	if c := find(t, results, "config"); c.Status != Failed {
+		t.Errorf("Expected the config check to fail, got %+v", c)
+	}
+	got := names(results)
+	for _, name := range []string{"browser", "cache", "bundle check"} {
+		if slices.Contains(got, name) {
+			t.Errorf("Expected no %s check, got %v", name, got)
+		}
+	}
+	find(t, results, "rubygems.org")
+}
+
+func TestCheck_BrokenPlugin(t *testing.T) {
+	env := fakeEnv(t, writeProject(t, "go.mod"), "go")
+	env.Plugins = []error{errors.New("failed to load the provider plugin /usr/bin/pudding-provider-pub: pub describe: boom")}
+
+	results := Check(env)
+	c := find(t, results, "plugins")
+	if c.Status != Warning || !strings.Contains(c.Detail, "pudding-provider-pub") {
+		t.Errorf("Expected a warning naming the plugin, got %+v", c)
+	}
+	if Critical(results) {
+		t.Error("Expected a broken plugin not to be critical")
+	}
+}
@@ -0,0 +1,215 @@
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Prefix names the executables on PATH that are provider plugins, e.g.
+// pudding-provider-pub for Dart packages
+const Prefix = "pudding-provider-"
+
+// ProtocolVersion is the version of the protocol this pudding speaks. A
+// plugin says which version it speaks when described.
+const ProtocolVersion = 1
+
+// Methods of the protocol. Every run of a plugin answers one request read
+// from stdin with one response written to stdout, both JSON.
+const (
+	MethodDescribe     = "describe"     // → Description
+	MethodDependencies = "dependencies" // dir → dependencies
+	MethodFetch        = "fetch"        // dependency → path of a directory holding index.html
+	MethodURL          = "url"          // dependency, keywords → url of hosted docs
+)
+
+// Request is what pudding asks a plugin
+type Request struct {
+	Method     string      `json:"method"`
+	Dir        string      `json:"dir,omitempty"`
+	Dependency *Dependency `json:"dependency,omitempty"`
+	Keywords   string      `json:"keywords,omitempty"`
+}
+
+// Description is the ecosystem a plugin teaches pudding
+type Description struct {
+	Protocol       int      `json:"protocol"`
+	ProjectType    string   `json:"project_type"`
+	DependencyType string   `json:"dependency_type"`
+	Label          string   `json:"label,omitempty"` // what the docs are called, e.g. "dartdoc"
+	Manifests      []string `json:"manifests"`       // a directory holding one is a project of the ecosystem
+	Lockfiles      []string `json:"lockfiles,omitempty"`
+}
+
+// Response is a plugin's answer, with the fields of the method asked for
+// set, or Error when it failed
+type Response struct {
+	Error string `json:"error,omitempty"`
+	Description
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+	Path         string       `json:"path,omitempty"`
+	URL          string       `json:"url,omitempty"`
+}
+
+// Dependency is a parser.Dependency on the wire, without its type which is
+// always the plugin's
+type Dependency struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Source      string `json:"source,omitempty"`
+	Dir         string `json:"dir,omitempty"`
+	Transitive  bool   `json:"transitive,omitempty"`
+	ProjectRoot string `json:"project_root,omitempty"`
+}
+
+// Plugin is a provider executable and the ecosystem it described
+type Plugin struct {
+	Name string // e.g. "pub" for pudding-provider-pub
+	Path string
+	Description
+}
+
+// Discover returns the provider plugins in a PATH list. Like commands, a
+// plugin shadows the ones of the same name later in the list.
+func Discover(pathList string) []string {
+	seen := map[string]bool{}
+	plugins := []string{}
+	for _, dir := range filepath.SplitList(pathList) {
+		matches, _ := filepath.Glob(filepath.Join(dir, Prefix+"*"))
+		sort.Strings(matches)
+		for _, path := range matches {
+			name := filepath.Base(path)
+			if seen[name] || !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, path)
+		}
+	}
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
+
+// Load asks the plugin at path to describe its ecosystem
+func Load(path string) (*Plugin, error) {
+	p := &Plugin{Name: strings.TrimPrefix(filepath.Base(path), Prefix), Path: path}
+	resp, err := p.call(Request{Method: MethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Protocol != ProtocolVersion {
+		return nil, fmt.Errorf("%s speaks protocol version %d, expected %d", p.Path, resp.Protocol, ProtocolVersion)
+	}
+	if resp.ProjectType == "" || resp.DependencyType == "" {
+		return nil, fmt.Errorf("%s described no project and dependency type", p.Path)
+	}
+	if len(resp.Manifests) == 0 {
+		return nil, fmt.Errorf("%s described no manifests to detect its projects by", p.Path)
+	}
+	p.Description = resp.Description
+	return p, nil
+}
+
+// Dependencies lists the dependencies of the project in dir
+func (p *Plugin) Dependencies(dir string) ([]parser.Dependency, error) {
+	resp, err := p.call(Request{Method: MethodDependencies, Dir: dir})
+	if err != nil {
+		return nil, err
+	}
+	deps := []parser.Dependency{}
+	for _, dep := range resp.Dependencies {
+		if dep.Name == "" {
+			return nil, fmt.Errorf("%s listed a dependency without a name in %s", p.Name, dir)
+		}
+		if dep.ProjectRoot == "" {
+			dep.ProjectRoot = dir
+		}
+		deps = append(deps, parser.Dependency{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			Type:        p.DependencyType,
+			Source:      dep.Source,
+			Dir:         dep.Dir,
+			Transitive:  dep.Transitive,
+			ProjectRoot: dep.ProjectRoot,
+		})
+	}
+	return deps, nil
+}
+
+// Fetch has the plugin produce the docs of a dependency and returns their
+// directory
+func (p *Plugin) Fetch(dep *parser.Dependency) (string, error) {
+	resp, err := p.call(Request{Method: MethodFetch, Dependency: wireDependency(dep)})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch docs for %s: %w", dep.Name, err)
+	}
+	if info, err := os.Stat(resp.Path); resp.Path == "" || err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s returned no docs directory for %s, got %q", p.Name, dep.Name, resp.Path)
+	}
+	return resp.Path, nil
+}
+
+// URL returns the hosted docs of a dependency, searched for keywords where
+// the plugin supports it
+func (p *Plugin) URL(dep *parser.Dependency, keywords string) (string, error) {
+	resp, err := p.call(Request{Method: MethodURL, Dependency: wireDependency(dep), Keywords: keywords})
+	if err != nil {
+		return "", fmt.Errorf("failed to find online docs for %s: %w", dep.Name, err)
+	}
+	if resp.URL == "" {
+		return "", fmt.Errorf("%s returned no online docs for %s", p.Name, dep.Name)
+	}
+	return resp.URL, nil
+}
+
+func wireDependency(dep *parser.Dependency) *Dependency {
+	return &Dependency{
+		Name:        dep.Name,
+		Version:     dep.Version,
+		Source:      dep.Source,
+		Dir:         dep.Dir,
+		Transitive:  dep.Transitive,
+		ProjectRoot: dep.ProjectRoot,
+	}
+}
+
+// call runs the plugin once with req on stdin and decodes its response
+func (p *Plugin) call(req Request) (*Response, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the %s request: %w", req.Method, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	runErr := cmd.Run()
+
+	resp := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("%s %s failed: %w: %s", p.Name, req.Method, runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to decode the %s response of %s: %w", req.Method, p.Name, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s %s: %s", p.Name, req.Method, resp.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", p.Name, req.Method, runErr)
+	}
+	return resp, nil
+}
@@ -0,0 +1,207 @@
+package external
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// testPlugin is the harness driving a plugin through every method of the
+// protocol, with project a directory holding one of its projects
+func testPlugin(t *testing.T, path, project string) []parser.Dependency {
+	t.Helper()
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	if p.ProjectType == "" || p.DependencyType == "" {
+		t.Fatalf("describe = %+v, want a project and dependency type", p.Description)
+	}
+
+	found := false
+	for _, manifest := range p.Manifests {
+		if _, err := os.Stat(filepath.Join(project, manifest)); err == nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("%s holds none of the manifests %v", project, p.Manifests)
+	}
+
+	deps, err := p.Dependencies(project)
+	if err != nil {
+		t.Fatalf("dependencies failed: %v", err)
+	}
+	if len(deps) == 0 {
+		t.Fatalf("dependencies of %s is empty", project)
+	}
+	for _, dep := range deps {
+		if dep.Version == "" || dep.Type != p.DependencyType || dep.ProjectRoot == "" {
+			t.Errorf("dependency %+v needs a version, its type and project root", dep)
+		}
+	}
+
+	// Docs are a directory with a start page, and hosted ones a web page
+	docPath, err := p.Fetch(&deps[0])
+	if err != nil {
+		t.Fatalf("fetch of %s failed: %v", deps[0].Name, err)
+	}
+	if _, err := os.Stat(filepath.Join(docPath, "index.html")); err != nil {
+		t.Errorf("fetch of %s returned %s without an index.html", deps[0].Name, docPath)
+	}
+	if hosted, err := p.URL(&deps[0], "keyword"); err == nil && !strings.HasPrefix(hosted, "http") {
+		t.Errorf("url of %s = %s, want a web page", deps[0].Name, hosted)
+	}
+
+	missing := parser.Dependency{Name: "no-such-dependency", Version: "0.0.0", Type: p.DependencyType, Dir: t.TempDir()}
+	if _, err := p.Fetch(&missing); err == nil || !strings.Contains(err.Error(), missing.Name) {
+		t.Errorf("fetch of a missing dependency = %v, want an error naming it", err)
+	}
+	if _, err := p.call(Request{Method: "bogus"}); err == nil {
+		t.Error("Expected an unknown method to fail")
+	}
+	return deps
+}
+
+// TestPlugin runs the harness against your own plugin:
+//
+//	PUDDING_PLUGIN=$(which pudding-provider-foo) PUDDING_PLUGIN_PROJECT=path/to/project go test ./internal/external -run TestPlugin
+func TestPlugin(t *testing.T) {
+	path, project := os.Getenv("PUDDING_PLUGIN"), os.Getenv("PUDDING_PLUGIN_PROJECT")
+	if path == "" || project == "" {
+		t.Skip("set PUDDING_PLUGIN and PUDDING_PLUGIN_PROJECT to check a plugin")
+	}
+	testPlugin(t, path, project)
+}
+
+// buildReference builds the reference plugin into dir
+func buildReference(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, Prefix+"pub")
+	if output, err := exec.Command("go", "build", "-o", path, "../../examples/pudding-provider-pub").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build the reference plugin: %v\n%s", err, output)
+	}
+	return path
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReferencePlugin(t *testing.T) {
+	path := buildReference(t, t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	pubCache := t.TempDir()
+	t.Setenv("PUB_CACHE", pubCache)
+
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "pubspec.yaml"), "name: app\n")
+	writeFile(t, filepath.Join(project, "pubspec.lock"), `packages:
+  http:
+    dependency: "direct main"
+    description:
+      name: http
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.2.1"
+  meta:
+    dependency: transitive
+    description:
+      name: meta
+      url: "https://pub.dev"
+    source: hosted
+    version: "1.11.0"
+  shared:
+    dependency: "direct main"
+    description:
+      path: "../shared"
+      relative: true
+    source: path
+    version: "0.1.0"
+  flutter:
+    dependency: "direct main"
+    description: flutter
+    source: sdk
+    version: "0.0.0"
+`)
+	writeFile(t, filepath.Join(pubCache, "hosted", "pub.dev", "http-1.2.1", "README.md"), "A composable <Future>-based library.\n")
+
+	deps := testPlugin(t, path, project)
+
+	got := []string{}
+	for _, dep := range deps {
+		got = append(got, dep.Name+"@"+dep.Version)
+	}
+	if want := []string{"http@1.2.1", "meta@1.11.0", "shared@0.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dependencies = %v, want %v", got, want)
+	}
+	if !deps[1].Transitive || deps[0].Transitive {
+		t.Errorf("Expected only meta to be transitive, got %+v", deps)
+	}
+	if deps[2].Source != "path" || deps[2].Dir != filepath.Join(filepath.Dir(project), "shared") {
+		t.Errorf("Expected shared to be a path dependency, got %+v", deps[2])
+	}
+
+	p, _ := Load(path)
+	if hosted, err := p.URL(&deps[0], ""); err != nil || hosted != "https://pub.dev/documentation/http/1.2.1/" {
+		t.Errorf("url = %s, %v", hosted, err)
+	}
+	if _, err := p.URL(&deps[2], ""); err == nil {
+		t.Error("Expected no hosted docs for a path dependency")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(first, Prefix+"pub"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(second, Prefix+"pub"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(second, Prefix+"opam"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(second, "pudding"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(second, Prefix+"opam"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(second, Prefix+"zig"), "#!/bin/sh\n")
+
+	got := Discover(strings.Join([]string{first, second, filepath.Join(second, "missing")}, string(os.PathListSeparator)))
+	want := []string{filepath.Join(first, Prefix+"pub"), filepath.Join(second, Prefix+"zig")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover = %v, want %v", got, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"crash", "echo 'no such file' >&2; exit 2", "no such file"},
+		{"garbage", "echo 'not json'", "failed to decode the describe response"},
+		{"error", `echo '{"error":"broken"}'`, "broken"},
+		{"old protocol", `echo '{"protocol":0,"project_type":"dart","dependency_type":"pub"}'`, "protocol version 0"},
+		{"no types", `echo '{"protocol":1}'`, "no project and dependency type"},
+		{"no manifests", `echo '{"protocol":1,"project_type":"dart","dependency_type":"pub"}'`, "no manifests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), Prefix+"broken")
+			writeFile(t, path, "#!/bin/sh\ncat >/dev/null\n"+tt.script+"\n")
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
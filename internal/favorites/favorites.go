@@ -0,0 +1,196 @@
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Favorite is a dependency the user pinned, independent of its version
+type Favorite struct {
+	Ecosystem string    `json:"ecosystem"` // dependency type, e.g. "elixir", "gem"
+	Name      string    `json:"name"`
+	AddedAt   time.Time `json:"added_at"`
+	Global    bool      `json:"-"` // set by List for favorites shared by every project
+}
+
+// Key identifies a favorite within a scope
+func (f Favorite) Key() string {
+	return Key(f.Ecosystem, f.Name)
+}
+
+// Key builds the key for an ecosystem and package name
+func Key(ecosystem, name string) string {
+	return ecosystem + "/" + name
+}
+
+// favoritesFile is the on-disk layout, with per-project favorites keyed by project root
+type favoritesFile struct {
+	Global   []Favorite            `json:"global"`
+	Projects map[string][]Favorite `json:"projects"`
+}
+
+// Store persists favorites in a JSON file
+type Store struct {
+	path string
+}
+
+// fileMu serializes updates within the process
+var fileMu sync.Mutex
+
+// DefaultPath returns the favorites file, honouring $XDG_CONFIG_HOME before
+// the platform default
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "pudding", "favorites.json"), nil
+}
+
+// Open returns the store backed by the file at path
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// OpenDefault returns the store at DefaultPath
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// List returns the favorites of a project followed by the global ones.
+// A favorite saved in both scopes is listed once, as a project favorite.
+func (s *Store) List(projectRoot string) ([]Favorite, error) {
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	favorites := []Favorite{}
+	seen := map[string]bool{}
+	for _, fav := range file.Projects[projectRoot] {
+		seen[fav.Key()] = true
+		favorites = append(favorites, fav)
+	}
+	for _, fav := range file.Global {
+		if seen[fav.Key()] {
+			continue
+		}
+		fav.Global = true
+		favorites = append(favorites, fav)
+	}
+	return favorites, nil
+}
+
+// Add saves a favorite for a project, or for every project when global is
+// true. Adding an existing favorite is a no-op.
+func (s *Store) Add(projectRoot string, global bool, ecosystem, name string) error {
+	return s.update(func(file *favoritesFile) {
+		list := file.get(projectRoot, global)
+		for _, fav := range list {
+			if fav.Key() == Key(ecosystem, name) {
+				return
+			}
+		}
+		list = append(list, Favorite{Ecosystem: ecosystem, Name: name, AddedAt: time.Now().UTC()})
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+		file.set(projectRoot, global, list)
+	})
+}
+
+// Remove deletes a favorite from a project, or from the global favorites when
+// global is true, reporting whether it was there
+func (s *Store) Remove(projectRoot string, global bool, ecosystem, name string) (bool, error) {
+	removed := false
+	err := s.update(func(file *favoritesFile) {
+		kept := []Favorite{}
+		for _, fav := range file.get(projectRoot, global) {
+			if fav.Key() == Key(ecosystem, name) {
+				removed = true
+				continue
+			}
+			kept = append(kept, fav)
+		}
+		file.set(projectRoot, global, kept)
+	})
+	return removed, err
+}
+
+func (f *favoritesFile) get(projectRoot string, global bool) []Favorite {
+	if global {
+		return f.Global
+	}
+	return f.Projects[projectRoot]
+}
+
+func (f *favoritesFile) set(projectRoot string, global bool, list []Favorite) {
+	switch {
+	case global:
+		f.Global = list
+	case len(list) == 0:
+		delete(f.Projects, projectRoot)
+	default:
+		f.Projects[projectRoot] = list
+	}
+}
+
+func (s *Store) load() (*favoritesFile, error) {
+	file := &favoritesFile{Global: []Favorite{}, Projects: map[string][]Favorite{}}
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read favorites: %w", err)
+	}
+	if err := json.Unmarshal(content, file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if file.Projects == nil {
+		file.Projects = map[string][]Favorite{}
+	}
+	return file, nil
+}
+
+func (s *Store) update(fn func(*favoritesFile)) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(file)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode favorites: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write favorites: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write favorites: %w", err)
+	}
+	return nil
+}
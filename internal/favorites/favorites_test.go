@@ -0,0 +1,121 @@
+package favorites
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func names(favs []Favorite) []string {
+	result := []string{}
+	for _, fav := range favs {
+		name := fav.Name
+		if fav.Global {
+			name += " (global)"
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+func TestStore_ProjectFavorites(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "favorites.json"))
+
+	if err := store.Add("/src/app", false, "elixir", "phoenix"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add("/src/app", false, "elixir", "ecto"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	// Adding twice is a no-op
+	if err := store.Add("/src/app", false, "elixir", "ecto"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	favs, err := store.List("/src/app")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if got := names(favs); len(got) != 2 || got[0] != "ecto" || got[1] != "phoenix" {
+		t.Errorf("List() = %v, want [ecto phoenix]", got)
+	}
+
+	other, err := store.List("/src/other")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("Expected project favorites to stay in their project, got %v", names(other))
+	}
+}
+
+func TestStore_GlobalFavorites(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "favorites.json"))
+
+	if err := store.Add("", true, "gem", "rails"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add("/src/app", false, "gem", "rack"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	favs, err := store.List("/src/app")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if got := names(favs); len(got) != 2 || got[0] != "rack" || got[1] != "rails (global)" {
+		t.Errorf("List() = %v, want [rack rails (global)]", got)
+	}
+
+	favs, err = store.List("/src/other")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if got := names(favs); len(got) != 1 || got[0] != "rails (global)" {
+		t.Errorf("Expected global favorites in every project, got %v", got)
+	}
+}
+
+func TestStore_Remove(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "favorites.json"))
+
+	if err := store.Add("/src/app", false, "npm", "react"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	removed, err := store.Remove("/src/app", true, "npm", "react")
+	if err != nil || removed {
+		t.Errorf("Expected removing from the global scope to miss (removed=%v, err=%v)", removed, err)
+	}
+
+	removed, err = store.Remove("/src/app", false, "npm", "react")
+	if err != nil || !removed {
+		t.Errorf("Expected react to be removed (removed=%v, err=%v)", removed, err)
+	}
+
+	favs, err := store.List("/src/app")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(favs) != 0 {
+		t.Errorf("Expected no favorites left, got %v", names(favs))
+	}
+}
+
+func TestStore_SameNameInDifferentEcosystems(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "favorites.json"))
+
+	if err := store.Add("/src/app", false, "npm", "yaml"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add("/src/app", false, "pypi", "yaml"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	favs, err := store.List("/src/app")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(favs) != 2 {
+		t.Errorf("Expected favorites keyed by ecosystem and name, got %d", len(favs))
+	}
+}
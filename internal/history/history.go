@@ -0,0 +1,197 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Entry records one time docs were opened
+type Entry struct {
+	Ecosystem   string    `json:"ecosystem"` // dependency type, e.g. "elixir", "gem"
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Source      string    `json:"source,omitempty"`
+	Dir         string    `json:"dir,omitempty"`
+	ProjectRoot string    `json:"project_root"`
+	Keywords    string    `json:"keywords,omitempty"`
+	OpenedAt    time.Time `json:"opened_at"`
+}
+
+// NewEntry builds the history entry for opening docs for dep
+func NewEntry(dep *parser.Dependency, keywords string) Entry {
+	return Entry{
+		Ecosystem:   dep.Type,
+		Name:        dep.Name,
+		Version:     dep.Version,
+		Source:      dep.Source,
+		Dir:         dep.Dir,
+		ProjectRoot: dep.ProjectRoot,
+		Keywords:    keywords,
+		OpenedAt:    time.Now().UTC(),
+	}
+}
+
+// Dependency returns the dependency the entry was recorded for
+func (e Entry) Dependency() parser.Dependency {
+	return parser.Dependency{
+		Name:        e.Name,
+		Version:     e.Version,
+		Type:        e.Ecosystem,
+		Source:      e.Source,
+		Dir:         e.Dir,
+		ProjectRoot: e.ProjectRoot,
+	}
+}
+
+// sameLookup reports whether two entries opened the same page
+func (e Entry) sameLookup(other Entry) bool {
+	return e.Ecosystem == other.Ecosystem &&
+		e.Name == other.Name &&
+		e.Version == other.Version &&
+		e.ProjectRoot == other.ProjectRoot &&
+		e.Keywords == other.Keywords
+}
+
+// maxEntries bounds the history file, dropping the oldest entries
+const maxEntries = 500
+
+// Store persists history in a JSON file, newest entry first
+type Store struct {
+	path string
+}
+
+// fileMu serializes updates within the process
+var fileMu sync.Mutex
+
+// DefaultPath returns the history file, honouring $XDG_STATE_HOME before
+// falling back to ~/.local/state
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate state directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "pudding", "history.json"), nil
+}
+
+// Open returns the store backed by the file at path
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// OpenDefault returns the store at DefaultPath
+func OpenDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// List returns the history, most recently opened first
+func (s *Store) List() ([]Entry, error) {
+	return s.load()
+}
+
+// Last returns the most recently opened entry
+func (s *Store) Last() (*Entry, bool, error) {
+	entries, err := s.load()
+	if err != nil || len(entries) == 0 {
+		return nil, false, err
+	}
+	return &entries[0], true, nil
+}
+
+// Record adds an entry to the top of the history. Opening the same page
+// again moves it to the top instead of adding a duplicate.
+func (s *Store) Record(entry Entry) error {
+	return s.update(func(entries []Entry) []Entry {
+		updated := []Entry{entry}
+		for _, existing := range entries {
+			if !existing.sameLookup(entry) {
+				updated = append(updated, existing)
+			}
+		}
+		if len(updated) > maxEntries {
+			updated = updated[:maxEntries]
+		}
+		return updated
+	})
+}
+
+// Remove deletes every entry matching fn, returning how many were removed
+func (s *Store) Remove(fn func(Entry) bool) (int, error) {
+	removed := 0
+	err := s.update(func(entries []Entry) []Entry {
+		kept := []Entry{}
+		for _, entry := range entries {
+			if fn(entry) {
+				removed++
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		return kept
+	})
+	return removed, err
+}
+
+// Clear deletes the whole history
+func (s *Store) Clear() error {
+	return s.update(func([]Entry) []Entry {
+		return []Entry{}
+	})
+}
+
+func (s *Store) load() ([]Entry, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	entries := []Entry{}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+func (s *Store) update(fn func([]Entry) []Entry) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries = fn(entries)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func testEntry(name, keywords string) Entry {
+	dep := &parser.Dependency{Name: name, Version: "1.0.0", Type: "elixir", ProjectRoot: "/src/app"}
+	return NewEntry(dep, keywords)
+}
+
+func TestStore_RecordNewestFirst(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "history.json"))
+
+	for _, name := range []string{"ecto", "phoenix", "plug"} {
+		if err := store.Record(testEntry(name, "")); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Name != "plug" || entries[2].Name != "ecto" {
+		t.Errorf("Expected newest first, got %+v", entries)
+	}
+
+	last, ok, err := store.Last()
+	if err != nil || !ok || last.Name != "plug" {
+		t.Errorf("Last() = %+v, %v, %v", last, ok, err)
+	}
+}
+
+func TestStore_RecordMovesRepeatsToTop(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "history.json"))
+
+	for _, entry := range []Entry{testEntry("ecto", ""), testEntry("phoenix", ""), testEntry("ecto", ""), testEntry("ecto", "changeset")} {
+		if err := store.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Keywords != "changeset" || entries[1].Name != "ecto" || entries[2].Name != "phoenix" {
+		t.Errorf("Unexpected order: %+v", entries)
+	}
+}
+
+func TestStore_RecordIsBounded(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "history.json"))
+
+	for i := 0; i < maxEntries+5; i++ {
+		if err := store.Record(testEntry("ecto", string(rune('a'+i%26))+string(rune('a'+i/26)))); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != maxEntries {
+		t.Errorf("Expected history capped at %d entries, got %d", maxEntries, len(entries))
+	}
+}
+
+func TestStore_RemoveAndClear(t *testing.T) {
+	store := Open(filepath.Join(t.TempDir(), "history.json"))
+
+	for _, name := range []string{"ecto", "phoenix"} {
+		if err := store.Record(testEntry(name, "")); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	removed, err := store.Remove(func(e Entry) bool { return e.Name == "ecto" })
+	if err != nil || removed != 1 {
+		t.Errorf("Remove() = %d, %v", removed, err)
+	}
+	entries, _ := store.List()
+	if len(entries) != 1 || entries[0].Name != "phoenix" {
+		t.Errorf("Expected only phoenix left, got %+v", entries)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, ok, _ := store.Last(); ok {
+		t.Error("Expected an empty history after Clear")
+	}
+}
+
+func TestEntry_Dependency(t *testing.T) {
+	dep := &parser.Dependency{Name: "react", Version: "18.2.0", Type: "npm", Source: "npm", Dir: "/src/web/node_modules/react", ProjectRoot: "/src/web"}
+	entry := NewEntry(dep, "useState")
+
+	if got := entry.Dependency(); !reflect.DeepEqual(got, *dep) {
+		t.Errorf("Dependency() = %+v, want %+v", got, *dep)
+	}
+	if entry.Ecosystem != "npm" || entry.Keywords != "useState" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+}
@@ -0,0 +1,389 @@
+package metadata
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+// Info describes a dependency using the package metadata installed locally
+type Info struct {
+	Summary   string
+	License   string
+	Homepage  string
+	Changelog string // local path to the package's changelog, if it ships one
+}
+
+// Lookup reads what's known about a dependency from its installed package.
+// It's best effort: fields that can't be determined are left empty.
+func Lookup(dep *parser.Dependency) *Info {
+	var info *Info
+	var pkgDir string
+
+	switch dep.Type {
+	case "elixir":
+		pkgDir = filepath.Join(dep.ProjectRoot, "deps", dep.Name)
+		info, _ = parseHexMetadata(filepath.Join(pkgDir, "hex_metadata.config"))
+	case "gem":
+		if home := gemHome(); home != "" {
+			if spec := globFirst(filepath.Join(home, "specifications", dep.Name+"-"+dep.Version+"*.gemspec")); spec != "" {
+				info, _ = parseGemspec(spec)
+				pkgDir = filepath.Join(home, "gems", strings.TrimSuffix(filepath.Base(spec), ".gemspec"))
+			}
+		}
+		if dep.Dir != "" {
+			pkgDir = dep.Dir
+		}
+	case "go":
+		if dep.Name == "go" {
+			info = &Info{Homepage: "https://go.dev/doc/devel/release#go" + dep.Version}
+			break
+		}
+		info = &Info{Homepage: "https://pkg.go.dev/" + dep.Name + "@" + dep.Version}
+		pkgDir = dep.Dir
+		if pkgDir == "" {
+			pkgDir = goModuleDir(dep.Name, dep.Version)
+		}
+	case "npm":
+		pkgDir = dep.Dir
+		if pkgDir != "" {
+			info, _ = parsePackageJSON(filepath.Join(pkgDir, "package.json"))
+		}
+	case "pypi":
+		if distInfo := findDistInfo(dep.Dir, dep.Name, dep.Version); distInfo != "" {
+			info, _ = parsePythonMetadata(filepath.Join(distInfo, "METADATA"))
+		}
+	case "crate":
+		pkgDir = cargoPackageDir(dep.Name, dep.Version)
+		info, _ = parseCargoManifest(filepath.Join(pkgDir, "Cargo.toml"))
+	}
+
+	if info == nil {
+		info = &Info{}
+	}
+	if pkgDir != "" {
+		if info.License == "" {
+			info.License = detectLicense(pkgDir)
+		}
+		info.Changelog = findChangelog(pkgDir)
+	}
+	return info
+}
+
+var hexMetadataRegex = regexp.MustCompile(`(?s)\{<<"(description|licenses|links)">>,(.*?)\}\.\n`)
+var erlangBinaryRegex = regexp.MustCompile(`<<"((?:[^"\\]|\\.)*)">>`)
+
+// parseHexMetadata reads the hex_metadata.config that Hex writes into deps/<name>
+func parseHexMetadata(path string) (*Info, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{}
+	for _, match := range hexMetadataRegex.FindAllStringSubmatch(string(content), -1) {
+		values := []string{}
+		for _, binary := range erlangBinaryRegex.FindAllStringSubmatch(match[2], -1) {
+			values = append(values, strings.ReplaceAll(binary[1], `\"`, `"`))
+		}
+		if len(values) == 0 {
+			continue
+		}
+		switch match[1] {
+		case "description":
+			info.Summary = values[0]
+		case "licenses":
+			info.License = strings.Join(values, ", ")
+		case "links":
+			// Links are {Name, URL} pairs, prefer an explicit homepage
+			for i := 0; i+1 < len(values); i += 2 {
+				if info.Homepage == "" || strings.EqualFold(values[i], "homepage") {
+					info.Homepage = values[i+1]
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+var gemspecAttrRegex = regexp.MustCompile(`^\s*s\.(summary|homepage|licenses|license) = (.+?)(?:\.freeze)?$`)
+var rubyStringRegex = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+// parseGemspec reads the summary, license and homepage from an installed
+// gem's specification, which RubyGems writes in a predictable format
+func parseGemspec(path string) (*Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := &Info{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := gemspecAttrRegex.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		values := []string{}
+		for _, str := range rubyStringRegex.FindAllStringSubmatch(match[2], -1) {
+			values = append(values, strings.ReplaceAll(str[1], `\"`, `"`))
+		}
+		if len(values) == 0 {
+			continue
+		}
+		switch match[1] {
+		case "summary":
+			info.Summary = values[0]
+		case "homepage":
+			info.Homepage = values[0]
+		case "licenses", "license":
+			info.License = strings.Join(values, ", ")
+		}
+	}
+	return info, scanner.Err()
+}
+
+// parsePackageJSON reads an installed npm package's package.json
+func parsePackageJSON(path string) (*Info, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Description string      `json:"description"`
+		License     interface{} `json:"license"`
+		Homepage    string      `json:"homepage"`
+		Repository  interface{} `json:"repository"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	info := &Info{Summary: pkg.Description, Homepage: pkg.Homepage}
+	switch license := pkg.License.(type) {
+	case string:
+		info.License = license
+	case map[string]interface{}:
+		// Legacy {"type": "MIT", "url": "..."} form
+		info.License, _ = license["type"].(string)
+	}
+	if info.Homepage == "" {
+		switch repo := pkg.Repository.(type) {
+		case string:
+			info.Homepage = repo
+		case map[string]interface{}:
+			url, _ := repo["url"].(string)
+			info.Homepage = strings.TrimSuffix(strings.TrimPrefix(url, "git+"), ".git")
+		}
+	}
+	return info, nil
+}
+
+// parsePythonMetadata reads the core metadata headers of an installed distribution
+func parsePythonMetadata(path string) (*Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	msg, err := mail.ReadMessage(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	info := &Info{
+		Summary:  msg.Header.Get("Summary"),
+		License:  msg.Header.Get("License-Expression"),
+		Homepage: msg.Header.Get("Home-page"),
+	}
+	if info.License == "" {
+		// The free-form License header sometimes holds the whole license text
+		if license := msg.Header.Get("License"); !strings.Contains(license, "\n") && len(license) < 80 {
+			info.License = license
+		}
+	}
+	if info.Homepage == "" {
+		for _, projectURL := range msg.Header["Project-Url"] {
+			label, url, ok := strings.Cut(projectURL, ",")
+			if !ok {
+				continue
+			}
+			if info.Homepage == "" || strings.EqualFold(strings.TrimSpace(label), "homepage") {
+				info.Homepage = strings.TrimSpace(url)
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseCargoManifest reads the [package] metadata of a crate from the registry
+func parseCargoManifest(path string) (*Info, error) {
+	var manifest struct {
+		Package struct {
+			Description string `toml:"description"`
+			License     string `toml:"license"`
+			Homepage    string `toml:"homepage"`
+			Repository  string `toml:"repository"`
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(path, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	info := &Info{
+		Summary:  strings.Join(strings.Fields(manifest.Package.Description), " "),
+		License:  manifest.Package.License,
+		Homepage: manifest.Package.Homepage,
+	}
+	if info.Homepage == "" {
+		info.Homepage = manifest.Package.Repository
+	}
+	return info, nil
+}
+
+var (
+	gemHomeOnce sync.Once
+	gemHomeDir  string
+)
+
+// gemHome returns `gem env home`, asking RubyGems only once per process
+func gemHome() string {
+	gemHomeOnce.Do(func() {
+		output, err := exec.Command("gem", "env", "home").Output()
+		if err == nil {
+			gemHomeDir = strings.TrimSpace(string(output))
+		}
+	})
+	return gemHomeDir
+}
+
+// goModuleDir returns where a module version is extracted in the module cache
+func goModuleDir(path, version string) string {
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		gopath := os.Getenv("GOPATH")
+		if gopath == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return ""
+			}
+			gopath = filepath.Join(home, "go")
+		}
+		modCache = filepath.Join(strings.Split(gopath, string(os.PathListSeparator))[0], "pkg", "mod")
+	}
+
+	// Upper case letters are escaped as "!" + lower case in module cache paths
+	var escaped strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			escaped.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		escaped.WriteRune(r)
+	}
+	return filepath.Join(modCache, escaped.String()+"@"+version)
+}
+
+// cargoPackageDir returns where Cargo unpacked a crate from the registry
+func cargoPackageDir(name, version string) string {
+	cargoHome := os.Getenv("CARGO_HOME")
+	if cargoHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		cargoHome = filepath.Join(home, ".cargo")
+	}
+	return globFirst(filepath.Join(cargoHome, "registry", "src", "*", name+"-"+version))
+}
+
+// findDistInfo locates the .dist-info directory of a Python distribution
+func findDistInfo(sitePackages, name, version string) string {
+	if sitePackages == "" {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(sitePackages, "*.dist-info"))
+	for _, match := range matches {
+		base := strings.TrimSuffix(filepath.Base(match), ".dist-info")
+		idx := strings.LastIndex(base, "-")
+		if idx == -1 {
+			continue
+		}
+		if parser.NormalizePythonName(base[:idx]) == parser.NormalizePythonName(name) && base[idx+1:] == version {
+			return match
+		}
+	}
+	return ""
+}
+
+// findChangelog returns the path of a changelog shipped in a package directory
+func findChangelog(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, prefix := range []string{"changelog", "changes", "history", "news"} {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasPrefix(strings.ToLower(entry.Name()), prefix) {
+				return filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return ""
+}
+
+// licensePatterns recognise common licenses from the start of a LICENSE file
+var licensePatterns = []struct {
+	license string
+	regex   *regexp.Regexp
+}{
+	{"Apache-2.0", regexp.MustCompile(`(?i)apache license,?\s+version 2\.0`)},
+	{"MPL-2.0", regexp.MustCompile(`(?i)mozilla public license,?\s+version 2\.0`)},
+	{"GPL-3.0", regexp.MustCompile(`(?i)gnu general public license\s+version 3`)},
+	{"GPL-2.0", regexp.MustCompile(`(?i)gnu general public license\s+version 2`)},
+	{"ISC", regexp.MustCompile(`(?i)^\s*(the )?isc license`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?i)neither the name of`)},
+	{"BSD-2-Clause", regexp.MustCompile(`(?i)redistributions in binary form must reproduce`)},
+	{"MIT", regexp.MustCompile(`(?i)permission is hereby granted, free of charge`)},
+}
+
+// detectLicense guesses the license of a package from its LICENSE file
+func detectLicense(dir string) string {
+	path := globFirst(filepath.Join(dir, "LICENSE*"), filepath.Join(dir, "LICENCE*"), filepath.Join(dir, "COPYING*"))
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, pattern := range licensePatterns {
+		if pattern.regex.Match(content) {
+			return pattern.license
+		}
+	}
+	return ""
+}
+
+// globFirst returns the first match of any of the patterns
+func globFirst(patterns ...string) string {
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return matches[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func TestParseHexMetadata(t *testing.T) {
+	info, err := parseHexMetadata(filepath.Join("testdata", "hex_metadata.config"))
+	if err != nil {
+		t.Fatalf("parseHexMetadata failed: %v", err)
+	}
+	want := Info{
+		Summary:  "Peace of mind from prototype to production",
+		License:  "MIT",
+		Homepage: "https://www.phoenixframework.org",
+	}
+	if *info != want {
+		t.Errorf("parseHexMetadata() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestParseGemspec(t *testing.T) {
+	info, err := parseGemspec(filepath.Join("testdata", "rack-3.0.8.gemspec"))
+	if err != nil {
+		t.Fatalf("parseGemspec failed: %v", err)
+	}
+	want := Info{
+		Summary:  "A modular Ruby webserver interface.",
+		License:  "MIT",
+		Homepage: "https://github.com/rack/rack",
+	}
+	if *info != want {
+		t.Errorf("parseGemspec() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	tests := []struct {
+		file string
+		want Info
+	}{
+		{"package.json", Info{Summary: "Fast, unopinionated, minimalist web framework", License: "MIT", Homepage: "http://expressjs.com/"}},
+		{"package-legacy.json", Info{Summary: "Something from 2013", License: "BSD", Homepage: "https://github.com/example/old-thing"}},
+	}
+	for _, tt := range tests {
+		info, err := parsePackageJSON(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatalf("parsePackageJSON(%s) failed: %v", tt.file, err)
+		}
+		if *info != tt.want {
+			t.Errorf("parsePackageJSON(%s) = %+v, want %+v", tt.file, *info, tt.want)
+		}
+	}
+}
+
+func TestParsePythonMetadata(t *testing.T) {
+	info, err := parsePythonMetadata(filepath.Join("testdata", "METADATA"))
+	if err != nil {
+		t.Fatalf("parsePythonMetadata failed: %v", err)
+	}
+	want := Info{
+		Summary:  "Python HTTP for Humans.",
+		License:  "Apache 2.0",
+		Homepage: "https://requests.readthedocs.io",
+	}
+	if *info != want {
+		t.Errorf("parsePythonMetadata() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestParseCargoManifest(t *testing.T) {
+	info, err := parseCargoManifest(filepath.Join("testdata", "Cargo.toml"))
+	if err != nil {
+		t.Fatalf("parseCargoManifest failed: %v", err)
+	}
+	want := Info{
+		Summary:  "A generic serialization/deserialization framework",
+		License:  "MIT OR Apache-2.0",
+		Homepage: "https://serde.rs",
+	}
+	if *info != want {
+		t.Errorf("parseCargoManifest() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestLookup_GoModuleDetectsLicenseAndChangelog(t *testing.T) {
+	dir := filepath.Join("testdata", "gomod")
+	info := Lookup(&parser.Dependency{Name: "example.com/gomod", Version: "v1.2.0", Type: "go", Source: "path", Dir: dir})
+
+	if info.Homepage != "https://pkg.go.dev/example.com/gomod@v1.2.0" {
+		t.Errorf("Unexpected homepage %q", info.Homepage)
+	}
+	if info.License != "BSD-3-Clause" {
+		t.Errorf("Expected BSD-3-Clause, got %q", info.License)
+	}
+	if info.Changelog != filepath.Join(dir, "CHANGELOG.md") {
+		t.Errorf("Unexpected changelog %q", info.Changelog)
+	}
+}
+
+func TestLookup_UnknownDependency(t *testing.T) {
+	info := Lookup(&parser.Dependency{Name: "missing", Version: "1.0.0", Type: "npm", Dir: t.TempDir()})
+	if *info != (Info{}) {
+		t.Errorf("Expected empty info, got %+v", *info)
+	}
+}
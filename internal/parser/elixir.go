@@ -3,12 +3,38 @@ package parser
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-// ParseElixirDeps parses dependencies from a Mix project
+// ParseElixirDeps parses dependencies from a Mix project. It reads mix.lock
+// directly and only falls back to `mix deps --all` when there is no lockfile.
 func ParseElixirDeps(projectRoot string) ([]Dependency, error) {
+	lockPath := filepath.Join(projectRoot, "mix.lock")
+	if !fileExists(lockPath) {
+		return parseElixirDepsWithMix(projectRoot)
+	}
+
+	lockDeps, err := ParseMixLock(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+	if elixirVersion, err := getElixirVersion(projectRoot); err == nil && elixirVersion != "" {
+		deps = append(deps, Dependency{
+			Name:    "elixir",
+			Version: elixirVersion,
+			Type:    "elixir",
+		})
+	}
+
+	return append(deps, lockDeps...), nil
+}
+
+// parseElixirDepsWithMix lists dependencies by scraping `mix deps --all`
+func parseElixirDepsWithMix(projectRoot string) ([]Dependency, error) {
 	// Use mix deps command to get dependencies
 	cmd := exec.Command("mix", "deps", "--all")
 	cmd.Dir = projectRoot
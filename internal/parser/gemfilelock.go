@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GemfileLock is the parsed content of a Gemfile.lock
+type GemfileLock struct {
+	Sources      []GemSource
+	Platforms    []string
+	Dependencies []string // direct dependencies from the DEPENDENCIES section
+	RubyVersion  string
+	BundledWith  string
+}
+
+// GemSource is a GEM, GIT or PATH section of a Gemfile.lock
+type GemSource struct {
+	Type     string // "rubygems", "git" or "path"
+	Remote   string
+	Revision string
+	Ref      string // git branch, tag or ref, if any
+	Specs    []GemSpec
+}
+
+// GemSpec is a single locked gem from a specs: tree
+type GemSpec struct {
+	Name         string
+	Version      string
+	Platform     string // e.g. "x86_64-linux", empty for pure ruby gems
+	Dependencies []string
+}
+
+var (
+	gemSpecRegex         = regexp.MustCompile(`^    (\S+) \(([^)]+)\)$`)
+	gemSpecDepRegex      = regexp.MustCompile(`^      (\S+)`)
+	gemDependencyRegex   = regexp.MustCompile(`^  (\S+?)!?(?: \(.*\))?$`)
+	lockRubyVersionRegex = regexp.MustCompile(`ruby\s+(\d+\.\d+\.\d+)`)
+	// gemPlatformRegex matches a version followed by a platform: java, or a
+	// CPU and an OS with an optional OS version, e.g. "x86_64-linux-musl"
+	gemPlatformRegex = regexp.MustCompile(`^(.+?)-(java|jruby|dalvik\d*|dotnet|(?:x86_64|x86|x64|i[3-6]86|universal|arm\w*|aarch64|powerpc\w*|ppc\w*|s390x?|sparc\w*|mips\w*|riscv64|loongarch64)-[a-z]\w*(?:-[\w.]+)?)$`)
+)
+
+// ParseGemfileLock reads and parses a Gemfile.lock
+func ParseGemfileLock(path string) (*GemfileLock, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	lock := &GemfileLock{}
+	var section string
+	var source *GemSource
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+		if line == "" {
+			continue
+		}
+
+		// Section headers start at column 0
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			source = nil
+			switch section {
+			case "GEM", "GIT", "PATH":
+				sourceType := map[string]string{"GEM": "rubygems", "GIT": "git", "PATH": "path"}[section]
+				lock.Sources = append(lock.Sources, GemSource{Type: sourceType})
+				source = &lock.Sources[len(lock.Sources)-1]
+			}
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			parseGemSourceLine(source, line)
+		case "PLATFORMS":
+			lock.Platforms = append(lock.Platforms, strings.TrimSpace(line))
+		case "DEPENDENCIES":
+			if matches := gemDependencyRegex.FindStringSubmatch(line); len(matches) > 1 {
+				lock.Dependencies = append(lock.Dependencies, matches[1])
+			}
+		case "RUBY VERSION":
+			if matches := lockRubyVersionRegex.FindStringSubmatch(line); len(matches) > 1 {
+				lock.RubyVersion = matches[1]
+			}
+		case "BUNDLED WITH":
+			lock.BundledWith = strings.TrimSpace(line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return lock, nil
+}
+
+func parseGemSourceLine(source *GemSource, line string) {
+	if matches := gemSpecRegex.FindStringSubmatch(line); len(matches) > 2 {
+		version, platform := splitGemPlatform(matches[2])
+		source.Specs = append(source.Specs, GemSpec{
+			Name:     matches[1],
+			Version:  version,
+			Platform: platform,
+		})
+		return
+	}
+
+	if matches := gemSpecDepRegex.FindStringSubmatch(line); len(matches) > 1 && len(source.Specs) > 0 {
+		spec := &source.Specs[len(source.Specs)-1]
+		spec.Dependencies = append(spec.Dependencies, matches[1])
+		return
+	}
+
+	// Source attributes such as "  remote: https://rubygems.org/"
+	key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found {
+		return
+	}
+	value = strings.TrimSpace(value)
+	switch key {
+	case "remote":
+		source.Remote = value
+	case "revision":
+		source.Revision = value
+	case "branch", "tag", "ref":
+		source.Ref = value
+	}
+}
+
+// splitGemPlatform splits "1.16.0-x86_64-linux" into "1.16.0" and "x86_64-linux".
+// Prerelease versions can hold dashes too, so only a known platform is split off.
+func splitGemPlatform(version string) (string, string) {
+	if matches := gemPlatformRegex.FindStringSubmatch(version); matches != nil {
+		return matches[1], matches[2]
+	}
+	return version, ""
+}
+
+// Deps flattens the locked specs into dependencies, listing each gem once
+// even when it is locked for several platforms. Gems missing from the
+// DEPENDENCIES section are only required by other gems.
+func (l *GemfileLock) Deps() []Dependency {
+	deps := []Dependency{}
+	seen := map[string]bool{}
+	for _, source := range l.Sources {
+		for _, spec := range source.Specs {
+			if seen[spec.Name] {
+				continue
+			}
+			seen[spec.Name] = true
+			deps = append(deps, Dependency{
+				Name:       spec.Name,
+				Version:    spec.Version,
+				Type:       "gem",
+				Source:     source.Type,
+				Transitive: len(l.Dependencies) > 0 && !slices.Contains(l.Dependencies, spec.Name),
+			})
+		}
+	}
+	return deps
+}
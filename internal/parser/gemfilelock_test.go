@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGemfileLock(t *testing.T) {
+	lock, err := ParseGemfileLock(filepath.Join("testdata", "Gemfile.lock"))
+	if err != nil {
+		t.Fatalf("ParseGemfileLock failed: %v", err)
+	}
+
+	if len(lock.Sources) != 3 {
+		t.Fatalf("Expected 3 sources, got %d", len(lock.Sources))
+	}
+
+	git := lock.Sources[0]
+	if git.Type != "git" || git.Remote != "https://github.com/rails/rails.git" || git.Ref != "main" {
+		t.Errorf("Unexpected git source: %+v", git)
+	}
+	if git.Revision != "3d1b4a7c9e0f0b1c2d3e4f5a6b7c8d9e0f1a2b3c" {
+		t.Errorf("Unexpected git revision %q", git.Revision)
+	}
+	if !reflect.DeepEqual(git.Specs[0].Dependencies, []string{"actionpack"}) {
+		t.Errorf("Unexpected rails spec dependencies: %v", git.Specs[0].Dependencies)
+	}
+
+	if lock.Sources[1].Type != "path" || lock.Sources[1].Remote != "." {
+		t.Errorf("Unexpected path source: %+v", lock.Sources[1])
+	}
+
+	gem := lock.Sources[2]
+	if gem.Type != "rubygems" || len(gem.Specs) != 5 {
+		t.Fatalf("Unexpected gem source: %+v", gem)
+	}
+	nokogiri := gem.Specs[1]
+	if nokogiri.Name != "nokogiri" || nokogiri.Version != "1.16.0" || nokogiri.Platform != "arm64-darwin" {
+		t.Errorf("Expected nokogiri 1.16.0 for arm64-darwin, got %+v", nokogiri)
+	}
+
+	if !reflect.DeepEqual(lock.Platforms, []string{"arm64-darwin", "x86_64-linux"}) {
+		t.Errorf("Unexpected platforms: %v", lock.Platforms)
+	}
+	if !reflect.DeepEqual(lock.Dependencies, []string{"my_app_tools", "nokogiri", "rails"}) {
+		t.Errorf("Unexpected dependencies: %v", lock.Dependencies)
+	}
+	if lock.RubyVersion != "3.2.2" {
+		t.Errorf("Expected ruby version 3.2.2, got %q", lock.RubyVersion)
+	}
+	if lock.BundledWith != "2.4.10" {
+		t.Errorf("Expected bundler 2.4.10, got %q", lock.BundledWith)
+	}
+}
+
+func TestGemfileLockDeps_DeduplicatesPlatforms(t *testing.T) {
+	lock, err := ParseGemfileLock(filepath.Join("testdata", "Gemfile.lock"))
+	if err != nil {
+		t.Fatalf("ParseGemfileLock failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "rails", Version: "7.2.0.alpha", Type: "gem", Source: "git"},
+		{Name: "my_app_tools", Version: "0.1.0", Type: "gem", Source: "path"},
+		{Name: "actionpack", Version: "7.2.0.alpha", Type: "gem", Source: "rubygems", Transitive: true},
+		{Name: "nokogiri", Version: "1.16.0", Type: "gem", Source: "rubygems"},
+		{Name: "racc", Version: "1.7.3", Type: "gem", Source: "rubygems", Transitive: true},
+		{Name: "rack", Version: "3.0.8", Type: "gem", Source: "rubygems", Transitive: true},
+	}
+	if deps := lock.Deps(); !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestGemfileLockDeps_Transitive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Gemfile.lock")
+	lock := "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (3.0.8)\n    rack-test (2.1.0)\n      rack (>= 1.3)\n\nDEPENDENCIES\n  rack-test\n"
+	if err := os.WriteFile(path, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseGemfileLock(path)
+	if err != nil {
+		t.Fatalf("ParseGemfileLock failed: %v", err)
+	}
+	deps := parsed.Deps()
+	if len(deps) != 2 || !deps[0].Transitive || deps[1].Transitive {
+		t.Errorf("Expected only rack to be transitive, got %+v", deps)
+	}
+}
+
+func TestSplitGemPlatform(t *testing.T) {
+	tests := []struct {
+		input    string
+		version  string
+		platform string
+	}{
+		{"1.16.0", "1.16.0", ""},
+		{"1.16.0-x86_64-linux", "1.16.0", "x86_64-linux"},
+		{"1.15.4-java", "1.15.4", "java"},
+		{"7.2.0.alpha", "7.2.0.alpha", ""},
+		{"1.0.0-beta", "1.0.0-beta", ""},
+		{"1.0.0-beta-x86_64-linux", "1.0.0-beta", "x86_64-linux"},
+		{"1.16.0-x86_64-linux-musl", "1.16.0", "x86_64-linux-musl"},
+		{"1.16.0-x64-mingw-ucrt", "1.16.0", "x64-mingw-ucrt"},
+		{"2.0.0-rc-1-arm64-darwin", "2.0.0-rc-1", "arm64-darwin"},
+		{"1.6.0-universal-darwin-22", "1.6.0", "universal-darwin-22"},
+	}
+
+	for _, tt := range tests {
+		version, platform := splitGemPlatform(tt.input)
+		if version != tt.version || platform != tt.platform {
+			t.Errorf("splitGemPlatform(%q) = %q, %q; expected %q, %q",
+				tt.input, version, platform, tt.version, tt.platform)
+		}
+	}
+}
+
+func TestParseRubyDeps_UsesGemfileLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	lock := "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (3.0.8)\n\nRUBY VERSION\n   ruby 3.3.0p0\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "Gemfile.lock"), []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write Gemfile.lock: %v", err)
+	}
+
+	deps, err := ParseRubyDeps(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseRubyDeps failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "ruby", Version: "3.3.0", Type: "gem"},
+		{Name: "rack", Version: "3.0.8", Type: "gem", Source: "rubygems"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
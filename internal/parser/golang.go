@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GoMod is the parsed content of a go.mod file
+type GoMod struct {
+	Module    string
+	GoVersion string
+	Toolchain string // e.g. "go1.24.4", empty when not set
+	Requires  []GoRequire
+	Replaces  []GoReplace
+	Excludes  []GoModuleVersion
+}
+
+// GoModuleVersion is a module path at a specific version
+type GoModuleVersion struct {
+	Path    string
+	Version string
+}
+
+// GoRequire is a single require directive
+type GoRequire struct {
+	GoModuleVersion
+	Indirect bool
+}
+
+// GoReplace is a single replace directive. Old.Version is empty when the
+// replacement applies to all versions, New.Version is empty when the
+// replacement is a local directory.
+type GoReplace struct {
+	Old GoModuleVersion
+	New GoModuleVersion
+}
+
+// ParseGoDeps parses dependencies from a Go module
+func ParseGoDeps(projectRoot string) ([]Dependency, error) {
+	mod, err := ParseGoMod(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+
+	// Add the Go toolchain itself, preferring the toolchain line
+	goVersion := strings.TrimPrefix(mod.Toolchain, "go")
+	if goVersion == "" {
+		goVersion = mod.GoVersion
+	}
+	if goVersion != "" {
+		deps = append(deps, Dependency{
+			Name:    "go",
+			Version: goVersion,
+			Type:    "go",
+		})
+	}
+
+	excluded := map[GoModuleVersion]bool{}
+	for _, exclude := range mod.Excludes {
+		excluded[exclude] = true
+	}
+
+	for _, req := range mod.Requires {
+		if excluded[req.GoModuleVersion] {
+			continue
+		}
+
+		dep := Dependency{
+			Name:       req.Path,
+			Version:    req.Version,
+			Type:       "go",
+			Source:     "proxy",
+			Transitive: req.Indirect,
+		}
+
+		if replace, ok := mod.replacementFor(req.GoModuleVersion); ok {
+			if replace.New.Version == "" {
+				// Local directory replacement
+				dep.Source = "path"
+				dep.Dir = replace.New.Path
+				if !filepath.IsAbs(dep.Dir) {
+					dep.Dir = filepath.Join(projectRoot, dep.Dir)
+				}
+			} else {
+				dep.Name = replace.New.Path
+				dep.Version = replace.New.Version
+				dep.Source = "replace"
+			}
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// replacementFor finds the replace directive that applies to a module version.
+// A version-specific replacement wins over a wildcard one.
+func (m *GoMod) replacementFor(mv GoModuleVersion) (GoReplace, bool) {
+	var wildcard *GoReplace
+	for i, replace := range m.Replaces {
+		if replace.Old.Path != mv.Path {
+			continue
+		}
+		if replace.Old.Version == mv.Version {
+			return replace, true
+		}
+		if replace.Old.Version == "" {
+			wildcard = &m.Replaces[i]
+		}
+	}
+	if wildcard != nil {
+		return *wildcard, true
+	}
+	return GoReplace{}, false
+}
+
+// ParseGoMod reads and parses a go.mod file
+func ParseGoMod(path string) (*GoMod, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	mod := &GoMod{}
+	var block string // directive of the current ( ... ) block, if any
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		indirect := strings.TrimSpace(comment) == "indirect"
+
+		if line == "" {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+			if err := mod.addDirective(block, goModFields(line), indirect); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			continue
+		}
+
+		fields := goModFields(line)
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if err := mod.addDirective(fields[0], fields[1:], indirect); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return mod, nil
+}
+
+func (m *GoMod) addDirective(verb string, args []string, indirect bool) error {
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: module path")
+		}
+		m.Module = args[0]
+	case "go":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: go 1.23")
+		}
+		m.GoVersion = args[0]
+	case "toolchain":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: toolchain go1.23.1")
+		}
+		m.Toolchain = args[0]
+	case "require":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: require module/path v1.2.3")
+		}
+		m.Requires = append(m.Requires, GoRequire{
+			GoModuleVersion: GoModuleVersion{Path: args[0], Version: args[1]},
+			Indirect:        indirect,
+		})
+	case "exclude":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: exclude module/path v1.2.3")
+		}
+		m.Excludes = append(m.Excludes, GoModuleVersion{Path: args[0], Version: args[1]})
+	case "replace":
+		arrow := -1
+		for i, arg := range args {
+			if arg == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+			return fmt.Errorf("usage: replace module/path [v1.2.3] => other/module [v1.4.5]")
+		}
+		replace := GoReplace{Old: GoModuleVersion{Path: args[0]}}
+		if arrow == 2 {
+			replace.Old.Version = args[1]
+		}
+		replace.New.Path = args[arrow+1]
+		if len(args) > arrow+2 {
+			replace.New.Version = args[arrow+2]
+		}
+		m.Replaces = append(m.Replaces, replace)
+	}
+	// Other directives (retract, godebug, tool, ignore) don't affect dependencies
+	return nil
+}
+
+// goModFields splits a go.mod line into fields, unquoting quoted strings
+func goModFields(line string) []string {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if strings.HasPrefix(field, `"`) || strings.HasPrefix(field, "`") {
+			if unquoted, err := strconv.Unquote(field); err == nil {
+				fields[i] = unquoted
+			}
+		}
+	}
+	return fields
+}
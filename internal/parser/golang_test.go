@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	mod, err := ParseGoMod(filepath.Join("testdata", "go.mod"))
+	if err != nil {
+		t.Fatalf("ParseGoMod failed: %v", err)
+	}
+
+	if mod.Module != "example.com/app" {
+		t.Errorf("Expected module example.com/app, got %q", mod.Module)
+	}
+	if mod.GoVersion != "1.23" || mod.Toolchain != "go1.24.4" {
+		t.Errorf("Unexpected go/toolchain lines: %q %q", mod.GoVersion, mod.Toolchain)
+	}
+	if len(mod.Requires) != 6 {
+		t.Fatalf("Expected 6 requires, got %d", len(mod.Requires))
+	}
+	if !mod.Requires[2].Indirect || mod.Requires[1].Indirect {
+		t.Errorf("Indirect markers not parsed correctly: %+v", mod.Requires)
+	}
+
+	expectedReplaces := []GoReplace{
+		{Old: GoModuleVersion{"example.com/forked", "v1.0.0"}, New: GoModuleVersion{"github.com/me/forked", "v1.0.1"}},
+		{Old: GoModuleVersion{"example.com/local", ""}, New: GoModuleVersion{"../local", ""}},
+	}
+	if !reflect.DeepEqual(mod.Replaces, expectedReplaces) {
+		t.Errorf("Expected replaces %+v, got %+v", expectedReplaces, mod.Replaces)
+	}
+
+	expectedExcludes := []GoModuleVersion{{"example.com/excluded", "v1.2.0"}}
+	if !reflect.DeepEqual(mod.Excludes, expectedExcludes) {
+		t.Errorf("Expected excludes %+v, got %+v", expectedExcludes, mod.Excludes)
+	}
+}
+
+func TestParseGoDeps(t *testing.T) {
+	root, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseGoDeps(root)
+	if err != nil {
+		t.Fatalf("ParseGoDeps failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "go", Version: "1.24.4", Type: "go"},
+		{Name: "github.com/stretchr/testify", Version: "v1.11.1", Type: "go", Source: "proxy"},
+		{Name: "github.com/BurntSushi/toml", Version: "v1.4.0", Type: "go", Source: "proxy"},
+		{Name: "golang.org/x/text", Version: "v0.24.0", Type: "go", Source: "proxy", Transitive: true},
+		{Name: "github.com/me/forked", Version: "v1.0.1", Type: "go", Source: "replace"},
+		{Name: "example.com/local", Version: "v0.0.0-00010101000000-000000000000", Type: "go", Source: "path", Dir: filepath.Join(filepath.Dir(root), "local")},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParseGoMod_InvalidDirective(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "go.mod")
+	content := "module example.com/app\n\nrequire github.com/only/path\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	if _, err := ParseGoMod(path); err == nil {
+		t.Error("Expected error for malformed require, got nil")
+	}
+}
@@ -0,0 +1,411 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MixLockEntry is a single entry from a mix.lock file
+type MixLockEntry struct {
+	App     string // key in the lock map, e.g. "phoenix"
+	Kind    string // "hex", "git" or "path"
+	Package string // hex package name (may differ from App)
+	Version string // locked version for hex, revision for git
+	Repo    string // hex repository ("hexpm") or git URL
+	Ref     string // git tag/branch/ref, if any
+	Path    string // local path for path deps
+}
+
+// ParseMixLock reads a mix.lock file and returns its dependencies
+func ParseMixLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	entries, err := parseMixLockEntries(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	deps := make([]Dependency, 0, len(entries))
+	for _, entry := range entries {
+		dep := entry.Dependency()
+		if dep.Dir != "" && !filepath.IsAbs(dep.Dir) {
+			dep.Dir = filepath.Join(filepath.Dir(path), dep.Dir)
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// Dependency converts the lock entry into a Dependency, whose Source tells
+// Hex packages from git and path dependencies, which have no Hex docs
+func (e MixLockEntry) Dependency() Dependency {
+	dep := Dependency{
+		Name:    e.App,
+		Version: e.Version,
+		Type:    "elixir",
+		Source:  e.Kind,
+	}
+
+	switch e.Kind {
+	case "hex":
+		if e.Package != "" {
+			dep.Name = e.Package
+		}
+	case "git":
+		// Prefer the human readable ref, fall back to a short revision
+		if e.Ref != "" {
+			dep.Version = e.Ref
+		} else if len(e.Version) > 7 {
+			dep.Version = e.Version[:7]
+		}
+	case "path":
+		dep.Dir = e.Path
+	}
+
+	return dep
+}
+
+// parseMixLockEntries parses the Elixir map literal stored in mix.lock
+func parseMixLockEntries(content string) ([]MixLockEntry, error) {
+	p := &termParser{input: content}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected trailing content")
+	}
+
+	lock, ok := value.(termMap)
+	if !ok {
+		return nil, fmt.Errorf("expected a map at top level")
+	}
+
+	apps := make([]string, 0, len(lock))
+	for app := range lock {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	entries := []MixLockEntry{}
+	for _, app := range apps {
+		tuple, ok := lock[app].(termTuple)
+		if !ok || len(tuple) == 0 {
+			return nil, fmt.Errorf("entry %q is not a tuple", app)
+		}
+		kind, _ := tuple[0].(termAtom)
+
+		entry := MixLockEntry{App: app, Kind: string(kind)}
+		switch kind {
+		case "hex":
+			// {:hex, :name, "version", "inner_hash", [:mix], [deps], "hexpm", "outer_hash"}
+			if len(tuple) < 3 {
+				return nil, fmt.Errorf("hex entry %q is too short", app)
+			}
+			entry.Package = termString(tuple[1])
+			entry.Version = termString(tuple[2])
+			if len(tuple) > 6 {
+				entry.Repo = termString(tuple[6])
+			}
+		case "git":
+			// {:git, "url", "revision", [tag: "v1.0.0"]}
+			if len(tuple) < 3 {
+				return nil, fmt.Errorf("git entry %q is too short", app)
+			}
+			entry.Repo = termString(tuple[1])
+			entry.Version = termString(tuple[2])
+			if len(tuple) > 3 {
+				if opts, ok := tuple[3].(termList); ok {
+					for _, key := range []string{"tag", "ref", "branch"} {
+						if ref := opts.keyword(key); ref != "" {
+							entry.Ref = ref
+							break
+						}
+					}
+				}
+			}
+		case "path":
+			// {:path, "relative/path", [opts]}
+			if len(tuple) < 2 {
+				return nil, fmt.Errorf("path entry %q is too short", app)
+			}
+			entry.Path = termString(tuple[1])
+		default:
+			// Unknown SCMs are skipped rather than failing the whole lockfile
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Erlang/Elixir term representation used by the mix.lock reader
+type (
+	termAtom  string
+	termTuple []interface{}
+	termList  []interface{}
+	termMap   map[string]interface{}
+	termPair  struct {
+		Key   string
+		Value interface{}
+	}
+)
+
+// keyword returns the string value for key in a keyword list
+func (l termList) keyword(key string) string {
+	for _, item := range l {
+		if pair, ok := item.(termPair); ok && pair.Key == key {
+			return termString(pair.Value)
+		}
+	}
+	return ""
+}
+
+// termString returns strings and atoms as plain Go strings
+func termString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case termAtom:
+		return string(s)
+	}
+	return ""
+}
+
+// termParser is a small recursive descent parser for the subset of Elixir
+// literals that appear in mix.lock files
+type termParser struct {
+	input string
+	pos   int
+}
+
+func (p *termParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.input[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *termParser) skipSpace() {
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '#':
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *termParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *termParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *termParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of input")
+	case c == '%':
+		p.pos++
+		return p.parseMap()
+	case c == '{':
+		p.pos++
+		items, err := p.parseSequence('}')
+		return termTuple(items), err
+	case c == '[':
+		p.pos++
+		items, err := p.parseSequence(']')
+		return termList(items), err
+	case c == '"':
+		return p.parseString()
+	case c == ':':
+		p.pos++
+		return p.parseAtom()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case isIdentStart(c):
+		// Bare identifiers such as true, false and nil
+		return termAtom(p.parseIdent()), nil
+	default:
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *termParser) parseMap() (interface{}, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+
+	result := termMap{}
+	for {
+		if p.peek() == '}' {
+			p.pos++
+			return result, nil
+		}
+
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		// Keys are written as "name": value or "name" => value
+		if p.peek() == ':' {
+			p.pos++
+		} else if strings.HasPrefix(p.input[p.pos:], "=>") {
+			p.pos += 2
+		} else {
+			return nil, p.errorf("expected ':' or '=>' after map key")
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result[termString(key)] = value
+
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != '}' {
+			return nil, p.errorf("expected ',' or '}' in map")
+		}
+	}
+}
+
+func (p *termParser) parseSequence(closing byte) ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		if p.peek() == closing {
+			p.pos++
+			return items, nil
+		}
+
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != closing {
+			return nil, p.errorf("expected ',' or %q", closing)
+		}
+	}
+}
+
+// parseItem parses a sequence element, which may be a keyword pair (key: value)
+func (p *termParser) parseItem() (interface{}, error) {
+	p.skipSpace()
+	start := p.pos
+	if isIdentStart(p.peek()) {
+		ident := p.parseIdent()
+		if p.pos < len(p.input) && p.input[p.pos] == ':' {
+			p.pos++
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			return termPair{Key: ident, Value: value}, nil
+		}
+		p.pos = start
+	}
+	return p.parseValue()
+}
+
+func (p *termParser) parseString() (string, error) {
+	p.pos++ // opening quote
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if p.pos+1 >= len(p.input) {
+				return "", p.errorf("unterminated escape")
+			}
+			p.pos++
+			switch esc := p.input[p.pos]; esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(esc)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *termParser) parseAtom() (termAtom, error) {
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		s, err := p.parseString()
+		return termAtom(s), err
+	}
+	ident := p.parseIdent()
+	if ident == "" {
+		return "", p.errorf("invalid atom")
+	}
+	return termAtom(ident), nil
+}
+
+func (p *termParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if !isIdentStart(c) && !(c >= '0' && c <= '9') && c != '?' && c != '!' && c != '@' {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *termParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.input[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+		p.pos++
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(p.input[start:p.pos], "_", ""))
+	if err != nil {
+		return nil, p.errorf("invalid number")
+	}
+	return n, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
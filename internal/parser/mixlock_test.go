@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMixLock(t *testing.T) {
+	deps, err := ParseMixLock(filepath.Join("testdata", "mix.lock"))
+	if err != nil {
+		t.Fatalf("ParseMixLock failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "castore", Version: "1.0.5", Type: "elixir", Source: "hex"},
+		{Name: "heroicons", Version: "v2.1.1", Type: "elixir", Source: "git"},
+		{Name: "jason", Version: "1.4.1", Type: "elixir", Source: "hex"},
+		// Path dependencies are relative to the lockfile
+		{Name: "local_lib", Version: "", Type: "elixir", Source: "path", Dir: "local_lib"},
+		{Name: "my_fork", Version: "0123456", Type: "elixir", Source: "git"},
+		{Name: "phoenix", Version: "1.7.10", Type: "elixir", Source: "hex"},
+		{Name: "postgrex", Version: "0.17.4", Type: "elixir", Source: "hex"},
+	}
+
+	if len(deps) != len(expected) {
+		t.Fatalf("Expected %d dependencies, got %d: %+v", len(expected), len(deps), deps)
+	}
+	for i, want := range expected {
+		if !reflect.DeepEqual(deps[i], want) {
+			t.Errorf("Dependency %d: expected %+v, got %+v", i, want, deps[i])
+		}
+	}
+}
+
+func TestParseMixLockEntries_Details(t *testing.T) {
+	content := `%{
+  "phoenix": {:hex, :phoenix, "1.7.10", "abc", [:mix], [], "hexpm", "def"},
+  "plug": {:git, "https://github.com/elixir-plug/plug.git", "deadbeefcafe", [branch: "main"]},
+}`
+
+	entries, err := parseMixLockEntries(content)
+	if err != nil {
+		t.Fatalf("parseMixLockEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Repo != "hexpm" {
+		t.Errorf("Expected hex repo hexpm, got %q", entries[0].Repo)
+	}
+	if entries[1].Repo != "https://github.com/elixir-plug/plug.git" {
+		t.Errorf("Unexpected git repo %q", entries[1].Repo)
+	}
+	if entries[1].Ref != "main" || entries[1].Version != "deadbeefcafe" {
+		t.Errorf("Expected ref main at deadbeefcafe, got %q at %q", entries[1].Ref, entries[1].Version)
+	}
+}
+
+func TestParseMixLockEntries_Invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`%{"phoenix": {:hex, :phoenix, "1.7.10"`,
+		`["not", "a", "map"]`,
+		`%{"phoenix": {:hex}}`,
+	}
+
+	for _, content := range tests {
+		if _, err := parseMixLockEntries(content); err == nil {
+			t.Errorf("Expected error for %q, got nil", content)
+		}
+	}
+}
+
+func TestParseElixirDeps_UsesMixLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	lock := `%{"jason": {:hex, :jason, "1.4.1", "abc", [:mix], [], "hexpm", "def"}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "mix.lock"), []byte(lock), 0644); err != nil {
+		t.Fatalf("Failed to write mix.lock: %v", err)
+	}
+
+	deps, err := ParseElixirDeps(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseElixirDeps failed: %v", err)
+	}
+
+	found := false
+	for _, dep := range deps {
+		if dep.Name == "jason" && dep.Version == "1.4.1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected jason 1.4.1 in %+v", deps)
+	}
+}
@@ -0,0 +1,388 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packageJSON holds the parts of package.json that describe dependencies
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// directDependencies returns the names of every dependency declared in package.json
+func (p *packageJSON) directDependencies() map[string]bool {
+	direct := map[string]bool{}
+	for _, group := range []map[string]string{p.Dependencies, p.DevDependencies, p.OptionalDependencies, p.PeerDependencies} {
+		for name := range group {
+			direct[name] = true
+		}
+	}
+	return direct
+}
+
+// ParseNodeDeps parses dependencies from a JavaScript/TypeScript project,
+// resolving exact versions from package-lock.json, yarn.lock or pnpm-lock.yaml
+func ParseNodeDeps(projectRoot string) ([]Dependency, error) {
+	content, err := os.ReadFile(filepath.Join(projectRoot, "package.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	direct := pkg.directDependencies()
+
+	var deps []Dependency
+	switch {
+	case fileExists(filepath.Join(projectRoot, "package-lock.json")):
+		deps, err = ParsePackageLock(filepath.Join(projectRoot, "package-lock.json"))
+	case fileExists(filepath.Join(projectRoot, "npm-shrinkwrap.json")):
+		deps, err = ParsePackageLock(filepath.Join(projectRoot, "npm-shrinkwrap.json"))
+	case fileExists(filepath.Join(projectRoot, "yarn.lock")):
+		deps, err = ParseYarnLock(filepath.Join(projectRoot, "yarn.lock"))
+		for i := range deps {
+			deps[i].Dir = yarnInstallPath(projectRoot, deps[i].Name, deps[i].Version)
+		}
+	case fileExists(filepath.Join(projectRoot, "pnpm-lock.yaml")):
+		deps, err = ParsePnpmLock(filepath.Join(projectRoot, "pnpm-lock.yaml"))
+	default:
+		return nil, fmt.Errorf("no lockfile found (package-lock.json, yarn.lock or pnpm-lock.yaml); run your package manager's install first")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deps {
+		// Nested copies of a declared package are still transitive
+		nested := deps[i].Dir != "" && deps[i].Dir != "node_modules/"+deps[i].Name
+		deps[i].Transitive = !direct[deps[i].Name] || nested
+		if deps[i].Dir == "" && deps[i].Source != "yarn" {
+			deps[i].Dir = filepath.Join(projectRoot, "node_modules", deps[i].Name)
+		} else if deps[i].Dir != "" && !filepath.IsAbs(deps[i].Dir) {
+			deps[i].Dir = filepath.Join(projectRoot, deps[i].Dir)
+		}
+	}
+
+	return deps, nil
+}
+
+// packageLock holds the parts of an npm lockfile we need. Lockfile v2/v3 use
+// "packages" keyed by install path, v1 uses nested "dependencies".
+type packageLock struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	Packages        map[string]struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Link    bool   `json:"link"`
+	} `json:"packages"`
+	Dependencies map[string]packageLockV1Dep `json:"dependencies"`
+}
+
+type packageLockV1Dep struct {
+	Version      string                      `json:"version"`
+	Dependencies map[string]packageLockV1Dep `json:"dependencies"`
+}
+
+// ParsePackageLock reads an npm package-lock.json or npm-shrinkwrap.json
+func ParsePackageLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	deps := []Dependency{}
+	if len(lock.Packages) > 0 {
+		installPaths := make([]string, 0, len(lock.Packages))
+		for installPath := range lock.Packages {
+			installPaths = append(installPaths, installPath)
+		}
+		// Shallow install paths first so hoisted copies win
+		sort.Slice(installPaths, func(i, j int) bool {
+			di, dj := strings.Count(installPaths[i], "node_modules/"), strings.Count(installPaths[j], "node_modules/")
+			if di != dj {
+				return di < dj
+			}
+			return installPaths[i] < installPaths[j]
+		})
+
+		for _, installPath := range installPaths {
+			entry := lock.Packages[installPath]
+			idx := strings.LastIndex(installPath, "node_modules/")
+			if idx == -1 || entry.Link {
+				// The root project or a workspace link
+				continue
+			}
+			name := entry.Name
+			if name == "" {
+				name = installPath[idx+len("node_modules/"):]
+			}
+			deps = append(deps, Dependency{
+				Name:    name,
+				Version: entry.Version,
+				Type:    "npm",
+				Source:  "npm",
+				Dir:     installPath,
+			})
+		}
+		return dedupeDeps(deps), nil
+	}
+
+	var walk func(prefix string, group map[string]packageLockV1Dep)
+	walk = func(prefix string, group map[string]packageLockV1Dep) {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			installPath := prefix + "node_modules/" + name
+			deps = append(deps, Dependency{
+				Name:    name,
+				Version: group[name].Version,
+				Type:    "npm",
+				Source:  "npm",
+				Dir:     installPath,
+			})
+		}
+		for _, name := range names {
+			walk(prefix+"node_modules/"+name+"/", group[name].Dependencies)
+		}
+	}
+	walk("", lock.Dependencies)
+
+	return dedupeDeps(deps), nil
+}
+
+// yarnInstallPath finds where a version of a package is installed, as
+// yarn.lock doesn't record which versions are hoisted: node_modules/<name>,
+// or nested under the package that needs it. It's the hoisted path while
+// nothing is installed, and empty when the hoisted copy is another version
+// and no nested one matches.
+func yarnInstallPath(projectRoot, name, version string) string {
+	hoisted := "node_modules/" + name
+	installed, ok := installedVersion(filepath.Join(projectRoot, hoisted))
+	if !ok || installed == version {
+		return hoisted
+	}
+	for _, pattern := range []string{"node_modules/*/node_modules/", "node_modules/@*/*/node_modules/"} {
+		matches, _ := filepath.Glob(filepath.Join(projectRoot, pattern+name))
+		for _, match := range matches {
+			if installed, ok := installedVersion(match); ok && installed == version {
+				rel, _ := filepath.Rel(projectRoot, match)
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return ""
+}
+
+// installedVersion reads the version of the package installed in dir
+func installedVersion(dir string) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", false
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return "", false
+	}
+	return pkg.Version, true
+}
+
+var yarnVersionRegex = regexp.MustCompile(`^\s+version:?\s+"?([^"\s]+)"?`)
+
+// ParseYarnLock reads a Yarn classic (v1) or Berry (v2+) yarn.lock
+func ParseYarnLock(path string) ([]Dependency, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	deps := []Dependency{}
+	var names []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Entry headers are unindented lists of specifiers ending in a colon, e.g.
+		//   "@babel/core@^7.0.0", "@babel/core@^7.12.3":
+		//   "lodash@npm:^4.17.21":
+		if !strings.HasPrefix(line, " ") {
+			names = nil
+			if strings.HasPrefix(line, "__metadata") {
+				continue
+			}
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				spec = strings.Trim(strings.TrimSpace(spec), `"`)
+				if name := npmSpecName(spec); name != "" {
+					names = append(names, name)
+				}
+			}
+			continue
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+		if matches := yarnVersionRegex.FindStringSubmatch(line); len(matches) > 1 {
+			version := matches[1]
+			// Berry marks workspaces and the project itself with this version
+			if !strings.HasSuffix(version, "-use.local") {
+				deps = append(deps, Dependency{
+					Name:    names[0],
+					Version: version,
+					Type:    "npm",
+					Source:  "yarn",
+				})
+			}
+			names = nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return dedupeDeps(deps), nil
+}
+
+// npmSpecName extracts the package name from "name@range", "@scope/name@npm:range"
+// or "name@patch:name@range#..."
+func npmSpecName(spec string) string {
+	name, _ := splitNpmSpec(spec)
+	return name
+}
+
+// splitNpmSpec splits a spec at the first "@" that isn't a scope prefix
+func splitNpmSpec(spec string) (string, string) {
+	idx := strings.Index(spec[min(1, len(spec)):], "@")
+	if idx == -1 {
+		return spec, ""
+	}
+	idx++
+	return spec[:idx], spec[idx+1:]
+}
+
+// isNpmPackageName reports whether name looks like "pkg" or "@scope/pkg"
+func isNpmPackageName(name string) bool {
+	if strings.HasPrefix(name, "@") {
+		return strings.Count(name, "/") == 1
+	}
+	return name != "" && !strings.Contains(name, "/")
+}
+
+// pnpmLock holds the parts of pnpm-lock.yaml we need across lockfile versions
+type pnpmLock struct {
+	Packages map[string]struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"packages"`
+}
+
+// ParsePnpmLock reads a pnpm-lock.yaml (lockfile v5 through v9)
+func ParsePnpmLock(path string) ([]Dependency, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock pnpmLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	deps := []Dependency{}
+	for key, pkg := range lock.Packages {
+		name, version := parsePnpmPackageKey(key)
+		if pkg.Name != "" {
+			name = pkg.Name
+		}
+		if pkg.Version != "" {
+			version = pkg.Version
+		}
+		if name == "" || version == "" {
+			continue
+		}
+		deps = append(deps, Dependency{
+			Name:    name,
+			Version: version,
+			Type:    "npm",
+			Source:  "pnpm",
+		})
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+
+	return dedupeDeps(deps), nil
+}
+
+// parsePnpmPackageKey splits package keys such as "/lodash@4.17.21",
+// "lodash@4.17.21", "/@babel/core/7.23.0" (v5) or "react-dom@18.2.0(react@18.2.0)"
+func parsePnpmPackageKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if idx := strings.Index(key, "("); idx != -1 {
+		key = key[:idx]
+	}
+
+	name, version := splitNpmSpec(key)
+	if version == "" || !isNpmPackageName(name) {
+		// v5 keys separate name and version with a slash
+		idx := strings.LastIndex(key, "/")
+		if idx <= 0 {
+			return "", ""
+		}
+		name, version = key[:idx], key[idx+1:]
+	}
+
+	// Older lockfiles append peer dependencies as 1.0.0_react@18.2.0
+	if idx := strings.Index(version, "_"); idx != -1 {
+		version = version[:idx]
+	}
+	if !isNpmPackageName(name) {
+		return "", ""
+	}
+	return name, version
+}
+
+// dedupeDeps removes repeated name/version pairs, keeping the first occurrence
+func dedupeDeps(deps []Dependency) []Dependency {
+	seen := map[string]bool{}
+	result := []Dependency{}
+	for _, dep := range deps {
+		key := dep.Name + "@" + dep.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, dep)
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseNodeDeps_PackageLock(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseNodeDeps(root)
+	if err != nil {
+		t.Fatalf("ParseNodeDeps failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "@babel/core", Version: "7.23.0", Type: "npm", Source: "npm", Dir: filepath.Join(root, "node_modules/@babel/core")},
+		{Name: "body-parser", Version: "1.20.1", Type: "npm", Source: "npm", Dir: filepath.Join(root, "node_modules/body-parser"), Transitive: true},
+		{Name: "debug", Version: "4.3.4", Type: "npm", Source: "npm", Dir: filepath.Join(root, "node_modules/debug")},
+		{Name: "express", Version: "4.18.2", Type: "npm", Source: "npm", Dir: filepath.Join(root, "node_modules/express")},
+		{Name: "debug", Version: "2.6.9", Type: "npm", Source: "npm", Dir: filepath.Join(root, "node_modules/express/node_modules/debug"), Transitive: true},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParsePackageLock_V1(t *testing.T) {
+	deps, err := ParsePackageLock(filepath.Join("testdata", "node", "package-lock-v1.json"))
+	if err != nil {
+		t.Fatalf("ParsePackageLock failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "debug", Version: "4.3.4", Type: "npm", Source: "npm", Dir: "node_modules/debug"},
+		{Name: "express", Version: "4.18.2", Type: "npm", Source: "npm", Dir: "node_modules/express"},
+		{Name: "debug", Version: "2.6.9", Type: "npm", Source: "npm", Dir: "node_modules/express/node_modules/debug"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParseYarnLock(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []Dependency
+	}{
+		{
+			file: "yarn-classic.lock",
+			expected: []Dependency{
+				{Name: "@babel/core", Version: "7.23.0", Type: "npm", Source: "yarn"},
+				{Name: "debug", Version: "4.3.4", Type: "npm", Source: "yarn"},
+			},
+		},
+		{
+			file: "yarn-berry.lock",
+			expected: []Dependency{
+				{Name: "@babel/core", Version: "7.23.0", Type: "npm", Source: "yarn"},
+				{Name: "debug", Version: "4.3.4", Type: "npm", Source: "yarn"},
+				{Name: "resolve", Version: "1.22.8", Type: "npm", Source: "yarn"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			deps, err := ParseYarnLock(filepath.Join("testdata", "node", tt.file))
+			if err != nil {
+				t.Fatalf("ParseYarnLock failed: %v", err)
+			}
+			if !reflect.DeepEqual(deps, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, deps)
+			}
+		})
+	}
+}
+
+func TestParsePnpmLock(t *testing.T) {
+	deps, err := ParsePnpmLock(filepath.Join("testdata", "node", "pnpm-lock.yaml"))
+	if err != nil {
+		t.Fatalf("ParsePnpmLock failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "@babel/core", Version: "7.23.0", Type: "npm", Source: "pnpm"},
+		{Name: "express", Version: "4.18.2", Type: "npm", Source: "pnpm"},
+		{Name: "react-dom", Version: "18.2.0", Type: "npm", Source: "pnpm"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParsePnpmPackageKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		name    string
+		version string
+	}{
+		{"lodash@4.17.21", "lodash", "4.17.21"},
+		{"/lodash@4.17.21", "lodash", "4.17.21"},
+		{"/@babel/core@7.23.0", "@babel/core", "7.23.0"},
+		{"react-dom@18.2.0(react@18.2.0)", "react-dom", "18.2.0"},
+		{"/lodash/4.17.21", "lodash", "4.17.21"},
+		{"/@babel/core/7.23.0", "@babel/core", "7.23.0"},
+		{"/react-dom/18.2.0_react@18.2.0", "react-dom", "18.2.0"},
+		{"/@testing-library/react/14.0.0_react@18.2.0", "@testing-library/react", "14.0.0"},
+	}
+
+	for _, tt := range tests {
+		name, version := parsePnpmPackageKey(tt.key)
+		if name != tt.name || version != tt.version {
+			t.Errorf("parsePnpmPackageKey(%q) = %q, %q; expected %q, %q",
+				tt.key, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+func TestParseNodeDeps_YarnNestedVersions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"dependencies": {"debug": "^4.3.4", "express": "^4.18.2"}}`,
+		"yarn.lock": `debug@2.6.9:
+  version "2.6.9"
+
+debug@^4.3.4:
+  version "4.3.4"
+
+express@^4.18.2:
+  version "4.18.2"
+  dependencies:
+    debug "2.6.9"
+
+ms@2.0.0:
+  version "2.0.0"
+`,
+		"node_modules/debug/package.json":                      `{"name": "debug", "version": "4.3.4"}`,
+		"node_modules/express/package.json":                    `{"name": "express", "version": "4.18.2"}`,
+		"node_modules/express/node_modules/debug/package.json": `{"name": "debug", "version": "2.6.9"}`,
+		"node_modules/ms/package.json":                         `{"name": "ms", "version": "2.1.3"}`,
+	})
+
+	deps, err := ParseNodeDeps(root)
+	if err != nil {
+		t.Fatalf("ParseNodeDeps failed: %v", err)
+	}
+
+	// The version yarn didn't hoist is read from where it's nested, and one
+	// installed nowhere doesn't point at the hoisted copy of another version
+	expected := []Dependency{
+		{Name: "debug", Version: "2.6.9", Type: "npm", Source: "yarn", Dir: filepath.Join(root, "node_modules/express/node_modules/debug"), Transitive: true},
+		{Name: "debug", Version: "4.3.4", Type: "npm", Source: "yarn", Dir: filepath.Join(root, "node_modules/debug")},
+		{Name: "express", Version: "4.18.2", Type: "npm", Source: "yarn", Dir: filepath.Join(root, "node_modules/express")},
+		{Name: "ms", Version: "2.0.0", Type: "npm", Source: "yarn", Transitive: true},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
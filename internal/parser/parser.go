@@ -1,19 +1,40 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Dependency represents a single project dependency
 type Dependency struct {
-	Name    string
-	Version string
-	Type    string // "elixir", "gem"
+	Name        string
+	Version     string
+	Type        string   // "elixir", "gem", "go", "npm", "pypi", "crate", picks the docs backend (see ProjectTypeFor)
+	Source      string   // where the version was locked from, e.g. "hex", "git", "path"
+	Dir         string   // local source directory, when known (e.g. path dependencies)
+	Transitive  bool     // true when only required by other dependencies
+	ProjectRoot string   // root directory of the project that declares the dependency
+	Extras      []string // optional features requested, e.g. Python extras such as "socks"
+	Markers     string   // environment markers limiting where it's installed, e.g. `sys_platform == "win32"`
+	// Projects lists the sub-projects of a workspace using the dependency,
+	// relative to the workspace root. It's empty for a single project.
+	Projects []string
 }
 
-// Parser interface for reading different dependency files
+// languages are the pseudo-dependencies parsers add for the language of a
+// project, by dependency type
+var languages = map[string]string{"gem": "ruby", "elixir": "elixir", "go": "go"}
+
+// IsLanguage reports whether d is the language a project is written in,
+// such as Ruby, rather than a package it depends on
+func (d Dependency) IsLanguage() bool {
+	return d.Name != "" && languages[d.Type] == d.Name
+}
+
+// Parser reads the dependencies of one ecosystem's projects, see Register
 type Parser interface {
 	Parse(projectRoot string) ([]Dependency, error)
 	CanParse(projectRoot string) bool
@@ -25,28 +46,85 @@ type ProjectType string
 const (
 	ProjectTypeElixir  ProjectType = "elixir"
 	ProjectTypeRuby    ProjectType = "ruby"
+	ProjectTypeGo      ProjectType = "go"
+	ProjectTypeNode    ProjectType = "node"
+	ProjectTypePython  ProjectType = "python"
+	ProjectTypeRust    ProjectType = "rust"
 	ProjectTypeUnknown ProjectType = "unknown"
 )
 
-// ParseProjectDependencies detects the project type and parses dependencies
-func ParseProjectDependencies(dir string) ([]Dependency, ProjectType, error) {
+// ProjectTypeFor returns the project type whose docs backend handles
+// dependencies of the given type, e.g. "gem" for Ruby
+func ProjectTypeFor(depType string) ProjectType {
+	for _, e := range Ecosystems() {
+		if e.DepType == depType {
+			return e.ProjectType
+		}
+	}
+	return ProjectTypeUnknown
+}
+
+// ParseProjectDependencies detects the project around dir and parses the
+// dependencies of every manifest in it, e.g. both a mix.exs and a Gemfile.
+// When only some of them fail to parse, the dependencies of the others are
+// returned along with a *PartialError to warn about.
+func ParseProjectDependencies(dir string) ([]Dependency, []ProjectType, error) {
+	root, projectTypes, err := DetectProject(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deps := []Dependency{}
+	failed := []error{}
+	for _, projectType := range projectTypes {
+		projectDeps, err := parseProject(root, projectType)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("failed to parse the %s project: %w", projectType, err))
+			continue
+		}
+		deps = append(deps, projectDeps...)
+	}
+	if len(failed) == len(projectTypes) {
+		return nil, projectTypes, errors.Join(failed...)
+	}
+	if len(failed) > 0 {
+		return deps, projectTypes, &PartialError{Errs: failed}
+	}
+	return deps, projectTypes, nil
+}
+
+// PartialError holds the ecosystems of a project that failed to parse while
+// others parsed
+type PartialError struct {
+	Errs []error
+}
+
+func (e *PartialError) Error() string { return errors.Join(e.Errs...).Error() }
+
+func (e *PartialError) Unwrap() []error { return e.Errs }
+
+// parseProject parses the dependencies of the project in root
+func parseProject(root string, projectType ProjectType) ([]Dependency, error) {
+	e, ok := LookupEcosystem(projectType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported project type: %q", projectType)
+	}
+	deps, err := e.Parser.Parse(root)
+	return withProjectRoot(deps, root), err
+}
+
+// DetectProject walks up from dir to the closest directory with a supported
+// manifest and returns that project root and the types of its manifests
+func DetectProject(dir string) (string, []ProjectType, error) {
 	// Walk up the directory tree to find project root
 	currentDir, err := filepath.Abs(dir)
 	if err != nil {
-		return nil, ProjectTypeUnknown, err
+		return "", nil, err
 	}
 
 	for {
-		// Check for mix.exs (Elixir)
-		if fileExists(filepath.Join(currentDir, "mix.exs")) {
-			deps, err := ParseElixirDeps(currentDir)
-			return deps, ProjectTypeElixir, err
-		}
-
-		// Check for Gemfile (Ruby)
-		if fileExists(filepath.Join(currentDir, "Gemfile")) {
-			deps, err := ParseRubyDeps(currentDir)
-			return deps, ProjectTypeRuby, err
+		if projectTypes := detectDir(currentDir); len(projectTypes) > 0 {
+			return currentDir, projectTypes, nil
 		}
 
 		// Move up one directory
@@ -58,7 +136,32 @@ func ParseProjectDependencies(dir string) ([]Dependency, ProjectType, error) {
 		currentDir = parent
 	}
 
-	return nil, ProjectTypeUnknown, fmt.Errorf("no supported project file found (mix.exs or Gemfile)")
+	manifests := []string{}
+	for _, e := range Ecosystems() {
+		manifests = append(manifests, e.Manifests...)
+	}
+	last := len(manifests) - 1
+	return "", nil, fmt.Errorf("no supported project file found (%s or %s)", strings.Join(manifests[:last], ", "), manifests[last])
+}
+
+// detectDir returns the types of the projects whose manifests are in dir,
+// such as a Phoenix app with a Gemfile for its tooling
+func detectDir(dir string) []ProjectType {
+	projectTypes := []ProjectType{}
+	for _, e := range Ecosystems() {
+		if e.Parser.CanParse(dir) {
+			projectTypes = append(projectTypes, e.ProjectType)
+		}
+	}
+	return projectTypes
+}
+
+// withProjectRoot records the project root on every dependency
+func withProjectRoot(deps []Dependency, projectRoot string) []Dependency {
+	for i := range deps {
+		deps[i].ProjectRoot = projectRoot
+	}
+	return deps
 }
 
 func fileExists(path string) bool {
@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -30,11 +33,86 @@ func TestFileExists(t *testing.T) {
 func TestParseProjectDependencies_NoProject(t *testing.T) {
 	tmpDir := t.TempDir()
 	
-	_, projectType, err := ParseProjectDependencies(tmpDir)
+	_, projectTypes, err := ParseProjectDependencies(tmpDir)
 	if err == nil {
 		t.Errorf("Expected error for directory without project files, got nil")
 	}
-	if projectType != ProjectTypeUnknown {
-		t.Errorf("Expected ProjectTypeUnknown, got %s", projectType)
+	if len(projectTypes) != 0 {
+		t.Errorf("Expected no project types, got %v", projectTypes)
+	}
+}
+
+func TestParseProjectDependencies_PartialFailure(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n\nrequire golang.org/x/text v0.14.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "package.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, projectTypes, err := ParseProjectDependencies(root)
+	var partial *PartialError
+	if !errors.As(err, &partial) || len(partial.Errs) != 1 || !strings.Contains(err.Error(), "node project") {
+		t.Fatalf("Expected the node project to fail on its own, got %v", err)
+	}
+	if len(projectTypes) != 2 {
+		t.Errorf("Expected both project types, got %v", projectTypes)
+	}
+	found := false
+	for _, dep := range deps {
+		found = found || dep.Name == "golang.org/x/text"
+	}
+	if !found {
+		t.Errorf("Expected the go dependencies despite the node failure, got %+v", deps)
+	}
+
+	// Failing altogether is a plain error
+	if err := os.Remove(filepath.Join(root, "go.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ParseProjectDependencies(root); err == nil || errors.As(err, &partial) {
+		t.Errorf("Expected a plain error, got %v", err)
+	}
+}
+
+func TestDetectProject(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Gemfile"), []byte("source \"https://rubygems.org\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write Gemfile: %v", err)
+	}
+	subdir := filepath.Join(root, "lib", "tasks")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "package.json"), []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	dir, projectTypes, err := DetectProject(subdir)
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+	if want := []ProjectType{ProjectTypeRuby, ProjectTypeNode}; dir != root || !reflect.DeepEqual(projectTypes, want) {
+		t.Errorf("DetectProject = %s, %v, want %s, %v", dir, projectTypes, root, want)
+	}
+}
+
+func TestDependency_IsLanguage(t *testing.T) {
+	tests := []struct {
+		dep  Dependency
+		want bool
+	}{
+		{Dependency{Name: "ruby", Version: "3.3.0", Type: "gem"}, true},
+		{Dependency{Name: "elixir", Version: "1.16.0", Type: "elixir"}, true},
+		{Dependency{Name: "go", Version: "1.24.4", Type: "go"}, true},
+		{Dependency{Name: "rails", Version: "7.1.3", Type: "gem"}, false},
+		{Dependency{Name: "ruby", Version: "0.1.0", Type: "npm"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.dep.IsLanguage(); got != tt.want {
+			t.Errorf("IsLanguage(%s/%s) = %v, want %v", tt.dep.Type, tt.dep.Name, got, tt.want)
+		}
 	}
 }
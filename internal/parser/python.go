@@ -0,0 +1,486 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PythonPackage is a pinned Python distribution from a lockfile or requirements file
+type PythonPackage struct {
+	Name    string
+	Version string
+	Extras  []string // extras requested for the package, e.g. ["socks"]
+	Markers string   // environment markers, e.g. `python_version >= "3.8"`
+}
+
+// PythonRequirement is a single PEP 508 requirement such as
+// `requests[socks]>=2.31 ; python_version >= "3.8"`
+type PythonRequirement struct {
+	Name      string
+	Extras    []string
+	Specifier string
+	Markers   string
+}
+
+var (
+	pythonRequirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[([^\]]*)\])?\s*(?:\(?\s*([^;()]*?)\s*\)?)?\s*(?:;\s*(.*))?$`)
+	pythonNormalizeRegex   = regexp.MustCompile(`[-_.]+`)
+)
+
+// NormalizePythonName normalizes a distribution name as described in PEP 503
+func NormalizePythonName(name string) string {
+	return pythonNormalizeRegex.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// ParsePythonRequirement parses a PEP 508 requirement string
+func ParsePythonRequirement(line string) (PythonRequirement, bool) {
+	line = strings.TrimSpace(line)
+	matches := pythonRequirementRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return PythonRequirement{}, false
+	}
+
+	req := PythonRequirement{
+		Name:      matches[1],
+		Specifier: strings.TrimSpace(matches[3]),
+		Markers:   strings.TrimSpace(matches[4]),
+	}
+	for _, extra := range strings.Split(matches[2], ",") {
+		if extra = strings.TrimSpace(extra); extra != "" {
+			req.Extras = append(req.Extras, extra)
+		}
+	}
+	return req, true
+}
+
+// ParsePythonDeps parses dependencies from a Python project, reading pinned
+// versions from poetry.lock, uv.lock, Pipfile.lock or requirements*.txt
+func ParsePythonDeps(projectRoot string) ([]Dependency, error) {
+	var packages []PythonPackage
+	var err error
+	switch {
+	case fileExists(filepath.Join(projectRoot, "poetry.lock")):
+		packages, err = ParsePoetryLock(filepath.Join(projectRoot, "poetry.lock"))
+	case fileExists(filepath.Join(projectRoot, "uv.lock")):
+		packages, err = ParseUvLock(filepath.Join(projectRoot, "uv.lock"))
+	case fileExists(filepath.Join(projectRoot, "Pipfile.lock")):
+		packages, err = ParsePipfileLock(filepath.Join(projectRoot, "Pipfile.lock"))
+	default:
+		packages, err = parseRequirementsFiles(projectRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	direct, err := pythonDirectDependencies(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Installed distributions live in the project's virtualenv, if there is one
+	sitePackages := findSitePackages(projectRoot)
+
+	deps := []Dependency{}
+	for _, pkg := range packages {
+		declared, isDirect := direct[NormalizePythonName(pkg.Name)]
+		// Lockfiles such as poetry.lock leave out the extras the project asks for
+		if len(pkg.Extras) == 0 {
+			pkg.Extras = declared.Extras
+		}
+		if pkg.Markers == "" {
+			pkg.Markers = declared.Markers
+		}
+		deps = append(deps, Dependency{
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Type:       "pypi",
+			Source:     "pypi",
+			Dir:        sitePackages,
+			Transitive: direct != nil && !isDirect,
+			Extras:     pkg.Extras,
+			Markers:    pkg.Markers,
+		})
+	}
+	return deps, nil
+}
+
+// poetryLock holds the [[package]] entries of a poetry.lock
+type poetryLock struct {
+	Package []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Markers string `toml:"markers"`
+	} `toml:"package"`
+}
+
+// uvLock holds the [[package]] entries of a uv.lock
+type uvLock struct {
+	Package []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  struct {
+			Editable string `toml:"editable"`
+			Virtual  string `toml:"virtual"`
+		} `toml:"source"`
+		Dependencies []struct {
+			Name   string   `toml:"name"`
+			Extra  []string `toml:"extra"`
+			Marker string   `toml:"marker"`
+		} `toml:"dependencies"`
+	} `toml:"package"`
+}
+
+// ParsePoetryLock reads a poetry.lock file
+func ParsePoetryLock(path string) ([]PythonPackage, error) {
+	var lock poetryLock
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	packages := []PythonPackage{}
+	for _, pkg := range lock.Package {
+		packages = append(packages, PythonPackage{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Markers: pkg.Markers,
+		})
+	}
+	return packages, nil
+}
+
+// ParseUvLock reads a uv.lock file, skipping the project itself
+func ParseUvLock(path string) ([]PythonPackage, error) {
+	var lock uvLock
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	packages := []PythonPackage{}
+	requested := map[string]PythonPackage{}
+	for _, pkg := range lock.Package {
+		// uv records the project and workspace members as editable/virtual
+		// sources, along with the extras and markers they request
+		if pkg.Source.Editable != "" || pkg.Source.Virtual != "" {
+			for _, dep := range pkg.Dependencies {
+				requested[NormalizePythonName(dep.Name)] = PythonPackage{Extras: dep.Extra, Markers: dep.Marker}
+			}
+			continue
+		}
+		packages = append(packages, PythonPackage{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+		})
+	}
+
+	for i, pkg := range packages {
+		if req, ok := requested[NormalizePythonName(pkg.Name)]; ok {
+			packages[i].Extras = req.Extras
+			packages[i].Markers = req.Markers
+		}
+	}
+	return packages, nil
+}
+
+// pipfileLock holds the default and develop package groups of a Pipfile.lock
+type pipfileLock struct {
+	Default map[string]pipfileLockPackage `json:"default"`
+	Develop map[string]pipfileLockPackage `json:"develop"`
+}
+
+type pipfileLockPackage struct {
+	Version string   `json:"version"`
+	Extras  []string `json:"extras"`
+	Markers string   `json:"markers"`
+}
+
+// ParsePipfileLock reads a Pipfile.lock file
+func ParsePipfileLock(path string) ([]PythonPackage, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock pipfileLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	packages := []PythonPackage{}
+	seen := map[string]bool{}
+	for _, group := range []map[string]pipfileLockPackage{lock.Default, lock.Develop} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			pkg := group[name]
+			if seen[name] || !strings.HasPrefix(pkg.Version, "==") {
+				// Already listed, or a VCS/path package without a pinned version
+				continue
+			}
+			seen[name] = true
+			packages = append(packages, PythonPackage{
+				Name:    name,
+				Version: strings.TrimPrefix(pkg.Version, "=="),
+				Extras:  pkg.Extras,
+				Markers: pkg.Markers,
+			})
+		}
+	}
+	return packages, nil
+}
+
+// ParseRequirementsFile reads a pip requirements file, keeping only pinned
+// (==) requirements and following -r includes
+func ParseRequirementsFile(path string) ([]PythonPackage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	packages := []PythonPackage{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, " #"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if included, found := requirementsInclude(line); found {
+			nested, err := ParseRequirementsFile(filepath.Join(filepath.Dir(path), included))
+			if err != nil {
+				return nil, err
+			}
+			packages = append(packages, nested...)
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			// Other pip options such as --index-url or -e. Constraint files
+			// (-c) only restrict versions without installing anything, so
+			// they're skipped too
+			continue
+		}
+
+		req, ok := ParsePythonRequirement(line)
+		if !ok {
+			continue
+		}
+		version, pinned := strings.CutPrefix(req.Specifier, "==")
+		if !pinned {
+			continue
+		}
+		packages = append(packages, PythonPackage{
+			Name:    req.Name,
+			Version: strings.TrimSpace(version),
+			Extras:  req.Extras,
+			Markers: req.Markers,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return packages, nil
+}
+
+// requirementsInclude returns the file named by a -r option in any of the
+// forms pip accepts: "-r file", "-rfile", "--requirement file" and
+// "--requirement=file"
+func requirementsInclude(line string) (string, bool) {
+	var included string
+	if rest, found := strings.CutPrefix(line, "--requirement"); found {
+		if !strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, "\t") {
+			return "", false
+		}
+		included = strings.TrimPrefix(strings.TrimSpace(rest), "=")
+	} else if rest, found := strings.CutPrefix(line, "-r"); found {
+		included = rest
+	} else {
+		return "", false
+	}
+	included = strings.TrimSpace(included)
+	return included, included != ""
+}
+
+// parseRequirementsFiles reads every requirements*.txt in the project root
+func parseRequirementsFiles(projectRoot string) ([]PythonPackage, error) {
+	paths, err := filepath.Glob(filepath.Join(projectRoot, "requirements*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no lockfile found (poetry.lock, uv.lock, Pipfile.lock or requirements.txt)")
+	}
+
+	packages := []PythonPackage{}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		filePackages, err := ParseRequirementsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range filePackages {
+			key := NormalizePythonName(pkg.Name) + "==" + pkg.Version
+			if !seen[key] {
+				seen[key] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	return packages, nil
+}
+
+// pyprojectTOML holds the dependency declarations of a pyproject.toml
+type pyprojectTOML struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	DependencyGroups map[string][]interface{} `toml:"dependency-groups"`
+	Tool             struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// pipfile holds the package groups of a Pipfile
+type pipfile struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
+
+// pythonDirectDependencies returns the requirements the project declares
+// itself by normalized name, or nil when there is no manifest declaring any
+// to tell direct from transitive
+func pythonDirectDependencies(projectRoot string) (map[string]PythonRequirement, error) {
+	direct := map[string]PythonRequirement{}
+	addRequirement := func(line string) {
+		if req, ok := ParsePythonRequirement(line); ok {
+			direct[NormalizePythonName(req.Name)] = req
+		}
+	}
+	addTable := func(group map[string]interface{}) {
+		for name, value := range group {
+			if name != "python" {
+				direct[NormalizePythonName(name)] = tableRequirement(name, value)
+			}
+		}
+	}
+
+	switch {
+	case fileExists(filepath.Join(projectRoot, "pyproject.toml")):
+		var pyproject pyprojectTOML
+		if _, err := toml.DecodeFile(filepath.Join(projectRoot, "pyproject.toml"), &pyproject); err != nil {
+			return nil, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+		}
+		for _, line := range pyproject.Project.Dependencies {
+			addRequirement(line)
+		}
+		for _, group := range pyproject.Project.OptionalDependencies {
+			for _, line := range group {
+				addRequirement(line)
+			}
+		}
+		for _, group := range pyproject.DependencyGroups {
+			for _, item := range group {
+				// Groups may also contain {include-group = "..."} tables
+				if line, ok := item.(string); ok {
+					addRequirement(line)
+				}
+			}
+		}
+		poetry := pyproject.Tool.Poetry
+		addTable(poetry.Dependencies)
+		addTable(poetry.DevDependencies)
+		for _, group := range poetry.Group {
+			addTable(group.Dependencies)
+		}
+	case fileExists(filepath.Join(projectRoot, "Pipfile")):
+		var pf pipfile
+		if _, err := toml.DecodeFile(filepath.Join(projectRoot, "Pipfile"), &pf); err != nil {
+			return nil, fmt.Errorf("failed to parse Pipfile: %w", err)
+		}
+		addTable(pf.Packages)
+		addTable(pf.DevPackages)
+	}
+
+	// A pyproject.toml holding only tool settings, or requirements files that
+	// list everything installed, can't tell direct from transitive
+	if len(direct) == 0 {
+		return nil, nil
+	}
+	return direct, nil
+}
+
+// tableRequirement reads a Poetry or Pipfile requirement, either a version
+// string or a table such as { version = "^2.31", extras = ["socks"] }
+func tableRequirement(name string, value interface{}) PythonRequirement {
+	req := PythonRequirement{Name: name}
+	switch value := value.(type) {
+	case string:
+		req.Specifier = value
+	case map[string]interface{}:
+		req.Specifier, _ = value["version"].(string)
+		req.Markers, _ = value["markers"].(string)
+		extras, _ := value["extras"].([]interface{})
+		for _, extra := range extras {
+			if extra, ok := extra.(string); ok {
+				req.Extras = append(req.Extras, extra)
+			}
+		}
+	}
+	return req
+}
+
+// findSitePackages locates the site-packages directory of the active
+// virtualenv, or of a .venv/venv/env directory in the project root
+func findSitePackages(projectRoot string) string {
+	candidates := []string{}
+	if venv := os.Getenv("VIRTUAL_ENV"); venv != "" {
+		candidates = append(candidates, venv)
+	}
+	for _, name := range []string{".venv", "venv", "env"} {
+		candidates = append(candidates, filepath.Join(projectRoot, name))
+	}
+
+	for _, venv := range candidates {
+		matches, _ := filepath.Glob(filepath.Join(venv, "lib", "python*", "site-packages"))
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			return matches[len(matches)-1]
+		}
+		// Windows virtualenvs
+		if windows := filepath.Join(venv, "Lib", "site-packages"); fileExists(windows) {
+			return windows
+		}
+	}
+	return ""
+}
+
+// hasPythonManifest reports whether dir contains a Python project manifest
+func hasPythonManifest(dir string) bool {
+	if fileExists(filepath.Join(dir, "pyproject.toml")) || fileExists(filepath.Join(dir, "Pipfile")) {
+		return true
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "requirements*.txt"))
+	return len(matches) > 0
+}
@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePythonRequirement(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected PythonRequirement
+	}{
+		{"requests", PythonRequirement{Name: "requests"}},
+		{"requests==2.31.0", PythonRequirement{Name: "requests", Specifier: "==2.31.0"}},
+		{
+			`requests[socks, security] >= 2.31 ; python_version >= "3.8"`,
+			PythonRequirement{Name: "requests", Extras: []string{"socks", "security"}, Specifier: ">= 2.31", Markers: `python_version >= "3.8"`},
+		},
+		{"PySocks (>=1.5.6,!=1.5.7)", PythonRequirement{Name: "PySocks", Specifier: ">=1.5.6,!=1.5.7"}},
+	}
+
+	for _, tt := range tests {
+		req, ok := ParsePythonRequirement(tt.line)
+		if !ok {
+			t.Errorf("ParsePythonRequirement(%q) failed", tt.line)
+			continue
+		}
+		if !reflect.DeepEqual(req, tt.expected) {
+			t.Errorf("ParsePythonRequirement(%q) = %+v, expected %+v", tt.line, req, tt.expected)
+		}
+	}
+}
+
+func TestNormalizePythonName(t *testing.T) {
+	if got := NormalizePythonName("Foo.Bar__baz-qux"); got != "foo-bar-baz-qux" {
+		t.Errorf("Expected foo-bar-baz-qux, got %q", got)
+	}
+}
+
+func TestParsePythonLockfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(string) ([]PythonPackage, error)
+		file     string
+		expected []PythonPackage
+	}{
+		{
+			name:  "poetry",
+			parse: ParsePoetryLock,
+			file:  "poetry.lock",
+			expected: []PythonPackage{
+				{Name: "certifi", Version: "2024.2.2"},
+				{Name: "colorama", Version: "0.4.6", Markers: `sys_platform == "win32"`},
+				{Name: "requests", Version: "2.31.0"},
+			},
+		},
+		{
+			name:  "uv",
+			parse: ParseUvLock,
+			file:  "uv.lock",
+			expected: []PythonPackage{
+				{Name: "colorama", Version: "0.4.6", Markers: "sys_platform == 'win32'"},
+				{Name: "requests", Version: "2.31.0", Extras: []string{"socks"}},
+			},
+		},
+		{
+			name:  "pipenv",
+			parse: ParsePipfileLock,
+			file:  "Pipfile.lock",
+			expected: []PythonPackage{
+				{Name: "requests", Version: "2.31.0", Extras: []string{"socks"}, Markers: "python_version >= '3.7'"},
+				{Name: "pytest", Version: "8.0.0"},
+			},
+		},
+		{
+			name:  "requirements",
+			parse: ParseRequirementsFile,
+			file:  "requirements.txt",
+			expected: []PythonPackage{
+				{Name: "certifi", Version: "2024.2.2"},
+				{Name: "requests", Version: "2.31.0", Extras: []string{"socks", "security"}, Markers: `python_version >= "3.8"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packages, err := tt.parse(filepath.Join("testdata", "python", tt.file))
+			if err != nil {
+				t.Fatalf("Parsing %s failed: %v", tt.file, err)
+			}
+			if !reflect.DeepEqual(packages, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, packages)
+			}
+		})
+	}
+}
+
+func TestParseRequirementsFile_Includes(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "short option", line: "-r base.txt"},
+		{name: "short option attached", line: "-rbase.txt"},
+		{name: "long option", line: "--requirement base.txt"},
+		{name: "long option with equals", line: "--requirement=base.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, map[string]string{
+				"base.txt":         "certifi==2024.2.2\n",
+				"constraints.txt":  "idna==3.6\n",
+				"requirements.txt": tt.line + "\n-c constraints.txt\n--constraint=constraints.txt\nrequests==2.31.0\n",
+			})
+
+			packages, err := ParseRequirementsFile(filepath.Join(root, "requirements.txt"))
+			if err != nil {
+				t.Fatalf("ParseRequirementsFile failed: %v", err)
+			}
+			// Constraint files only restrict versions, so idna isn't listed
+			expected := []PythonPackage{
+				{Name: "certifi", Version: "2024.2.2"},
+				{Name: "requests", Version: "2.31.0"},
+			}
+			if !reflect.DeepEqual(packages, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, packages)
+			}
+		})
+	}
+}
+
+func TestParsePythonDeps_DirectAndVirtualenv(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("testdata", "python"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	venv := t.TempDir()
+	sitePackages := filepath.Join(venv, "lib", "python3.12", "site-packages")
+	if err := os.MkdirAll(sitePackages, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VIRTUAL_ENV", venv)
+
+	deps, err := ParsePythonDeps(root)
+	if err != nil {
+		t.Fatalf("ParsePythonDeps failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "certifi", Version: "2024.2.2", Type: "pypi", Source: "pypi", Dir: sitePackages, Transitive: true},
+		{Name: "colorama", Version: "0.4.6", Type: "pypi", Source: "pypi", Dir: sitePackages, Markers: `sys_platform == "win32"`},
+		// The extras come from pyproject.toml, as poetry.lock doesn't record them
+		{Name: "requests", Version: "2.31.0", Type: "pypi", Source: "pypi", Dir: sitePackages, Extras: []string{"socks"}},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParsePythonDeps_ToolOnlyPyproject(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VIRTUAL_ENV", "")
+	if err := os.WriteFile(filepath.Join(root, "pyproject.toml"), []byte("[tool.black]\nline-length = 100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "requirements.txt"), []byte("requests==2.31.0\ncertifi==2024.2.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParsePythonDeps(root)
+	if err != nil {
+		t.Fatalf("ParsePythonDeps failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %+v", deps)
+	}
+	for _, dep := range deps {
+		if dep.Transitive {
+			t.Errorf("Expected %s to be direct without declared dependencies, got %+v", dep.Name, dep)
+		}
+	}
+}
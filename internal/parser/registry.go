@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// Ecosystem is a kind of project pudding reads dependencies from, such as
+// Ruby projects and their gems
+type Ecosystem struct {
+	ProjectType ProjectType
+	DepType     string   // Type of its dependencies, e.g. "gem"
+	Manifests   []string // files marking a project, named when none is found
+	Lockfiles   []string // files pinning dependency versions, see coveredByParent
+	Parser      Parser
+}
+
+// manifestParser parses the projects holding one of its manifests
+type manifestParser struct {
+	manifests []string
+	parse     func(projectRoot string) ([]Dependency, error)
+}
+
+func (p manifestParser) CanParse(projectRoot string) bool {
+	for _, manifest := range p.manifests {
+		if fileExists(filepath.Join(projectRoot, manifest)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p manifestParser) Parse(projectRoot string) ([]Dependency, error) {
+	return p.parse(projectRoot)
+}
+
+// pythonParser also recognizes requirements files with a suffix, such as
+// requirements-dev.txt
+type pythonParser struct{}
+
+func (pythonParser) CanParse(projectRoot string) bool { return hasPythonManifest(projectRoot) }
+
+func (pythonParser) Parse(projectRoot string) ([]Dependency, error) {
+	return ParsePythonDeps(projectRoot)
+}
+
+var (
+	registryMu sync.RWMutex
+	// ecosystems in detection order: when manifests share a directory, the
+	// first one is the project's main type
+	ecosystems = []Ecosystem{
+		{ProjectTypeElixir, "elixir", []string{"mix.exs"}, []string{"mix.lock"},
+			manifestParser{[]string{"mix.exs"}, ParseElixirDeps}},
+		{ProjectTypeRuby, "gem", []string{"Gemfile"}, []string{"Gemfile.lock"},
+			manifestParser{[]string{"Gemfile"}, ParseRubyDeps}},
+		{ProjectTypeGo, "go", []string{"go.mod"}, []string{"go.sum"},
+			manifestParser{[]string{"go.mod"}, ParseGoDeps}},
+		{ProjectTypeNode, "npm", []string{"package.json"}, []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml"},
+			manifestParser{[]string{"package.json"}, ParseNodeDeps}},
+		{ProjectTypeRust, "crate", []string{"Cargo.toml"}, []string{"Cargo.lock"},
+			manifestParser{[]string{"Cargo.toml"}, ParseRustDeps}},
+		{ProjectTypePython, "pypi", []string{"pyproject.toml", "Pipfile", "requirements.txt"}, []string{"poetry.lock", "uv.lock", "Pipfile.lock"},
+			pythonParser{}},
+	}
+)
+
+// Register adds an ecosystem, detected after the ones already registered. It
+// fails when the project or dependency type is taken.
+func Register(e Ecosystem) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if e.ProjectType == "" || e.ProjectType == ProjectTypeUnknown || e.DepType == "" || e.Parser == nil {
+		return fmt.Errorf("ecosystem %q needs a project type, a dependency type and a parser", e.ProjectType)
+	}
+	for _, registered := range ecosystems {
+		if registered.ProjectType == e.ProjectType || registered.DepType == e.DepType {
+			return fmt.Errorf("ecosystem %q is already registered", e.ProjectType)
+		}
+	}
+	ecosystems = append(ecosystems, e)
+	return nil
+}
+
+// Ecosystems returns the registered ecosystems in detection order
+func Ecosystems() []Ecosystem {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Ecosystem(nil), ecosystems...)
+}
+
+// LookupEcosystem returns the ecosystem of a project type
+func LookupEcosystem(projectType ProjectType) (Ecosystem, bool) {
+	for _, e := range Ecosystems() {
+		if e.ProjectType == projectType {
+			return e, true
+		}
+	}
+	return Ecosystem{}, false
+}
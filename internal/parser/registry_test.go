@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// registerForTest registers e and removes it again when the test ends
+func registerForTest(t *testing.T, e Ecosystem) {
+	t.Helper()
+	registered := Ecosystems()
+	if err := Register(e); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		ecosystems = registered
+	})
+}
+
+func TestRegister(t *testing.T) {
+	registerForTest(t, Ecosystem{
+		ProjectType: "ocaml",
+		DepType:     "opam",
+		Manifests:   []string{"dune-project"},
+		Lockfiles:   []string{"project.opam.locked"},
+		Parser: manifestParser{[]string{"dune-project"}, func(projectRoot string) ([]Dependency, error) {
+			return []Dependency{{Name: "lwt", Version: "5.7.0", Type: "opam"}}, nil
+		}},
+	})
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"dune-project": "(lang dune 3.0)\n", "Gemfile": ""})
+
+	_, projectTypes, err := DetectProject(root)
+	if err != nil {
+		t.Fatalf("DetectProject failed: %v", err)
+	}
+	if want := []ProjectType{ProjectTypeRuby, "ocaml"}; !reflect.DeepEqual(projectTypes, want) {
+		t.Errorf("DetectProject types = %v, want %v", projectTypes, want)
+	}
+	if ProjectTypeFor("opam") != "ocaml" {
+		t.Errorf("ProjectTypeFor(opam) = %s", ProjectTypeFor("opam"))
+	}
+
+	if err := os.Remove(filepath.Join(root, "Gemfile")); err != nil {
+		t.Fatal(err)
+	}
+	deps, _, err := ParseProjectDependencies(root)
+	if err != nil || len(deps) != 1 || deps[0].Name != "lwt" || deps[0].ProjectRoot != root {
+		t.Errorf("ParseProjectDependencies = %+v, %v", deps, err)
+	}
+
+	_, _, err = DetectProject(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "requirements.txt or dune-project") {
+		t.Errorf("Expected the registered manifest in the error, got %v", err)
+	}
+}
+
+func TestRegister_Conflicts(t *testing.T) {
+	parser := manifestParser{[]string{"x"}, nil}
+	for _, e := range []Ecosystem{
+		{ProjectType: ProjectTypeRuby, DepType: "ruby2", Parser: parser},
+		{ProjectType: "ruby2", DepType: "gem", Parser: parser},
+		{ProjectType: "ocaml", DepType: "opam"},
+		{DepType: "opam", Parser: parser},
+	} {
+		if err := Register(e); err == nil {
+			t.Errorf("Expected registering %+v to fail", e)
+		}
+	}
+}
@@ -3,15 +3,44 @@ package parser
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-// ParseRubyDeps parses dependencies from a Ruby project using bundler
+// ParseRubyDeps parses dependencies from a Ruby project. It reads Gemfile.lock
+// directly and only falls back to `bundle list` when there is no lockfile.
 func ParseRubyDeps(projectRoot string) ([]Dependency, error) {
-	// First check if Gemfile.lock exists
-	// If not, we might need to run bundle install first
-	
+	lockPath := filepath.Join(projectRoot, "Gemfile.lock")
+	if !fileExists(lockPath) {
+		return parseRubyDepsWithBundler(projectRoot)
+	}
+
+	lock, err := ParseGemfileLock(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := lock.Deps()
+
+	// Prefer the Ruby version recorded in the lockfile over the one on PATH
+	rubyVersion := lock.RubyVersion
+	if rubyVersion == "" {
+		rubyVersion, _ = getRubyVersion()
+	}
+	if rubyVersion != "" {
+		deps = append([]Dependency{{
+			Name:    "ruby",
+			Version: rubyVersion,
+			Type:    "gem",
+		}}, deps...)
+	}
+
+	return deps, nil
+}
+
+// parseRubyDepsWithBundler lists dependencies using `bundle list`
+func parseRubyDepsWithBundler(projectRoot string) ([]Dependency, error) {
 	// Use bundle list to get dependencies with versions
 	cmd := exec.Command("bundle", "list")
 	cmd.Dir = projectRoot
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// cargoManifest holds the parts of a Cargo.toml that declare dependencies
+type cargoManifest struct {
+	Package struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Dependencies      map[string]interface{} `toml:"dependencies"`
+	DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+	Target            map[string]struct {
+		Dependencies      map[string]interface{} `toml:"dependencies"`
+		DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+		BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+	} `toml:"target"`
+	Workspace struct {
+		Members      []string               `toml:"members"`
+		Exclude      []string               `toml:"exclude"`
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+// cargoLock holds the [[package]] entries of a Cargo.lock
+type cargoLock struct {
+	Package []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  string `toml:"source"`
+	} `toml:"package"`
+}
+
+// ParseRustDeps parses dependencies from a Cargo package or workspace
+func ParseRustDeps(projectRoot string) ([]Dependency, error) {
+	root, err := readCargoManifest(filepath.Join(projectRoot, "Cargo.toml"))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []*cargoManifest{root}
+	memberPaths, err := cargoWorkspaceMembers(projectRoot, root)
+	if err != nil {
+		return nil, err
+	}
+	for _, memberPath := range memberPaths {
+		member, err := readCargoManifest(filepath.Join(memberPath, "Cargo.toml"))
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, member)
+	}
+
+	// Workspace members are the project itself, everything they declare is direct
+	members := map[string]bool{}
+	direct := map[string]bool{}
+	for _, manifest := range manifests {
+		if manifest.Package.Name != "" {
+			members[manifest.Package.Name] = true
+		}
+		for name := range manifest.declaredDependencies() {
+			direct[name] = true
+		}
+	}
+
+	lockPath := filepath.Join(projectRoot, "Cargo.lock")
+	if !fileExists(lockPath) {
+		return nil, fmt.Errorf("no Cargo.lock found; run `cargo generate-lockfile` first")
+	}
+	var lock cargoLock
+	if _, err := toml.DecodeFile(lockPath, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", lockPath, err)
+	}
+
+	deps := []Dependency{}
+	for _, pkg := range lock.Package {
+		if pkg.Source == "" && members[pkg.Name] {
+			continue
+		}
+		source := "crates.io"
+		switch {
+		case pkg.Source == "":
+			source = "path"
+		case strings.HasPrefix(pkg.Source, "git+"):
+			source = "git"
+		}
+		deps = append(deps, Dependency{
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Type:       "crate",
+			Source:     source,
+			Transitive: !direct[pkg.Name],
+		})
+	}
+
+	return deps, nil
+}
+
+func readCargoManifest(path string) (*cargoManifest, error) {
+	var manifest cargoManifest
+	if _, err := toml.DecodeFile(path, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// declaredDependencies returns the package names of every dependency the
+// manifest declares, resolving renames such as `foo = { package = "bar" }`
+func (m *cargoManifest) declaredDependencies() map[string]bool {
+	groups := []map[string]interface{}{m.Dependencies, m.DevDependencies, m.BuildDependencies, m.Workspace.Dependencies}
+	for _, target := range m.Target {
+		groups = append(groups, target.Dependencies, target.DevDependencies, target.BuildDependencies)
+	}
+
+	names := map[string]bool{}
+	for _, group := range groups {
+		for key, spec := range group {
+			name := key
+			if table, ok := spec.(map[string]interface{}); ok {
+				if pkg, ok := table["package"].(string); ok && pkg != "" {
+					name = pkg
+				}
+			}
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// cargoWorkspaceMembers expands the workspace member globs into directories
+// containing a Cargo.toml
+func cargoWorkspaceMembers(projectRoot string, root *cargoManifest) ([]string, error) {
+	excluded := map[string]bool{}
+	for _, exclude := range root.Workspace.Exclude {
+		excluded[filepath.Join(projectRoot, exclude)] = true
+	}
+
+	members := []string{}
+	seen := map[string]bool{}
+	for _, pattern := range root.Workspace.Members {
+		matches, err := filepath.Glob(filepath.Join(projectRoot, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace member %q: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if excluded[match] || seen[match] || filepath.Clean(match) == filepath.Clean(projectRoot) {
+				continue
+			}
+			if !fileExists(filepath.Join(match, "Cargo.toml")) {
+				continue
+			}
+			seen[match] = true
+			members = append(members, match)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRustDeps_Workspace(t *testing.T) {
+	deps, err := ParseRustDeps(filepath.Join("testdata", "rust"))
+	if err != nil {
+		t.Fatalf("ParseRustDeps failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "clap", Version: "4.5.4", Type: "crate", Source: "crates.io"},
+		{Name: "itoa", Version: "1.0.11", Type: "crate", Source: "crates.io", Transitive: true},
+		{Name: "libc", Version: "0.2.153", Type: "crate", Source: "git"},
+		{Name: "serde", Version: "1.0.197", Type: "crate", Source: "crates.io"},
+		{Name: "serde_json", Version: "1.0.115", Type: "crate", Source: "crates.io"},
+	}
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestParseRustDeps_MissingLockfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifest := "[package]\nname = \"solo\"\nversion = \"0.1.0\"\n\n[dependencies]\nanyhow = \"1\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "Cargo.toml"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.toml: %v", err)
+	}
+
+	if _, err := ParseRustDeps(tmpDir); err == nil {
+		t.Error("Expected error for missing Cargo.lock, got nil")
+	}
+}
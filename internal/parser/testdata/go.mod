@@ -0,0 +1,25 @@
+module example.com/app
+
+go 1.23
+
+toolchain go1.24.4
+
+require github.com/stretchr/testify v1.11.1
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	golang.org/x/text v0.24.0 // indirect
+	example.com/forked v1.0.0
+	example.com/local v0.0.0-00010101000000-000000000000
+	example.com/excluded v1.2.0
+)
+
+replace example.com/forked v1.0.0 => github.com/me/forked v1.0.1
+
+replace (
+	example.com/local => ../local
+)
+
+exclude example.com/excluded v1.2.0
+
+retract v0.9.0 // published by mistake
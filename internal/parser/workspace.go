@@ -0,0 +1,408 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Project is one project of a workspace. A directory with manifests of
+// several ecosystems, such as a Rails app with a package.json, holds one
+// project per ecosystem.
+type Project struct {
+	Dir  string // absolute path of the directory holding the manifest
+	Path string // relative to the workspace root, "." for the root itself
+	Type ProjectType
+	// Umbrella is the Path of the Elixir umbrella an app belongs to. Apps
+	// share the umbrella's mix.lock, so their dependencies come from it.
+	Umbrella string
+}
+
+// Workspace is a repository holding one or more projects, such as a
+// monorepo, a Rails app with an Elixir service or an Elixir umbrella
+type Workspace struct {
+	Root         string // repository root, or the project's directory when there's only one
+	Projects     []Project
+	Dependencies []Dependency
+	// Skipped holds the errors of projects other than the closest one that
+	// failed to parse, which are left out rather than failing the workspace
+	Skipped []error
+}
+
+// maxWorkspaceDepth bounds how deep below the repository root projects are
+// looked for
+const maxWorkspaceDepth = 4
+
+// ignoredDirs hold installed dependencies, build output or fixtures rather
+// than projects
+var ignoredDirs = map[string]bool{
+	"node_modules": true, "deps": true, "_build": true, "vendor": true, "target": true,
+	"testdata": true, "tmp": true, "dist": true, "build": true, "venv": true, "__pycache__": true,
+}
+
+// ParseWorkspace finds every project in the repository around dir and merges
+// their dependencies, recording which projects use each one. With project
+// set, a path relative to dir or to the repository root, only the
+// dependencies of the projects in that directory are parsed.
+func ParseWorkspace(dir, project string) (*Workspace, error) {
+	ws, err := DiscoverWorkspace(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Projects in the required directory fail the workspace when none of
+	// them parses, e.g. a Phoenix app still parses with a broken package.json
+	projects := ws.Projects
+	required, _, err := DetectProject(dir)
+	if err != nil {
+		return nil, err
+	}
+	if project != "" {
+		if projects, err = ws.findProject(dir, project); err != nil {
+			return nil, err
+		}
+		required = projects[0].Dir
+	}
+
+	var requiredErr error
+	parsedRequired := false
+	for _, p := range projects {
+		// Umbrella apps' dependencies come with the umbrella's
+		if project == "" && p.Umbrella != "" {
+			continue
+		}
+		deps, err := ws.parse(p)
+		if err != nil {
+			if p.Dir == required && requiredErr == nil {
+				requiredErr = err
+			}
+			ws.Skipped = append(ws.Skipped, fmt.Errorf("failed to parse the %s project in %s: %w", p.Type, p.Path, err))
+			continue
+		}
+		parsedRequired = parsedRequired || p.Dir == required
+		ws.Dependencies = mergeDependencies(ws.Dependencies, deps)
+	}
+	if !parsedRequired && requiredErr != nil {
+		return nil, requiredErr
+	}
+	return ws, nil
+}
+
+// DiscoverWorkspace finds the projects in the repository around dir without
+// parsing their dependencies
+func DiscoverWorkspace(dir string) (*Workspace, error) {
+	closest, projectTypes, err := DetectProject(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := workspaceRoot(closest)
+	projects, err := discoverProjects(root)
+	if err != nil {
+		return nil, err
+	}
+	// The closest project may sit somewhere discovery skips, such as vendor
+	if !slices.ContainsFunc(projects, func(p Project) bool { return p.Dir == closest }) {
+		root = closest
+		projects = []Project{}
+		for _, projectType := range projectTypes {
+			projects = append(projects, Project{Dir: closest, Path: ".", Type: projectType})
+		}
+	}
+	ws := &Workspace{Root: root, Projects: projects}
+	if ws.single() {
+		ws.Root = projects[0].Dir
+	}
+	return ws, nil
+}
+
+// workspaceRoot returns the root of the git repository holding the project
+// in dir, or the Elixir umbrella it's an app of, or dir itself
+func workspaceRoot(dir string) string {
+	for current := dir; ; {
+		if fileExists(filepath.Join(current, ".git")) {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	// apps/<app>/mix.exs under an umbrella's mix.exs
+	umbrella := filepath.Dir(filepath.Dir(dir))
+	if appsPath := umbrellaAppsPath(umbrella); appsPath != "" && filepath.Join(umbrella, appsPath) == filepath.Dir(dir) {
+		return umbrella
+	}
+	return dir
+}
+
+// discoverProjects lists the projects below root, root first. Projects that
+// share the lockfile of a project above them, such as Cargo or npm
+// workspace members, are covered by it and left out.
+func discoverProjects(root string) ([]Project, error) {
+	projects := []Project{}
+	umbrellaApps := map[string]string{} // app directory to its umbrella's path
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories can't hold projects we could parse
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if path != root && (strings.HasPrefix(d.Name(), ".") || ignoredDirs[d.Name()] || strings.Count(rel, string(filepath.Separator)) >= maxWorkspaceDepth) {
+			return filepath.SkipDir
+		}
+
+		for _, projectType := range detectDir(path) {
+			project := Project{Dir: path, Path: filepath.ToSlash(rel), Type: projectType}
+			if umbrella, ok := umbrellaApps[filepath.Dir(path)]; ok && projectType == ProjectTypeElixir {
+				project.Umbrella = umbrella
+			} else if coveredByParent(projects, project) {
+				continue
+			}
+			projects = append(projects, project)
+
+			if projectType == ProjectTypeElixir {
+				if appsPath := umbrellaAppsPath(path); appsPath != "" {
+					umbrellaApps[filepath.Join(path, appsPath)] = project.Path
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for projects in %s: %w", root, err)
+	}
+	return projects, nil
+}
+
+// coveredByParent reports whether project has no lockfile of its own while
+// an enclosing project of the same type has one
+func coveredByParent(projects []Project, project Project) bool {
+	if hasLockfile(project) {
+		return false
+	}
+	for _, parent := range projects {
+		if parent.Type == project.Type && strings.HasPrefix(project.Dir, parent.Dir+string(filepath.Separator)) && hasLockfile(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLockfile(project Project) bool {
+	e, _ := LookupEcosystem(project.Type)
+	for _, name := range e.Lockfiles {
+		if fileExists(filepath.Join(project.Dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+var appsPathRegex = regexp.MustCompile(`apps_path:\s*"([^"]+)"`)
+
+// umbrellaAppsPath returns the apps_path of an Elixir umbrella's mix.exs in
+// dir, or "" when dir isn't an umbrella
+func umbrellaAppsPath(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "mix.exs"))
+	if err != nil {
+		return ""
+	}
+	if matches := appsPathRegex.FindSubmatch(content); matches != nil {
+		return filepath.FromSlash(string(matches[1]))
+	}
+	return ""
+}
+
+var mixDepRegex = regexp.MustCompile(`\{\s*:(\w+)\s*,`)
+
+// declaredMixDeps returns the names of the dependencies a mix.exs declares in
+// its deps function
+func declaredMixDeps(dir string) []string {
+	content, err := os.ReadFile(filepath.Join(dir, "mix.exs"))
+	if err != nil {
+		return nil
+	}
+	text := string(content)
+	if i := strings.Index(text, " deps do"); i >= 0 {
+		text = text[i:]
+	}
+	names := []string{}
+	for _, match := range mixDepRegex.FindAllStringSubmatch(text, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// findProject resolves a --project argument against dir and the root to the
+// projects in that directory
+func (ws *Workspace) findProject(dir, project string) ([]Project, error) {
+	candidates := []string{filepath.Join(ws.Root, project)}
+	if abs, err := filepath.Abs(filepath.Join(dir, project)); err == nil {
+		candidates = append([]string{abs}, candidates...)
+	}
+	if filepath.IsAbs(project) {
+		candidates = []string{filepath.Clean(project)}
+	}
+
+	for _, candidate := range candidates {
+		selected := []Project{}
+		for _, p := range ws.Projects {
+			if p.Dir == candidate {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) > 0 {
+			return selected, nil
+		}
+	}
+	return nil, fmt.Errorf("no project at %s, expected one of: %s", project, strings.Join(ws.Paths(), ", "))
+}
+
+// Paths returns the paths of the workspace's projects, once per directory
+func (ws *Workspace) Paths() []string {
+	paths := []string{}
+	for _, p := range ws.Projects {
+		if !slices.Contains(paths, p.Path) {
+			paths = append(paths, p.Path)
+		}
+	}
+	return paths
+}
+
+// single reports whether every project is in one directory, whose
+// dependencies then aren't labelled with their projects
+func (ws *Workspace) single() bool {
+	return len(ws.Paths()) == 1
+}
+
+// parse returns the dependencies of one project, labelled with the projects
+// using them when the workspace has several
+func (ws *Workspace) parse(project Project) ([]Dependency, error) {
+	if project.Umbrella != "" {
+		umbrella := ws.project(project.Umbrella)
+		deps, err := ws.parse(umbrella)
+		if err != nil {
+			return nil, err
+		}
+		return slices.DeleteFunc(deps, func(dep Dependency) bool {
+			return !slices.Contains(dep.Projects, project.Path)
+		}), nil
+	}
+
+	deps, err := parseProject(project.Dir, project.Type)
+	if err != nil || ws.single() {
+		return deps, err
+	}
+
+	// Umbrella dependencies belong to the apps that declare them
+	apps := ws.umbrellaApps(project)
+	declared := make([][]string, len(apps))
+	for i, app := range apps {
+		declared[i] = declaredMixDeps(app.Dir)
+	}
+	for i := range deps {
+		deps[i].Projects = []string{project.Path}
+		var users []string
+		for j, app := range apps {
+			if slices.Contains(declared[j], deps[i].Name) {
+				users = append(users, app.Path)
+			}
+		}
+		if len(users) > 0 {
+			deps[i].Projects = users
+		}
+	}
+	return deps, nil
+}
+
+func (ws *Workspace) project(path string) Project {
+	for _, p := range ws.Projects {
+		if p.Path == path && p.Type == ProjectTypeElixir {
+			return p
+		}
+	}
+	return Project{}
+}
+
+func (ws *Workspace) umbrellaApps(umbrella Project) []Project {
+	apps := []Project{}
+	if umbrella.Type != ProjectTypeElixir {
+		return apps
+	}
+	for _, p := range ws.Projects {
+		if p.Umbrella == umbrella.Path {
+			apps = append(apps, p)
+		}
+	}
+	return apps
+}
+
+// mergeDependencies adds deps to merged, combining the projects of
+// dependencies used at the same version by several projects
+func mergeDependencies(merged, deps []Dependency) []Dependency {
+	index := map[string]int{}
+	for i, dep := range merged {
+		index[dep.Type+"/"+dep.Name+"@"+dep.Version] = i
+	}
+	for _, dep := range deps {
+		key := dep.Type + "/" + dep.Name + "@" + dep.Version
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, dep)
+			continue
+		}
+		for _, project := range dep.Projects {
+			if !slices.Contains(merged[i].Projects, project) {
+				merged[i].Projects = append(merged[i].Projects, project)
+			}
+		}
+		merged[i].Transitive = merged[i].Transitive && dep.Transitive
+	}
+	return merged
+}
+
+// VersionConflicts groups the dependencies that are used at more than one
+// version across a workspace, ordered by name. Versions used by the same
+// projects, such as a package nested under another in node_modules, aren't
+// differing between projects, so only those of different projects count.
+func VersionConflicts(deps []Dependency) [][]Dependency {
+	groups := map[string][]Dependency{}
+	for _, dep := range deps {
+		if len(dep.Projects) == 0 {
+			continue
+		}
+		key := dep.Type + "/" + dep.Name
+		groups[key] = append(groups[key], dep)
+	}
+
+	conflicts := [][]Dependency{}
+	for _, group := range groups {
+		projects := map[string]bool{}
+		for _, dep := range group {
+			projects[strings.Join(dep.Projects, ",")] = true
+		}
+		if len(projects) > 1 {
+			conflicts = append(conflicts, group)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i][0].Name < conflicts[j][0].Name
+	})
+	return conflicts
+}
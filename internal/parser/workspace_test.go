@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files below root from a map of relative paths to contents
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func gemfileLock(specs ...string) string {
+	lock := "GEM\n  remote: https://rubygems.org/\n  specs:\n"
+	for _, spec := range specs {
+		lock += "    " + spec + "\n"
+	}
+	lock += "\nPLATFORMS\n  ruby\n\nDEPENDENCIES\n"
+	for _, spec := range specs {
+		name, _, _ := strings.Cut(spec, " ")
+		lock += "  " + name + "\n"
+	}
+	return lock
+}
+
+// findDeps returns the dependencies named name
+func findDeps(deps []Dependency, name string) []Dependency {
+	found := []Dependency{}
+	for _, dep := range deps {
+		if dep.Name == name {
+			found = append(found, dep)
+		}
+	}
+	return found
+}
+
+func TestParseWorkspace_Monorepo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/HEAD":                     "ref: refs/heads/main\n",
+		"Gemfile":                       "source \"https://rubygems.org\"\n",
+		"Gemfile.lock":                  gemfileLock("rack (3.0.8)", "rake (13.1.0)"),
+		"admin/Gemfile":                 "source \"https://rubygems.org\"\n",
+		"admin/Gemfile.lock":            gemfileLock("rack (2.2.8)", "rake (13.1.0)"),
+		"services/billing/mix.exs":      "defmodule Billing.MixProject do\nend\n",
+		"services/billing/mix.lock":     `%{"jason": {:hex, :jason, "1.4.1", "hash", [:mix], [], "hexpm", "hash"}}` + "\n",
+		"node_modules/left-pad/Gemfile": "",
+	})
+
+	ws, err := ParseWorkspace(filepath.Join(root, "services", "billing"), "")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if ws.Root != root {
+		t.Errorf("Expected root %s, got %s", root, ws.Root)
+	}
+
+	paths := []string{}
+	for _, p := range ws.Projects {
+		paths = append(paths, p.Path)
+	}
+	if want := []string{".", "admin", "services/billing"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Projects = %v, want %v", paths, want)
+	}
+
+	rack := findDeps(ws.Dependencies, "rack")
+	if len(rack) != 2 || rack[0].Version != "3.0.8" || !reflect.DeepEqual(rack[0].Projects, []string{"."}) ||
+		rack[1].Version != "2.2.8" || !reflect.DeepEqual(rack[1].Projects, []string{"admin"}) {
+		t.Errorf("Expected rack at two versions, got %+v", rack)
+	}
+	rake := findDeps(ws.Dependencies, "rake")
+	if len(rake) != 1 || !reflect.DeepEqual(rake[0].Projects, []string{".", "admin"}) {
+		t.Errorf("Expected rake merged across projects, got %+v", rake)
+	}
+	jason := findDeps(ws.Dependencies, "jason")
+	if len(jason) != 1 || jason[0].ProjectRoot != filepath.Join(root, "services", "billing") {
+		t.Errorf("Expected jason from the billing service, got %+v", jason)
+	}
+
+	conflicts := VersionConflicts(ws.Dependencies)
+	if len(conflicts) != 1 || conflicts[0][0].Name != "rack" {
+		t.Errorf("Expected a rack conflict, got %+v", conflicts)
+	}
+}
+
+func TestVersionConflicts_SingleProject(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"dependencies": {"debug": "^4.3.4", "send": "^0.18.0"}}`,
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+			"": {"dependencies": {"debug": "^4.3.4", "send": "^0.18.0"}},
+			"node_modules/debug": {"version": "4.3.4"},
+			"node_modules/send": {"version": "0.18.0"},
+			"node_modules/send/node_modules/debug": {"version": "2.6.9"}
+		}}`,
+	})
+
+	ws, err := ParseWorkspace(root, "")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if debug := findDeps(ws.Dependencies, "debug"); len(debug) != 2 {
+		t.Fatalf("Expected debug at two versions, got %+v", debug)
+	}
+	// Nested versions of one project don't differ between projects
+	if conflicts := VersionConflicts(ws.Dependencies); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts in a single project, got %+v", conflicts)
+	}
+}
+
+func TestParseWorkspace_SelectProject(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/HEAD":          "ref: refs/heads/main\n",
+		"Gemfile":            "source \"https://rubygems.org\"\n",
+		"Gemfile.lock":       gemfileLock("rack (3.0.8)"),
+		"admin/Gemfile":      "source \"https://rubygems.org\"\n",
+		"admin/Gemfile.lock": gemfileLock("rack (2.2.8)"),
+	})
+
+	ws, err := ParseWorkspace(root, "admin")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	rack := findDeps(ws.Dependencies, "rack")
+	if len(rack) != 1 || rack[0].Version != "2.2.8" {
+		t.Errorf("Expected only admin's rack, got %+v", rack)
+	}
+
+	// Relative to the working directory too
+	if ws, err = ParseWorkspace(filepath.Join(root, "admin"), "."); err != nil || findDeps(ws.Dependencies, "rack")[0].Version != "2.2.8" {
+		t.Errorf("Expected --project . to select admin, got %+v, %v", ws, err)
+	}
+
+	_, err = ParseWorkspace(root, "missing")
+	if err == nil || !strings.Contains(err.Error(), "expected one of: ., admin") {
+		t.Errorf("Expected an error listing the projects, got %v", err)
+	}
+}
+
+func TestParseWorkspace_Umbrella(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"mix.exs": "defmodule Shop.Umbrella.MixProject do\n  def project do\n    [apps_path: \"apps\", deps: deps()]\n  end\nend\n",
+		"mix.lock": `%{
+  "castore": {:hex, :castore, "1.0.5", "hash", [:mix], [], "hexpm", "hash"},
+  "jason": {:hex, :jason, "1.4.1", "hash", [:mix], [], "hexpm", "hash"},
+  "phoenix": {:hex, :phoenix, "1.7.10", "hash", [:mix], [], "hexpm", "hash"},
+}` + "\n",
+		"apps/api/mix.exs": "defmodule Api.MixProject do\n  defp deps do\n    [{:jason, \"~> 1.4\"}]\n  end\nend\n",
+		"apps/web/mix.exs": "defmodule Web.MixProject do\n  defp deps do\n    [\n      {:phoenix, \"~> 1.7\"},\n      {:jason, \"~> 1.4\"},\n      {:api, in_umbrella: true}\n    ]\n  end\nend\n",
+	})
+
+	// Without a git repository the umbrella is found from an app
+	ws, err := ParseWorkspace(filepath.Join(root, "apps", "web"), "")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if ws.Root != root || len(ws.Projects) != 3 || ws.Projects[1].Umbrella != "." {
+		t.Fatalf("Expected the umbrella and its apps, got %+v", ws)
+	}
+
+	want := map[string][]string{
+		"castore": {"."},
+		"jason":   {"apps/api", "apps/web"},
+		"phoenix": {"apps/web"},
+	}
+	for name, projects := range want {
+		deps := findDeps(ws.Dependencies, name)
+		if len(deps) != 1 || !reflect.DeepEqual(deps[0].Projects, projects) {
+			t.Errorf("Expected %s in %v, got %+v", name, projects, deps)
+		}
+	}
+
+	ws, err = ParseWorkspace(root, "apps/web")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	names := []string{}
+	for _, dep := range ws.Dependencies {
+		if dep.Name != "elixir" {
+			names = append(names, dep.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"jason", "phoenix"}) {
+		t.Errorf("Expected web's dependencies, got %v", names)
+	}
+}
+
+func TestParseWorkspace_SharedLockfile(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/HEAD":    "ref: refs/heads/main\n",
+		"Gemfile":      "source \"https://rubygems.org\"\n",
+		"Gemfile.lock": gemfileLock("rack (3.0.8)"),
+		// A gem developed inside the app is covered by the app's lockfile
+		"engines/billing/Gemfile": "source \"https://rubygems.org\"\n",
+	})
+
+	ws, err := ParseWorkspace(root, "")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if len(ws.Projects) != 1 || ws.Root != root {
+		t.Errorf("Expected a single project, got %+v", ws.Projects)
+	}
+	if rack := findDeps(ws.Dependencies, "rack"); len(rack) != 1 || rack[0].Projects != nil {
+		t.Errorf("Expected a single project's rack without projects, got %+v", rack)
+	}
+}
+
+func TestParseWorkspace_MixedEcosystems(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		// A Phoenix app with a Gemfile for its tooling
+		"mix.exs":      "defmodule Shop.MixProject do\n  defp deps do\n    [{:jason, \"~> 1.4\"}]\n  end\nend\n",
+		"mix.lock":     `%{"jason": {:hex, :jason, "1.4.1", "hash", [:mix], [], "hexpm", "hash"}}` + "\n",
+		"Gemfile":      "source \"https://rubygems.org\"\n",
+		"Gemfile.lock": gemfileLock("rubocop (1.60.0)"),
+		// Not installed yet, which leaves out only its own dependencies
+		"package.json": "{}\n",
+	})
+
+	ws, err := ParseWorkspace(root, "")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if len(ws.Projects) != 3 || ws.Projects[0].Type != ProjectTypeElixir || ws.Projects[1].Type != ProjectTypeRuby || ws.Projects[2].Type != ProjectTypeNode {
+		t.Fatalf("Expected an Elixir, a Ruby and a Node project, got %+v", ws.Projects)
+	}
+	if len(ws.Skipped) != 1 || !strings.Contains(ws.Skipped[0].Error(), "node project in .") {
+		t.Errorf("Expected the Node project to be skipped, got %v", ws.Skipped)
+	}
+	if ws.Root != root || !reflect.DeepEqual(ws.Paths(), []string{"."}) {
+		t.Errorf("Expected a single directory at %s, got %s with %v", root, ws.Root, ws.Paths())
+	}
+
+	jason := findDeps(ws.Dependencies, "jason")
+	rubocop := findDeps(ws.Dependencies, "rubocop")
+	if len(jason) != 1 || jason[0].Type != "elixir" || jason[0].Projects != nil {
+		t.Errorf("Expected an unlabelled jason from mix.lock, got %+v", jason)
+	}
+	if len(rubocop) != 1 || rubocop[0].Type != "gem" || rubocop[0].Projects != nil {
+		t.Errorf("Expected an unlabelled rubocop from Gemfile.lock, got %+v", rubocop)
+	}
+
+	// Selecting the directory selects both ecosystems
+	ws, err = ParseWorkspace(root, ".")
+	if err != nil {
+		t.Fatalf("ParseWorkspace failed: %v", err)
+	}
+	if len(findDeps(ws.Dependencies, "jason")) != 1 || len(findDeps(ws.Dependencies, "rubocop")) != 1 {
+		t.Errorf("Expected both ecosystems' dependencies, got %+v", ws.Dependencies)
+	}
+}
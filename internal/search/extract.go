@@ -0,0 +1,382 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+// snippetLength is the number of characters of documentation kept per item
+const snippetLength = 160
+
+// extractor pulls searchable items out of one doc set format. It reports
+// false when the doc set isn't in its format.
+type extractor func(dir string) (docs []Document, bodies []string, ok bool, err error)
+
+// Build indexes the docs for a cache entry, recognising ExDoc, RDoc,
+// rustdoc and pages rendered by pudding
+func Build(entry cache.Entry) (*Segment, error) {
+	extractors := []extractor{extractExDoc, extractRDoc, extractRenderedPages, extractRustdoc}
+	for _, extract := range extractors {
+		docs, bodies, ok, err := extract(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index docs for %s %s: %w", entry.Name, entry.Version, err)
+		}
+		if ok {
+			if entry.Ecosystem == "go" {
+				docs, bodies = appendGoSymbols(docs, bodies)
+			}
+			return newSegment(entry, docs, bodies), nil
+		}
+	}
+	return nil, fmt.Errorf("no searchable docs found for %s %s in %s", entry.Name, entry.Version, entry.Path)
+}
+
+// exDocItem is an entry of ExDoc's search_data (searchData.items) or the
+// older search_items (searchNodes)
+type exDocItem struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Doc   string `json:"doc"`
+	Ref   string `json:"ref"`
+}
+
+// exDocSidebarNode is a module, extra or task in ExDoc's sidebar_items
+type exDocSidebarNode struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	NodeGroups []struct {
+		Name  string `json:"name"`
+		Nodes []struct {
+			ID     string `json:"id"`
+			Anchor string `json:"anchor"`
+		} `json:"nodes"`
+	} `json:"nodeGroups"`
+}
+
+func extractExDoc(dir string) ([]Document, []string, bool, error) {
+	if path := globFirst(dir, "dist/search_data-*.js"); path != "" {
+		var data struct {
+			Items []exDocItem `json:"items"`
+		}
+		if err := readJSAssignment(path, &data); err != nil {
+			return nil, nil, false, err
+		}
+		docs, bodies := exDocItems(data.Items)
+		return docs, bodies, true, nil
+	}
+
+	if path := globFirst(dir, "dist/search_items-*.js"); path != "" {
+		var items []exDocItem
+		if err := readJSAssignment(path, &items); err != nil {
+			return nil, nil, false, err
+		}
+		docs, bodies := exDocItems(items)
+		return docs, bodies, true, nil
+	}
+
+	if path := globFirst(dir, "dist/sidebar_items-*.js"); path != "" {
+		var raw map[string]json.RawMessage
+		if err := readJSAssignment(path, &raw); err != nil {
+			return nil, nil, false, err
+		}
+		sidebar := map[string][]exDocSidebarNode{}
+		for group, value := range raw {
+			var nodes []exDocSidebarNode
+			if json.Unmarshal(value, &nodes) == nil {
+				sidebar[group] = nodes
+			}
+		}
+		docs, bodies := exDocSidebar(sidebar)
+		return docs, bodies, true, nil
+	}
+
+	return nil, nil, false, nil
+}
+
+func exDocItems(items []exDocItem) ([]Document, []string) {
+	docs := make([]Document, 0, len(items))
+	bodies := make([]string, 0, len(items))
+	for _, item := range items {
+		text := plainText(item.Doc)
+		docs = append(docs, Document{Name: item.Title, Kind: item.Type, File: item.Ref, Snippet: snippet(text)})
+		bodies = append(bodies, text)
+	}
+	return docs, bodies
+}
+
+func exDocSidebar(sidebar map[string][]exDocSidebarNode) ([]Document, []string) {
+	groups := make([]string, 0, len(sidebar))
+	for group := range sidebar {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	docs := []Document{}
+	for _, group := range groups {
+		kind := strings.TrimSuffix(group, "s")
+		for _, node := range sidebar[group] {
+			docs = append(docs, Document{Name: node.Title, Kind: kind, File: node.ID + ".html"})
+			for _, nodeGroup := range node.NodeGroups {
+				for _, child := range nodeGroup.Nodes {
+					docs = append(docs, Document{
+						Name: node.ID + "." + child.ID,
+						Kind: strings.ToLower(strings.TrimSuffix(nodeGroup.Name, "s")),
+						File: node.ID + ".html#" + child.Anchor,
+					})
+				}
+			}
+		}
+	}
+	// The sidebar carries no documentation text, only names
+	return docs, make([]string, len(docs))
+}
+
+// rdocPageRegex matches pages generated from files such as README.md
+var rdocPageRegex = regexp.MustCompile(`_(md|rdoc|txt|markdown)\.html$`)
+
+func extractRDoc(dir string) ([]Document, []string, bool, error) {
+	path := globFirst(dir, "js/search_index.js", "js/search_data.js")
+	if path == "" {
+		return nil, nil, false, nil
+	}
+
+	// info rows are [name, namespace, path, params, snippet]
+	var data struct {
+		Index struct {
+			Info [][]string `json:"info"`
+		} `json:"index"`
+	}
+	if err := readJSAssignment(path, &data); err != nil {
+		return nil, nil, false, err
+	}
+
+	docs := []Document{}
+	bodies := []string{}
+	for _, info := range data.Index.Info {
+		if len(info) < 5 {
+			continue
+		}
+		name, namespace, ref, params := info[0], info[1], info[2], info[3]
+		kind := "class"
+		switch {
+		case strings.Contains(ref, "#method-i-"):
+			kind = "method"
+			name = namespace + "#" + name + params
+		case strings.Contains(ref, "#method-c-"):
+			kind = "class method"
+			name = namespace + "." + name + params
+		case namespace != "":
+			name = namespace + "::" + name
+		case rdocPageRegex.MatchString(ref):
+			kind = "page"
+		}
+		text := plainText(info[4])
+		docs = append(docs, Document{Name: name, Kind: kind, File: ref, Snippet: snippet(text)})
+		bodies = append(bodies, text)
+	}
+	return docs, bodies, true, nil
+}
+
+var renderedSectionRegex = regexp.MustCompile(`(?s)<h2 id="([^"]*)">(.*?)</h2>\n<pre>(.*?)</pre>`)
+
+// extractRenderedPages indexes the sections of pages pudding renders itself
+// for Go, npm and Python docs
+func extractRenderedPages(dir string) ([]Document, []string, bool, error) {
+	pages, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	sort.Strings(pages)
+
+	docs := []Document{}
+	bodies := []string{}
+	for _, page := range pages {
+		content, err := os.ReadFile(page)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		for _, match := range renderedSectionRegex.FindAllStringSubmatch(string(content), -1) {
+			id, title, body := html.UnescapeString(match[1]), html.UnescapeString(match[2]), html.UnescapeString(match[3])
+			docs = append(docs, Document{
+				Name:    title,
+				Kind:    "section",
+				File:    filepath.Base(page) + "#" + id,
+				Snippet: snippet(strings.Join(strings.Fields(body), " ")),
+			})
+			bodies = append(bodies, body)
+		}
+	}
+	return docs, bodies, len(docs) > 0, nil
+}
+
+var goDeclRegex = regexp.MustCompile(`^(?:func (?:\(\w* ?\*?(\w+)(?:\[[^\]]*\])?\) )?(\w+)|type (\w+))`)
+
+// appendGoSymbols adds an item for every func, method and type declared in
+// rendered `go doc -all` sections
+func appendGoSymbols(docs []Document, bodies []string) ([]Document, []string) {
+	sections := len(docs)
+	for i := 0; i < sections; i++ {
+		pkg := docs[i].Name
+		lines := strings.Split(bodies[i], "\n")
+		for start := 0; start < len(lines); start++ {
+			match := goDeclRegex.FindStringSubmatch(lines[start])
+			if match == nil {
+				continue
+			}
+			end := start + 1
+			for end < len(lines) && (lines[end] == "" || strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t") || strings.HasPrefix(lines[end], "}") || strings.HasPrefix(lines[end], ")")) {
+				end++
+			}
+
+			name, kind := match[2], "func"
+			switch {
+			case match[3] != "":
+				name, kind = match[3], "type"
+			case match[1] != "":
+				name, kind = match[1]+"."+match[2], "method"
+			}
+			body := strings.Join(lines[start:end], "\n")
+			docs = append(docs, Document{
+				Name:    pkg + "." + name,
+				Kind:    kind,
+				File:    docs[i].File,
+				Snippet: snippet(strings.Join(strings.Fields(strings.Join(lines[start+1:end], " ")), " ")),
+			})
+			bodies = append(bodies, body)
+		}
+	}
+	return docs, bodies
+}
+
+var (
+	rustdocFileRegex        = regexp.MustCompile(`^(struct|enum|trait|fn|macro|type|constant|static|union|attr|derive|primitive|traitalias)\.(.+)\.html$`)
+	rustdocDescriptionRegex = regexp.MustCompile(`<meta name="description" content="([^"]*)"`)
+)
+
+// extractRustdoc indexes a crate's rustdoc output by its item pages, e.g.
+// serde/de/trait.Deserialize.html becomes serde::de::Deserialize
+func extractRustdoc(dir string) ([]Document, []string, bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		return nil, nil, false, nil
+	}
+	crate := filepath.Base(dir)
+
+	docs := []Document{}
+	bodies := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.IsDir() {
+			if rel != "." && !isRustdocModuleDir(path) {
+				return filepath.SkipDir
+			}
+			if rel != "." {
+				docs = append(docs, Document{Name: crate + "::" + strings.ReplaceAll(filepath.ToSlash(rel), "/", "::"), Kind: "mod", File: filepath.ToSlash(filepath.Join(rel, "index.html"))})
+				bodies = append(bodies, rustdocDescription(filepath.Join(path, "index.html")))
+				docs[len(docs)-1].Snippet = snippet(bodies[len(bodies)-1])
+			}
+			return nil
+		}
+
+		match := rustdocFileRegex.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		modulePath := crate
+		if parent := filepath.Dir(rel); parent != "." {
+			modulePath += "::" + strings.ReplaceAll(filepath.ToSlash(parent), "/", "::")
+		}
+		description := rustdocDescription(path)
+		docs = append(docs, Document{
+			Name:    modulePath + "::" + match[2],
+			Kind:    match[1],
+			File:    filepath.ToSlash(rel),
+			Snippet: snippet(description),
+		})
+		bodies = append(bodies, description)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return docs, bodies, len(docs) > 0, nil
+}
+
+// isRustdocModuleDir reports whether dir holds the docs for a module
+func isRustdocModuleDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "index.html"))
+	return err == nil
+}
+
+func rustdocDescription(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if match := rustdocDescriptionRegex.FindSubmatch(content); match != nil {
+		return html.UnescapeString(string(match[1]))
+	}
+	return ""
+}
+
+// readJSAssignment decodes the JSON value assigned in a JavaScript file such
+// as `searchData={...}` or `var search_data = {...};`
+func readJSAssignment(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text := string(content)
+	start := strings.IndexAny(text, "{[")
+	end := strings.LastIndexAny(text, "}]")
+	if start == -1 || end < start {
+		return fmt.Errorf("no data found in %s", path)
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// globFirst returns the first file matching any of the patterns under dir
+func globFirst(dir string, patterns ...string) string {
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			return matches[0]
+		}
+	}
+	return ""
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips HTML tags and collapses whitespace
+func plainText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTagRegex.ReplaceAllString(s, " "))), " ")
+}
+
+// snippet shortens text to snippetLength characters at a word boundary
+func snippet(text string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetLength {
+		return text
+	}
+	cut := string(runes[:snippetLength])
+	if idx := strings.LastIndex(cut, " "); idx > snippetLength/2 {
+		cut = cut[:idx]
+	}
+	return cut + "…"
+}
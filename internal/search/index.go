@@ -0,0 +1,212 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+// Document is a single searchable item within a doc set, such as a module,
+// function or page section
+type Document struct {
+	Name    string `json:"name"`    // e.g. "Phoenix.Controller.json/2"
+	Kind    string `json:"kind"`    // e.g. "module", "function", "method", "section"
+	File    string `json:"file"`    // path relative to the doc set, with an optional #anchor
+	Snippet string `json:"snippet"` // start of the item's documentation
+}
+
+// Posting records that a term occurs in a document
+type Posting struct {
+	Doc    int     `json:"d"`
+	Weight float64 `json:"w"`
+}
+
+// Segment is the inverted index for one doc set
+type Segment struct {
+	Ecosystem string               `json:"ecosystem"`
+	Name      string               `json:"name"`
+	Version   string               `json:"version"`
+	Path      string               `json:"path"`
+	FetchedAt time.Time            `json:"fetched_at"` // of the cache entry the segment was built from
+	Docs      []Document           `json:"docs"`
+	Postings  map[string][]Posting `json:"postings"`
+}
+
+// nameWeight boosts terms that appear in an item's name over its body
+const nameWeight = 10
+
+// newSegment builds the inverted index for documents extracted from a cache entry.
+// bodies holds the full text of each document and is not stored.
+func newSegment(entry cache.Entry, docs []Document, bodies []string) *Segment {
+	seg := &Segment{
+		Ecosystem: entry.Ecosystem,
+		Name:      entry.Name,
+		Version:   entry.Version,
+		Path:      entry.Path,
+		FetchedAt: entry.FetchedAt,
+		Docs:      docs,
+		Postings:  map[string][]Posting{},
+	}
+
+	for i, doc := range docs {
+		weights := map[string]float64{}
+		for _, term := range Tokenize(doc.Name) {
+			weights[term] += nameWeight
+		}
+		counts := map[string]int{}
+		for _, term := range Tokenize(bodies[i]) {
+			counts[term]++
+		}
+		for term, count := range counts {
+			weights[term] += 1 + math.Log(float64(count))
+		}
+		for term, weight := range weights {
+			seg.Postings[term] = append(seg.Postings[term], Posting{Doc: i, Weight: weight})
+		}
+	}
+
+	return seg
+}
+
+// Tokenize splits text into lowercase search terms. Identifiers are indexed
+// whole and by their camelCase parts, so "HandleFunc" yields "handlefunc",
+// "handle" and "func".
+func Tokenize(text string) []string {
+	terms := []string{}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, word := range words {
+		for _, part := range append([]string{word}, splitIdentifier(word)...) {
+			part = strings.ToLower(strings.Trim(part, "_"))
+			if len(part) < 2 {
+				continue
+			}
+			terms = append(terms, part)
+		}
+	}
+	return terms
+}
+
+// splitIdentifier returns the snake_case and camelCase parts of word, or
+// nothing when word is a single part
+func splitIdentifier(word string) []string {
+	parts := []string{}
+	runes := []rune(word)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		boundary := i == len(runes) ||
+			runes[i] == '_' ||
+			(unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1])) ||
+			(unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))
+		if !boundary {
+			continue
+		}
+		if part := strings.Trim(string(runes[start:i]), "_"); part != "" {
+			parts = append(parts, part)
+		}
+		start = i
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts
+}
+
+// Index stores segments on disk, one file per doc set
+type Index struct {
+	dir string
+}
+
+// Open returns the index rooted at dir
+func Open(dir string) (*Index, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	return &Index{dir: dir}, nil
+}
+
+// OpenDefault returns the index kept alongside the docs cache
+func OpenDefault() (*Index, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return Open(filepath.Join(dir, "search"))
+}
+
+func (idx *Index) segmentPath(ecosystem, name, version string) string {
+	return filepath.Join(idx.dir, ecosystem, url.PathEscape(name)+"@"+url.PathEscape(version)+".json")
+}
+
+// Load returns the stored segment for a cache entry. It reports false when
+// the entry has never been indexed or its docs were fetched again since.
+func (idx *Index) Load(entry cache.Entry) (*Segment, bool) {
+	content, err := os.ReadFile(idx.segmentPath(entry.Ecosystem, entry.Name, entry.Version))
+	if err != nil {
+		return nil, false
+	}
+	var seg Segment
+	if err := json.Unmarshal(content, &seg); err != nil {
+		return nil, false
+	}
+	if !seg.FetchedAt.Equal(entry.FetchedAt) || seg.Path != entry.Path {
+		return nil, false
+	}
+	return &seg, true
+}
+
+// Save writes a segment to disk, replacing any previous version
+func (idx *Index) Save(seg *Segment) error {
+	path := idx.segmentPath(seg.Ecosystem, seg.Name, seg.Version)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create search index directory: %w", err)
+	}
+
+	content, err := json.Marshal(seg)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".segment-*")
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
+
+// Segment returns the segment for a cache entry, building and saving it
+// first when it's missing or stale. built reports whether indexing happened.
+func (idx *Index) Segment(entry cache.Entry) (seg *Segment, built bool, err error) {
+	if seg, ok := idx.Load(entry); ok {
+		return seg, false, nil
+	}
+
+	seg, err = Build(entry)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := idx.Save(seg); err != nil {
+		return nil, false, err
+	}
+	return seg, true, nil
+}
@@ -0,0 +1,116 @@
+package search
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Hit is a ranked search result
+type Hit struct {
+	Document
+	Ecosystem string
+	Dep       string // dependency name
+	Version   string
+	Location  string // absolute file path with an optional #anchor
+	Score     float64
+}
+
+// exactNameBoost multiplies the score of items whose name, or the last
+// component of it, is exactly the query
+const exactNameBoost = 2
+
+// Search ranks the documents across segments that contain every term in
+// query, returning at most limit hits (all when limit is 0)
+func Search(segments []*Segment, query string, limit int) []Hit {
+	terms := uniqueTerms(Tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	// Inverse document frequency over all searched doc sets, so rare terms count for more
+	totalDocs := 0
+	docFreq := map[string]int{}
+	for _, seg := range segments {
+		totalDocs += len(seg.Docs)
+		for _, term := range terms {
+			docFreq[term] += len(seg.Postings[term])
+		}
+	}
+	idf := map[string]float64{}
+	for _, term := range terms {
+		if docFreq[term] == 0 {
+			return nil
+		}
+		idf[term] = math.Log(1 + float64(totalDocs)/float64(docFreq[term]))
+	}
+
+	normalizedQuery := strings.ToLower(strings.TrimSpace(query))
+	hits := []Hit{}
+	for _, seg := range segments {
+		scores := map[int]float64{}
+		matched := map[int]int{}
+		for _, term := range terms {
+			for _, posting := range seg.Postings[term] {
+				scores[posting.Doc] += posting.Weight * idf[term]
+				matched[posting.Doc]++
+			}
+		}
+
+		for docID, score := range scores {
+			if matched[docID] < len(terms) {
+				continue
+			}
+			doc := seg.Docs[docID]
+			if isExactName(doc.Name, normalizedQuery) {
+				score *= exactNameBoost
+			}
+			hits = append(hits, Hit{
+				Document:  doc,
+				Ecosystem: seg.Ecosystem,
+				Dep:       seg.Name,
+				Version:   seg.Version,
+				Location:  filepath.Join(seg.Path, doc.File),
+				Score:     score,
+			})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Name != hits[j].Name {
+			return hits[i].Name < hits[j].Name
+		}
+		return hits[i].Dep < hits[j].Dep
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// isExactName reports whether query names the item, e.g. "json" or
+// "controller.json" for "Phoenix.Controller.json/2"
+func isExactName(name, query string) bool {
+	name = strings.ToLower(name)
+	if idx := strings.LastIndexAny(name, "/("); idx > 0 {
+		name = name[:idx]
+	}
+	return name == query || strings.HasSuffix(name, "."+query) || strings.HasSuffix(name, "#"+query) || strings.HasSuffix(name, "::"+query)
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,237 @@
+package search
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heycomputer/pudding/internal/cache"
+)
+
+func buildTestSegment(t *testing.T, ecosystem, name, version, dir string) *Segment {
+	t.Helper()
+	seg, err := Build(cache.Entry{Ecosystem: ecosystem, Name: name, Version: version, Path: dir})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return seg
+}
+
+func findDoc(seg *Segment, name string) (Document, bool) {
+	for _, doc := range seg.Docs {
+		if doc.Name == name {
+			return doc, true
+		}
+	}
+	return Document{}, false
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Phoenix.Controller.json/2 HandleFunc parse_HTTPHeader")
+	want := []string{"phoenix", "controller", "json", "handlefunc", "handle", "func", "parse_httpheader", "parse", "http", "header"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestBuild_ExDoc(t *testing.T) {
+	seg := buildTestSegment(t, "elixir", "phoenix", "1.7.14", filepath.Join("testdata", "exdoc"))
+
+	if len(seg.Docs) != 4 {
+		t.Fatalf("Expected 4 documents, got %d", len(seg.Docs))
+	}
+	doc, ok := findDoc(seg, "Phoenix.Controller.json/2")
+	if !ok {
+		t.Fatal("Expected Phoenix.Controller.json/2 to be indexed")
+	}
+	if doc.Kind != "function" || doc.File != "Phoenix.Controller.html#json/2" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	if doc.Snippet == "" {
+		t.Error("Expected a snippet")
+	}
+}
+
+func TestBuild_RDoc(t *testing.T) {
+	seg := buildTestSegment(t, "gem", "rack", "3.0.8", filepath.Join("testdata", "rdoc"))
+
+	tests := []struct {
+		name string
+		kind string
+		file string
+	}{
+		{"Rack", "class", "Rack.html"},
+		{"Rack::Request", "class", "Rack/Request.html"},
+		{"Rack::Request#get?()", "method", "Rack/Request.html#method-i-get-3F"},
+		{"Rack::Request.new(env)", "class method", "Rack/Request.html#method-c-new"},
+		{"README", "page", "README_md.html"},
+	}
+	for _, tt := range tests {
+		doc, ok := findDoc(seg, tt.name)
+		if !ok {
+			t.Errorf("Expected %s to be indexed", tt.name)
+			continue
+		}
+		if doc.Kind != tt.kind || doc.File != tt.file {
+			t.Errorf("%s: got kind %q file %q, want %q %q", tt.name, doc.Kind, doc.File, tt.kind, tt.file)
+		}
+	}
+}
+
+func TestBuild_RenderedGoDocs(t *testing.T) {
+	seg := buildTestSegment(t, "go", "github.com/google/uuid", "v1.6.0", filepath.Join("testdata", "rendered"))
+
+	tests := []struct {
+		name string
+		kind string
+	}{
+		{"github.com/google/uuid", "section"},
+		{"github.com/google/uuid.SetRand", "func"},
+		{"github.com/google/uuid.UUID", "type"},
+		{"github.com/google/uuid.NewRandom", "func"},
+		{"github.com/google/uuid.UUID.MarshalText", "method"},
+	}
+	for _, tt := range tests {
+		doc, ok := findDoc(seg, tt.name)
+		if !ok {
+			t.Errorf("Expected %s to be indexed", tt.name)
+			continue
+		}
+		if doc.Kind != tt.kind {
+			t.Errorf("%s: got kind %q, want %q", tt.name, doc.Kind, tt.kind)
+		}
+		if doc.File != "index.html#github.com/google/uuid" {
+			t.Errorf("%s: unexpected file %q", tt.name, doc.File)
+		}
+	}
+}
+
+func TestBuild_Rustdoc(t *testing.T) {
+	seg := buildTestSegment(t, "crate", "mycrate", "0.3.1", filepath.Join("testdata", "rustdoc", "mycrate"))
+
+	doc, ok := findDoc(seg, "mycrate::de::Deserialize")
+	if !ok {
+		t.Fatal("Expected mycrate::de::Deserialize to be indexed")
+	}
+	if doc.Kind != "trait" || doc.File != "de/trait.Deserialize.html" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	if _, ok := findDoc(seg, "mycrate::from_str"); !ok {
+		t.Error("Expected mycrate::from_str to be indexed")
+	}
+	if _, ok := findDoc(seg, "mycrate::de"); !ok {
+		t.Error("Expected module mycrate::de to be indexed")
+	}
+}
+
+func TestBuild_UnknownFormat(t *testing.T) {
+	_, err := Build(cache.Entry{Ecosystem: "npm", Name: "empty", Version: "1.0.0", Path: t.TempDir()})
+	if err == nil {
+		t.Error("Expected an error for a directory without docs")
+	}
+}
+
+func TestSearch_RanksNameMatchesFirst(t *testing.T) {
+	segments := []*Segment{
+		buildTestSegment(t, "elixir", "phoenix", "1.7.14", filepath.Join("testdata", "exdoc")),
+		buildTestSegment(t, "gem", "rack", "3.0.8", filepath.Join("testdata", "rdoc")),
+	}
+
+	hits := Search(segments, "json", 10)
+	if len(hits) == 0 {
+		t.Fatal("Expected hits for json")
+	}
+	if hits[0].Name != "Phoenix.Controller.json/2" {
+		t.Errorf("Expected Phoenix.Controller.json/2 first, got %s", hits[0].Name)
+	}
+	if hits[0].Dep != "phoenix" || hits[0].Version != "1.7.14" {
+		t.Errorf("Unexpected dependency on hit: %s %s", hits[0].Dep, hits[0].Version)
+	}
+	want := filepath.Join("testdata", "exdoc", "Phoenix.Controller.html#json/2")
+	if hits[0].Location != want {
+		t.Errorf("Location = %q, want %q", hits[0].Location, want)
+	}
+}
+
+func TestSearch_RequiresAllTerms(t *testing.T) {
+	segments := []*Segment{buildTestSegment(t, "gem", "rack", "3.0.8", filepath.Join("testdata", "rdoc"))}
+
+	hits := Search(segments, "request method", 0)
+	if len(hits) != 1 || hits[0].Name != "Rack::Request#get?()" {
+		t.Errorf("Expected only Rack::Request#get?(), got %+v", hits)
+	}
+
+	if hits := Search(segments, "request nonexistentterm", 0); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %d", len(hits))
+	}
+}
+
+func TestSearch_Limit(t *testing.T) {
+	segments := []*Segment{buildTestSegment(t, "gem", "rack", "3.0.8", filepath.Join("testdata", "rdoc"))}
+
+	if hits := Search(segments, "rack", 2); len(hits) != 2 {
+		t.Errorf("Expected 2 hits, got %d", len(hits))
+	}
+}
+
+func TestIndex_SegmentRebuildsWhenStale(t *testing.T) {
+	idx, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	entry := cache.Entry{
+		Ecosystem: "elixir",
+		Name:      "phoenix",
+		Version:   "1.7.14",
+		Path:      filepath.Join("testdata", "exdoc"),
+		FetchedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if _, built, err := idx.Segment(entry); err != nil || !built {
+		t.Fatalf("Expected first lookup to build the segment (built=%v, err=%v)", built, err)
+	}
+	seg, built, err := idx.Segment(entry)
+	if err != nil || built {
+		t.Fatalf("Expected second lookup to load the saved segment (built=%v, err=%v)", built, err)
+	}
+	if len(seg.Docs) != 4 {
+		t.Errorf("Expected loaded segment to have 4 documents, got %d", len(seg.Docs))
+	}
+
+	entry.FetchedAt = entry.FetchedAt.Add(time.Hour)
+	if _, built, err := idx.Segment(entry); err != nil || !built {
+		t.Errorf("Expected refetched docs to be reindexed (built=%v, err=%v)", built, err)
+	}
+}
+
+func TestFindSymbol(t *testing.T) {
+	exdoc := buildTestSegment(t, "elixir", "phoenix", "1.7.14", filepath.Join("testdata", "exdoc"))
+	rdoc := buildTestSegment(t, "gem", "rack", "3.0.8", filepath.Join("testdata", "rdoc"))
+
+	tests := []struct {
+		seg    *Segment
+		symbol string
+		files  []string
+	}{
+		{exdoc, "Phoenix.Controller", []string{"Phoenix.Controller.html"}},
+		{exdoc, "Phoenix.Controller.json/2", []string{"Phoenix.Controller.html#json/2"}},
+		{exdoc, "Phoenix.Controller.json", []string{"Phoenix.Controller.html#json/2"}},
+		{exdoc, "Phoenix.Controller.json/3", nil},
+		{exdoc, "Phoenix.Control", nil},
+		{rdoc, "Rack::Request#get?", []string{"Rack/Request.html#method-i-get-3F"}},
+		{rdoc, "Rack::Request.new", []string{"Rack/Request.html#method-c-new"}},
+		{rdoc, "Rack::Request::new", []string{"Rack/Request.html#method-c-new"}},
+		{rdoc, "Rack::Request", []string{"Rack/Request.html"}},
+	}
+	for _, tt := range tests {
+		files := []string{}
+		for _, doc := range FindSymbol(tt.seg, tt.symbol) {
+			files = append(files, doc.File)
+		}
+		if len(files) != len(tt.files) || len(files) > 0 && !reflect.DeepEqual(files, tt.files) {
+			t.Errorf("FindSymbol(%q) = %v, want %v", tt.symbol, files, tt.files)
+		}
+	}
+}
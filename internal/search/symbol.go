@@ -0,0 +1,55 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FindSymbol returns the documents in seg whose name is exactly symbol, such
+// as "Ecto.Changeset.cast/4" or "Rack::Request#get?". A symbol without an
+// arity matches every arity of the function, and RDoc's "Class::method"
+// spelling of class methods matches "Class.method".
+func FindSymbol(seg *Segment, symbol string) []Document {
+	symbol = normalizeSymbol(symbol)
+	matches := []Document{}
+	for _, doc := range seg.Docs {
+		name := symbolName(doc)
+		if name == symbol || hasAnyArity(name, symbol) {
+			matches = append(matches, doc)
+		}
+	}
+	return matches
+}
+
+// symbolName strips the parameter list RDoc appends to method names
+func symbolName(doc Document) string {
+	if strings.Contains(doc.Kind, "method") {
+		if i := strings.Index(doc.Name, "("); i > 0 {
+			return doc.Name[:i]
+		}
+	}
+	return doc.Name
+}
+
+// hasAnyArity reports whether name is symbol followed by an arity, e.g.
+// "cast/4" for "cast"
+func hasAnyArity(name, symbol string) bool {
+	arity, ok := strings.CutPrefix(name, symbol+"/")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(arity)
+	return err == nil
+}
+
+func normalizeSymbol(symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if i := strings.LastIndex(symbol, "::"); i >= 0 {
+		rest := symbol[i+2:]
+		if rest != "" && !unicode.IsUpper([]rune(rest)[0]) {
+			symbol = symbol[:i] + "." + rest
+		}
+	}
+	return symbol
+}
@@ -0,0 +1 @@
+searchData={"items":[{"type":"module","doc":"Controllers are used to group common functionality in the same (pluggable) module.","title":"Phoenix.Controller","ref":"Phoenix.Controller.html"},{"type":"function","doc":"Sends JSON response.\n\nIt uses the configured `:json_library` under the `:phoenix` application for `:json` to pick up the encoder module.","title":"Phoenix.Controller.json/2","ref":"Phoenix.Controller.html#json/2"},{"type":"function","doc":"Renders the given template or the default template specified by the current action with the given assigns.","title":"Phoenix.Controller.render/3","ref":"Phoenix.Controller.html#render/3"},{"type":"extras","doc":"Phoenix is a web development framework written in Elixir which implements the server-side Model View Controller (MVC) pattern.","title":"Overview","ref":"overview.html"}],"content_type":"text/markdown","producer":{"name":"ex_doc","version":[48,46,51,52,46,50]}}
@@ -0,0 +1 @@
+var search_data = {"index":{"searchIndex":["rack","request","get?()","new()","readme"],"longSearchIndex":["rack","rack::request","rack::request#get?()","rack::request::new()",""],"info":[["Rack","","Rack.html","","<p>The Rack main module, serving as a namespace for all core Rack modules and classes.\n"],["Request","Rack","Rack/Request.html","","<p>Rack::Request provides a convenient interface to a Rack environment.\n"],["get?","Rack::Request","Rack/Request.html#method-i-get-3F","()","<p>Checks the HTTP request method (or verb) to see if it was of type GET\n"],["new","Rack::Request","Rack/Request.html#method-c-new","(env)",""],["README","","README_md.html","","<p>Rack provides a minimal, modular, and adaptable interface for developing web applications in Ruby.\n"]]}}
@@ -1,54 +1,11 @@
 package selector
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/heycomputer/pudding/internal/parser"
-	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
 )
 
-// SelectDependency allows the user to interactively select a dependency from a list
-// using fuzzy finding. If query is provided, it will be used as initial filter.
-func SelectDependency(deps []parser.Dependency, query string) (*parser.Dependency, error) {
-	if len(deps) == 0 {
-		return nil, fmt.Errorf("no dependencies found")
-	}
-
-	// If there's an exact match for the query, return it
-	if query != "" {
-		for i := range deps {
-			if strings.EqualFold(deps[i].Name, query) {
-				return &deps[i], nil
-			}
-		}
-	}
-
-	// Use fuzzy finder for interactive selection
-	idx, err := fuzzyfinder.Find(
-		deps,
-		func(i int) string {
-			return fmt.Sprintf("%s %s", deps[i].Name, deps[i].Version)
-		},
-		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
-			if i == -1 {
-				return ""
-			}
-			return fmt.Sprintf("Package: %s\nVersion: %s\nType: %s",
-				deps[i].Name,
-				deps[i].Version,
-				deps[i].Type)
-		}),
-		fuzzyfinder.WithPromptString("view docs for> "),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &deps[idx], nil
-}
-
 // FilterDependencies returns dependencies matching the query string (case-insensitive)
 func FilterDependencies(deps []parser.Dependency, query string) []parser.Dependency {
 	if query == "" {
@@ -66,3 +23,30 @@ func FilterDependencies(deps []parser.Dependency, query string) []parser.Depende
 
 	return filtered
 }
+
+// DirectDependencies returns only the dependencies the project declares itself,
+// dropping transitive ones
+func DirectDependencies(deps []parser.Dependency) []parser.Dependency {
+	direct := []parser.Dependency{}
+	for _, dep := range deps {
+		if !dep.Transitive {
+			direct = append(direct, dep)
+		}
+	}
+	return direct
+}
+
+// PinFavorites moves favorite dependencies to the top of the list, keeping
+// the existing order among favorites and among the rest
+func PinFavorites(deps []parser.Dependency, isFavorite func(dep parser.Dependency) bool) []parser.Dependency {
+	pinned := []parser.Dependency{}
+	rest := []parser.Dependency{}
+	for _, dep := range deps {
+		if isFavorite(dep) {
+			pinned = append(pinned, dep)
+		} else {
+			rest = append(rest, dep)
+		}
+	}
+	return append(pinned, rest...)
+}
@@ -94,3 +94,45 @@ func TestFilterDependencies_CaseInsensitive(t *testing.T) {
 		}
 	}
 }
+
+func TestDirectDependencies(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "express", Version: "4.18.2", Type: "npm"},
+		{Name: "body-parser", Version: "1.20.1", Type: "npm", Transitive: true},
+		{Name: "lodash", Version: "4.17.21", Type: "npm"},
+	}
+
+	result := DirectDependencies(deps)
+	if len(result) != 2 {
+		t.Fatalf("DirectDependencies returned %d results, expected 2", len(result))
+	}
+	for _, dep := range result {
+		if dep.Transitive {
+			t.Errorf("DirectDependencies returned transitive dependency %s", dep.Name)
+		}
+	}
+}
+
+func TestPinFavorites(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "ecto", Version: "3.11.0", Type: "elixir"},
+		{Name: "jason", Version: "1.4.1", Type: "elixir"},
+		{Name: "phoenix", Version: "1.7.14", Type: "elixir"},
+		{Name: "plug", Version: "1.15.3", Type: "elixir"},
+	}
+	favorites := map[string]bool{"plug": true, "jason": true}
+
+	result := PinFavorites(deps, func(dep parser.Dependency) bool {
+		return favorites[dep.Name]
+	})
+
+	expected := []string{"jason", "plug", "ecto", "phoenix"}
+	if len(result) != len(expected) {
+		t.Fatalf("PinFavorites returned %d results, expected %d", len(result), len(expected))
+	}
+	for i, name := range expected {
+		if result[i].Name != name {
+			t.Errorf("PinFavorites()[%d] = %s, expected %s", i, result[i].Name, name)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/search"
+)
+
+// Server serves cached docs over HTTP under /<ecosystem>/<name>/<version>/,
+// alongside an index of the project's dependencies and a search endpoint
+type Server struct {
+	store       *cache.Store
+	index       *search.Index
+	deps        []parser.Dependency
+	projectName string
+	mux         *http.ServeMux
+}
+
+// New returns a server for the docs in store. deps are the project's
+// dependencies at their locked versions; when empty the index page and
+// search cover every cached doc set instead.
+func New(store *cache.Store, index *search.Index, deps []parser.Dependency) *Server {
+	s := &Server{
+		store: store,
+		index: index,
+		deps:  deps,
+		mux:   http.NewServeMux(),
+	}
+	if len(deps) > 0 && deps[0].ProjectRoot != "" {
+		s.projectName = filepath.Base(deps[0].ProjectRoot)
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("GET /", s.handleDocs)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// indexRow is one dependency on the index page
+type indexRow struct {
+	Name       string
+	Version    string
+	Type       string
+	Transitive bool
+	URL        string // empty when the docs aren't cached
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows := []indexRow{}
+	if len(s.deps) > 0 {
+		for _, dep := range s.deps {
+			row := indexRow{Name: dep.Name, Version: dep.Version, Type: dep.Type, Transitive: dep.Transitive}
+			if entry, ok := s.store.Get(dep.Type, dep.Name, dep.Version); ok {
+				row.URL = s.startPage(entries, entry)
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		for i := range entries {
+			rows = append(rows, indexRow{Name: entries[i].Name, Version: entries[i].Version, Type: entries[i].Ecosystem, URL: s.startPage(entries, &entries[i])})
+		}
+	}
+
+	title := "Cached docs"
+	if s.projectName != "" {
+		title = s.projectName + " dependencies"
+	}
+	s.render(w, indexTemplate, map[string]any{"Title": title, "Query": "", "Rows": rows})
+}
+
+// startPage returns the server path of a doc set's landing page
+func (s *Server) startPage(entries []cache.Entry, entry *cache.Entry) string {
+	if fileURL, err := docs.URL(entry, ""); err == nil {
+		if served, ok := docs.ServedURL("", entries, fileURL); ok {
+			return served
+		}
+	}
+	return "/" + entry.Key() + "/"
+}
+
+// searchResult is one hit returned by the search endpoint
+type searchResult struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	Ecosystem string  `json:"ecosystem"`
+	Dep       string  `json:"dependency"`
+	Version   string  `json:"version"`
+	Snippet   string  `json:"snippet,omitempty"`
+	URL       string  `json:"url"`
+	Score     float64 `json:"score"`
+}
+
+// handleSearch searches every doc set on the index page. Browsers get an
+// HTML page, other clients JSON.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = n
+	}
+
+	entries, err := s.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results := []searchResult{}
+	if query != "" {
+		for _, hit := range search.Search(s.segments(entries), query, limit) {
+			url, ok := docs.ServedURL("", entries, "file://"+hit.Location)
+			if !ok {
+				continue
+			}
+			results = append(results, searchResult{
+				Name:      hit.Name,
+				Kind:      hit.Kind,
+				Ecosystem: hit.Ecosystem,
+				Dep:       hit.Dep,
+				Version:   hit.Version,
+				Snippet:   hit.Snippet,
+				URL:       url,
+				Score:     hit.Score,
+			})
+		}
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(results)
+		return
+	}
+	s.render(w, searchTemplate, map[string]any{"Title": "Search", "Query": query, "Results": results})
+}
+
+// segments loads the search index for the doc sets on the index page,
+// building any that are missing
+func (s *Server) segments(entries []cache.Entry) []*search.Segment {
+	wanted := entries
+	if len(s.deps) > 0 {
+		wanted = []cache.Entry{}
+		for _, dep := range s.deps {
+			if entry, ok := s.store.Get(dep.Type, dep.Name, dep.Version); ok {
+				wanted = append(wanted, *entry)
+			}
+		}
+	}
+
+	segments := []*search.Segment{}
+	for _, entry := range wanted {
+		segment, _, err := s.index.Segment(entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// handleDocs serves files from the cached doc set named by the path
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Names may contain slashes (Go modules, scoped npm packages), so match
+	// whole keys rather than splitting the path
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	var match *cache.Entry
+	for i := range entries {
+		key := entries[i].Key()
+		if path != key && !strings.HasPrefix(path, key+"/") {
+			continue
+		}
+		if match == nil || len(key) > len(match.Key()) {
+			match = &entries[i]
+		}
+	}
+	if match == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if path == match.Key() {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	http.StripPrefix("/"+match.Key(), http.FileServer(http.Dir(match.Path))).ServeHTTP(w, r)
+}
+
+func (s *Server) render(w http.ResponseWriter, tmpl *template.Template, data map[string]any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/search"
+)
+
+const uuidPage = `<!DOCTYPE html>
+<html><body>
+<h1>github.com/google/uuid v1.6.0</h1>
+<h2 id="github.com/google/uuid">github.com/google/uuid</h2>
+<pre>package uuid
+
+func NewRandom() (UUID, error)
+    NewRandom returns a Random (Version 4) UUID.
+</pre>
+</body></html>
+`
+
+func newTestServer(t *testing.T, deps []parser.Dependency) *Server {
+	t.Helper()
+	dir := t.TempDir()
+
+	store, err := cache.Open(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docsDir := filepath.Join(dir, "docs", "uuid")
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "index.html"), []byte(uuidPage), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(cache.Entry{Ecosystem: "go", Name: "github.com/google/uuid", Version: "v1.6.0", Path: docsDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := search.Open(filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(store, index, deps)
+}
+
+func get(s *Server, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_ServesCachedDocs(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	rec := get(s, "/go/github.com/google/uuid/v1.6.0/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "NewRandom") {
+		t.Errorf("Expected the cached page, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = get(s, "/go/github.com/google/uuid/v1.6.0")
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/go/github.com/google/uuid/v1.6.0/" {
+		t.Errorf("Expected a redirect to the doc set root, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	for _, path := range []string{"/go/github.com/google/uuid/v1.5.0/index.html", "/go/github.com/google/uuid/v1.6.0/../../../../etc/passwd"} {
+		if rec := get(s, path); rec.Code == http.StatusOK {
+			t.Errorf("Expected %s not to be served", path)
+		}
+	}
+}
+
+func TestServer_IndexListsProjectDependencies(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "github.com/google/uuid", Version: "v1.6.0", Type: "go", ProjectRoot: "/src/app"},
+		{Name: "golang.org/x/text", Version: "v0.24.0", Type: "go", Transitive: true, ProjectRoot: "/src/app"},
+	}
+	s := newTestServer(t, deps)
+
+	body := get(s, "/").Body.String()
+	for _, want := range []string{"app dependencies", `href="/go/github.com/google/uuid/v1.6.0/index.html"`, "golang.org/x/text", "not cached"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the index page to contain %q", want)
+		}
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	rec := get(s, "/search?q=NewRandom")
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("Expected JSON, got %s", rec.Header().Get("Content-Type"))
+	}
+	var results []searchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to decode results: %v", err)
+	}
+	if len(results) == 0 || !strings.HasSuffix(results[0].Name, ".NewRandom") {
+		t.Fatalf("Expected NewRandom first, got %+v", results)
+	}
+	if !strings.HasPrefix(results[0].URL, "/go/github.com/google/uuid/v1.6.0/index.html#") {
+		t.Errorf("Expected a link into the served docs, got %s", results[0].URL)
+	}
+
+	rec = get(s, "/search?q=NewRandom", "Accept", "text/html")
+	if !strings.Contains(rec.Body.String(), `NewRandom</strong>`) {
+		t.Errorf("Expected an HTML results page, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import "html/template"
+
+const layout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} · pudding</title>
+<style>
+body { font-family: -apple-system, system-ui, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+a { color: #7a3e9d; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3em 0.8em 0.3em 0; }
+.dim { color: #888; }
+form { margin: 1em 0 2em; }
+input[type=search] { width: 60%; font-size: 1em; padding: 0.3em; }
+</style>
+</head>
+<body>
+<h1><a href="/">pudding</a> · {{.Title}}</h1>
+<form action="/search"><input type="search" name="q" value="{{.Query}}" placeholder="Search docs" autofocus> <button>Search</button></form>
+{{template "content" .}}
+</body>
+</html>
+`
+
+var indexTemplate = template.Must(template.Must(template.New("index").Parse(layout)).Parse(`{{define "content"}}
+<table>
+<tr><th>Name</th><th>Version</th><th>Type</th><th></th></tr>
+{{range .Rows}}<tr>
+<td>{{if .URL}}<a href="{{.URL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td>
+<td>{{.Version}}</td>
+<td>{{.Type}}</td>
+<td class="dim">{{if not .URL}}not cached, run <code>pd sync</code>{{else if .Transitive}}transitive{{end}}</td>
+</tr>{{else}}<tr><td class="dim" colspan="4">No docs cached yet</td></tr>{{end}}
+</table>
+{{end}}`))
+
+var searchTemplate = template.Must(template.Must(template.New("search").Parse(layout)).Parse(`{{define "content"}}
+{{if .Query}}{{range .Results}}<p>
+<a href="{{.URL}}"><strong>{{.Name}}</strong></a> <span class="dim">{{.Kind}} in {{.Dep}} {{.Version}}</span><br>
+{{.Snippet}}
+</p>{{else}}<p class="dim">No results for “{{.Query}}”</p>{{end}}{{end}}
+{{end}}`))
@@ -0,0 +1,398 @@
+package termdoc
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Options control how a page is rendered
+type Options struct {
+	Width    int    // wrap paragraphs at this many columns, 80 when zero
+	Color    bool   // emit ANSI styles
+	Fragment string // render only the element with this id, e.g. "cast/4"
+}
+
+// ANSI styles applied when Options.Color is set
+const (
+	styleReset     = "\x1b[0m"
+	styleBold      = "\x1b[1m"
+	styleDim       = "\x1b[2m"
+	styleUnderline = "\x1b[4m"
+	styleHeading   = "\x1b[1;35m"
+	styleCode      = "\x1b[36m"
+)
+
+// Render converts the HTML page in r to text. Navigation, scripts and other
+// page chrome are dropped, and links are listed as footnotes at the end.
+func Render(r io.Reader, opts Options) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page: %w", err)
+	}
+	if opts.Width <= 0 {
+		opts.Width = 80
+	}
+
+	root := contentRoot(doc)
+	if opts.Fragment != "" {
+		root = FindID(doc, opts.Fragment)
+		if root == nil {
+			return "", fmt.Errorf("no section '%s' on this page", opts.Fragment)
+		}
+	}
+
+	rd := &renderer{opts: opts}
+	rd.block(root)
+	rd.flush()
+	rd.footnotes()
+	return strings.TrimSpace(rd.out.String()) + "\n", nil
+}
+
+// FindID returns the element with the given id attribute, or nil
+func FindID(n *html.Node, id string) *html.Node {
+	if n.Type == html.ElementNode && attr(n, "id") == id {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := FindID(c, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// contentRoot finds the main content of a page: ExDoc's #content, RDoc's
+// <main>, or the whole body
+func contentRoot(doc *html.Node) *html.Node {
+	if n := FindID(doc, "content"); n != nil {
+		return n
+	}
+	if n := findAtom(doc, atom.Main); n != nil {
+		return n
+	}
+	if n := findAtom(doc, atom.Body); n != nil {
+		return n
+	}
+	return doc
+}
+
+func findAtom(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findAtom(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// skipped reports whether an element is page chrome rather than content
+func skipped(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Nav,
+		atom.Footer, atom.Aside, atom.Button, atom.Form, atom.Input, atom.Svg:
+		return true
+	}
+	return attr(n, "aria-hidden") == "true" || hasClass(n, "sr-only") || hasClass(n, "hidden")
+}
+
+// word is a run of inline text that can't be broken
+type word struct {
+	text  string // with styles applied
+	width int    // visible width
+	space bool   // preceded by whitespace, so a line may break before it
+}
+
+type renderer struct {
+	opts   Options
+	out    strings.Builder
+	words  []word
+	space  bool     // whitespace seen since the last word
+	styles []string // active inline styles
+	prefix string   // indent for every line of the current block
+	first  string   // replaces prefix on the next line written, e.g. a bullet
+	links  []string
+	blank  bool // the output ends with a blank line
+}
+
+// block renders the children of n as block content
+func (rd *renderer) block(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rd.node(c)
+	}
+}
+
+func (rd *renderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		rd.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		rd.block(n)
+		return
+	}
+	if skipped(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		rd.paragraphBreak()
+		rd.styled(n, styleHeading)
+		rd.paragraphBreak()
+
+	case atom.P, atom.Section, atom.Article, atom.Header, atom.Dl, atom.Table, atom.Details:
+		rd.paragraphBreak()
+		rd.block(n)
+		rd.paragraphBreak()
+
+	case atom.Div, atom.Tr, atom.Summary, atom.Main, atom.Body:
+		rd.flush()
+		rd.block(n)
+		rd.flush()
+
+	case atom.Pre:
+		rd.paragraphBreak()
+		rd.pre(n)
+		rd.paragraphBreak()
+
+	case atom.Ul, atom.Ol:
+		rd.paragraphBreak()
+		number := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				continue
+			}
+			number++
+			bullet := "• "
+			if n.DataAtom == atom.Ol {
+				bullet = strconv.Itoa(number) + ". "
+			}
+			rd.indented(c, bullet, strings.Repeat(" ", utf8.RuneCountInString(bullet)))
+		}
+		rd.paragraphBreak()
+
+	case atom.Blockquote:
+		rd.paragraphBreak()
+		rd.indented(n, "│ ", "│ ")
+		rd.paragraphBreak()
+
+	case atom.Dt:
+		rd.flush()
+		rd.styled(n, styleBold)
+		rd.flush()
+
+	case atom.Dd:
+		rd.indented(n, "    ", "    ")
+
+	case atom.Br:
+		rd.flush()
+
+	case atom.Hr:
+		rd.paragraphBreak()
+		rd.line(rd.style(styleDim, strings.Repeat("─", min(rd.opts.Width, 40))))
+		rd.paragraphBreak()
+
+	case atom.Td, atom.Th:
+		rd.space = true
+		rd.block(n)
+		rd.space = true
+
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		rd.styled(n, styleCode)
+
+	case atom.Strong, atom.B:
+		rd.styled(n, styleBold)
+
+	case atom.Em, atom.I, atom.Var:
+		rd.styled(n, styleUnderline)
+
+	case atom.A:
+		rd.link(n)
+
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			rd.text("[" + alt + "]")
+		}
+
+	default:
+		rd.block(n)
+	}
+}
+
+// styled renders n's children inline with an extra style
+func (rd *renderer) styled(n *html.Node, style string) {
+	rd.styles = append(rd.styles, style)
+	rd.block(n)
+	rd.styles = rd.styles[:len(rd.styles)-1]
+}
+
+// link renders a link's text and notes its target as a footnote. Links
+// within the page and icon-only links are left out.
+func (rd *renderer) link(n *html.Node) {
+	before := len(rd.words)
+	rd.styled(n, styleUnderline)
+
+	href := attr(n, "href")
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") || len(rd.words) == before {
+		return
+	}
+	rd.links = append(rd.links, href)
+	rd.words = append(rd.words, word{text: rd.style(styleDim, fmt.Sprintf("[%d]", len(rd.links))), width: len(fmt.Sprintf("[%d]", len(rd.links)))})
+}
+
+// indented renders n as a block whose first line starts with first and
+// whose other lines are indented by rest
+func (rd *renderer) indented(n *html.Node, first, rest string) {
+	rd.flush()
+	prefix := rd.prefix
+	rd.first = prefix + first
+	rd.prefix = prefix + rest
+	rd.block(n)
+	rd.flush()
+	rd.prefix = prefix
+	rd.first = ""
+}
+
+// pre writes preformatted text as an indented code block without wrapping
+func (rd *renderer) pre(n *html.Node) {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			sb.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+
+	code := strings.Trim(strings.ReplaceAll(sb.String(), "\t", "    "), "\n")
+	for _, line := range strings.Split(code, "\n") {
+		rd.line("    " + rd.style(styleCode, strings.TrimRight(line, " ")))
+	}
+}
+
+// text splits inline text into words using the active styles
+func (rd *renderer) text(s string) {
+	for len(s) > 0 {
+		trimmed := strings.TrimLeft(s, " \t\r\n\f")
+		if len(trimmed) < len(s) {
+			rd.space = true
+		}
+		s = trimmed
+		if s == "" {
+			return
+		}
+
+		end := strings.IndexAny(s, " \t\r\n\f")
+		if end < 0 {
+			end = len(s)
+		}
+		piece := s[:end]
+		s = s[end:]
+
+		rd.words = append(rd.words, word{
+			text:  rd.style(strings.Join(rd.styles, ""), piece),
+			width: utf8.RuneCountInString(piece),
+			space: rd.space,
+		})
+		rd.space = false
+	}
+}
+
+func (rd *renderer) style(style, s string) string {
+	if !rd.opts.Color || style == "" {
+		return s
+	}
+	return style + s + styleReset
+}
+
+// flush wraps the pending words into lines
+func (rd *renderer) flush() {
+	if len(rd.words) == 0 {
+		return
+	}
+
+	var line strings.Builder
+	width := 0
+	limit := max(rd.opts.Width-utf8.RuneCountInString(rd.prefix), 20)
+	for _, w := range rd.words {
+		if width > 0 && w.space && width+1+w.width > limit {
+			rd.line(line.String())
+			line.Reset()
+			width = 0
+		}
+		if width > 0 && w.space {
+			line.WriteString(" ")
+			width++
+		}
+		line.WriteString(w.text)
+		width += w.width
+	}
+	rd.line(line.String())
+
+	rd.words = nil
+	rd.space = false
+}
+
+// line writes one line of output with the block's indent
+func (rd *renderer) line(s string) {
+	prefix := rd.prefix
+	if rd.first != "" {
+		prefix, rd.first = rd.first, ""
+	}
+	rd.out.WriteString(strings.TrimRight(prefix+s, " ") + "\n")
+	rd.blank = false
+}
+
+// paragraphBreak ends the current block and leaves one blank line
+func (rd *renderer) paragraphBreak() {
+	rd.flush()
+	if !rd.blank && rd.out.Len() > 0 {
+		rd.out.WriteString(strings.TrimRight(rd.prefix, " ") + "\n")
+		rd.blank = true
+	}
+}
+
+func (rd *renderer) footnotes() {
+	if len(rd.links) == 0 {
+		return
+	}
+	rd.paragraphBreak()
+	rd.line(rd.style(styleHeading, "Links"))
+	for i, href := range rd.links {
+		rd.line(rd.style(styleDim, fmt.Sprintf("[%d] ", i+1)) + href)
+	}
+}
@@ -0,0 +1,92 @@
+package termdoc
+
+import (
+	"strings"
+	"testing"
+)
+
+const exDocPage = `<!DOCTYPE html>
+<html>
+<head><title>Ecto.Changeset</title><script>var x = 1;</script></head>
+<body>
+<nav id="sidebar">Sidebar entries</nav>
+<div id="content">
+  <h1>Ecto.Changeset <small class="app-vsn">(Ecto v3.11.0)</small></h1>
+  <p>Changesets allow filtering, <code>casting</code>, validation and definition of
+  constraints when manipulating structs. See <a href="Ecto.Schema.html">Ecto.Schema</a>.</p>
+  <ul><li>first point</li><li>second point</li></ul>
+  <section class="detail" id="cast/4">
+    <div class="detail-header">
+      <a href="#cast/4" class="detail-link" aria-label="Link to this function"><i class="ri-link-m" aria-hidden="true"></i></a>
+      <h1 class="signature">cast(data, params, permitted, opts \\ [])</h1>
+    </div>
+    <section class="docstring">
+      <p>Applies the given <code>params</code> as changes.</p>
+      <pre><code class="makeup elixir">changeset = cast(post, params, [:title])
+if changeset.valid? do
+  Repo.update!(changeset)
+end</code></pre>
+    </section>
+  </section>
+</div>
+<footer>Built using ExDoc</footer>
+</body>
+</html>
+`
+
+func TestRender_Page(t *testing.T) {
+	text, err := Render(strings.NewReader(exDocPage), Options{Width: 60})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"Ecto.Changeset (Ecto v3.11.0)",
+		"Ecto.Schema[1].",
+		"• first point\n• second point",
+		"    changeset = cast(post, params, [:title])\n    if changeset.valid? do\n      Repo.update!(changeset)",
+		"Links\n[1] Ecto.Schema.html",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Sidebar", "var x", "Built using ExDoc", "Link to this function", "\x1b["} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("Expected output not to contain %q", unwanted)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if len([]rune(line)) > 60 && !strings.HasPrefix(line, "    ") {
+			t.Errorf("Line exceeds the width: %q", line)
+		}
+	}
+}
+
+func TestRender_Fragment(t *testing.T) {
+	text, err := Render(strings.NewReader(exDocPage), Options{Fragment: "cast/4"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasPrefix(text, "cast(data, params, permitted, opts \\\\ [])") {
+		t.Errorf("Expected the function section only, got:\n%s", text)
+	}
+	if strings.Contains(text, "Changesets allow filtering") {
+		t.Error("Expected the module docs to be left out")
+	}
+
+	if _, err := Render(strings.NewReader(exDocPage), Options{Fragment: "missing/1"}); err == nil {
+		t.Error("Expected an error for a missing section")
+	}
+}
+
+func TestRender_Color(t *testing.T) {
+	text, err := Render(strings.NewReader(`<p>Use <code>cast/4</code></p>`), Options{Color: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(text, styleCode+"cast/4"+styleReset) {
+		t.Errorf("Expected inline code to be styled, got %q", text)
+	}
+}
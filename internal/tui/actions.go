@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/docs"
+	"github.com/heycomputer/pudding/internal/favorites"
+	"github.com/heycomputer/pudding/internal/history"
+	"github.com/heycomputer/pudding/internal/metadata"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func openHomepage(dep parser.Dependency) tea.Cmd {
+	return func() tea.Msg {
+		info := metadata.Lookup(&dep)
+		if info.Homepage == "" {
+			return statusMsg{text: fmt.Sprintf("No homepage known for %s", dep.Name)}
+		}
+		if err := docs.OpenURL(info.Homepage); err != nil {
+			return statusMsg{err: fmt.Errorf("failed to open homepage for %s: %w", dep.Name, err)}
+		}
+		return statusMsg{text: "Opened " + info.Homepage}
+	}
+}
+
+func openChangelog(dep parser.Dependency) tea.Cmd {
+	return func() tea.Msg {
+		info := metadata.Lookup(&dep)
+		if info.Changelog == "" {
+			return statusMsg{text: fmt.Sprintf("No changelog installed for %s %s", dep.Name, dep.Version)}
+		}
+		if err := docs.OpenURL("file://" + info.Changelog); err != nil {
+			return statusMsg{err: fmt.Errorf("failed to open changelog for %s: %w", dep.Name, err)}
+		}
+		return statusMsg{text: "Opened " + info.Changelog}
+	}
+}
+
+// copyDocURL fetches docs if needed and copies the URL `pd url` would print
+// to the clipboard, then refreshes the cached markers since fetching may have
+// added an entry. Warnings join the status, as stderr is hidden behind the TUI.
+func copyDocURL(dep parser.Dependency) tea.Cmd {
+	copyCmd := func() tea.Msg {
+		url, warnings, err := docs.ResolveURLQuietly(&dep, "")
+		if err != nil {
+			return statusMsg{err: err}
+		}
+		if err := copyToClipboard(url); err != nil {
+			return statusMsg{err: err}
+		}
+		status := "Copied " + url
+		for _, warning := range warnings {
+			status += " (Warning: " + warning.Error() + ")"
+		}
+		return statusMsg{text: status}
+	}
+	return tea.Sequence(copyCmd, refreshCache)
+}
+
+// toggleFavorite adds or removes a favorite and reloads the list
+func toggleFavorite(store *favorites.Store, projectRoot string, global, remove bool, dep parser.Dependency) tea.Cmd {
+	return func() tea.Msg {
+		scope := "project"
+		if global {
+			scope = "global"
+		}
+
+		var status string
+		if remove {
+			if _, err := store.Remove(projectRoot, global, dep.Type, dep.Name); err != nil {
+				return statusMsg{err: err}
+			}
+			status = fmt.Sprintf("Removed %s from %s favorites", dep.Name, scope)
+		} else {
+			if err := store.Add(projectRoot, global, dep.Type, dep.Name); err != nil {
+				return statusMsg{err: err}
+			}
+			status = fmt.Sprintf("Added %s to %s favorites", dep.Name, scope)
+		}
+
+		favs, err := store.List(projectRoot)
+		if err != nil {
+			return statusMsg{err: err}
+		}
+		return favoritesMsg{favorites: favs, status: status}
+	}
+}
+
+// deleteHistory removes every lookup of a dependency version from the history
+func deleteHistory(store *history.Store, dep parser.Dependency) tea.Cmd {
+	return func() tea.Msg {
+		_, err := store.Remove(func(entry history.Entry) bool {
+			opened := entry.Dependency()
+			return depKey(&opened) == depKey(&dep)
+		})
+		if err != nil {
+			return statusMsg{err: err}
+		}
+		entries, err := store.List()
+		if err != nil {
+			return statusMsg{err: err}
+		}
+		return historyMsg{entries: entries, status: fmt.Sprintf("Removed %s %s from history", dep.Name, dep.Version)}
+	}
+}
+
+func refreshCache() tea.Msg {
+	store, err := cache.OpenDefault()
+	if err != nil {
+		return nil
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil
+	}
+	return cacheMsg(entries)
+}
+
+// clipboardCommands are tried in order before falling back to OSC 52
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard puts text on the system clipboard, asking the terminal to
+// do it via an OSC 52 escape sequence when no clipboard tool is installed
+func copyToClipboard(text string) error {
+	for _, command := range clipboardCommands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	}
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
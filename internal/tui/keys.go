@@ -0,0 +1,59 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+// keyMap holds the key bindings of the list, shown in the help line
+type keyMap struct {
+	Up             key.Binding
+	Down           key.Binding
+	PageUp         key.Binding
+	PageDown       key.Binding
+	Open           key.Binding
+	Homepage       key.Binding
+	Changelog      key.Binding
+	CopyURL        key.Binding
+	Favorite       key.Binding
+	GlobalFavorite key.Binding
+	SortRecent     key.Binding
+	DeleteHistory  key.Binding
+	Filter         key.Binding
+	NextView       key.Binding
+	PrevView       key.Binding
+	Quit           key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:             key.NewBinding(key.WithKeys("up", "k", "ctrl+p"), key.WithHelp("↑/k", "up")),
+		Down:           key.NewBinding(key.WithKeys("down", "j", "ctrl+n"), key.WithHelp("↓/j", "down")),
+		PageUp:         key.NewBinding(key.WithKeys("pgup", "ctrl+u"), key.WithHelp("pgup", "page up")),
+		PageDown:       key.NewBinding(key.WithKeys("pgdown", "ctrl+d"), key.WithHelp("pgdn", "page down")),
+		Open:           key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open docs")),
+		Homepage:       key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "homepage")),
+		Changelog:      key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "changelog")),
+		CopyURL:        key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy doc URL")),
+		Favorite:       key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "favorite")),
+		GlobalFavorite: key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "global favorite")),
+		SortRecent:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by recent")),
+		DeleteHistory:  key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete from history")),
+		Filter:         key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		NextView:       key.NewBinding(key.WithKeys("tab", "right", "l"), key.WithHelp("tab", "next view")),
+		PrevView:       key.NewBinding(key.WithKeys("shift+tab", "left", "h"), key.WithHelp("shift+tab", "prev view")),
+		Quit:           key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"), key.WithHelp("q", "quit")),
+	}
+}
+
+// ShortHelp implements help.KeyMap
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Open, k.Homepage, k.Changelog, k.CopyURL, k.Favorite, k.Filter, k.NextView, k.Quit}
+}
+
+// FullHelp implements help.KeyMap
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.PageUp, k.PageDown},
+		{k.Open, k.Homepage, k.Changelog, k.CopyURL},
+		{k.Favorite, k.GlobalFavorite, k.SortRecent, k.DeleteHistory},
+		{k.Filter, k.NextView, k.PrevView, k.Quit},
+	}
+}
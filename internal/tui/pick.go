@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Choice is one of the candidates offered by Pick
+type Choice struct {
+	Title  string
+	Detail string // shown dimmed after the title
+}
+
+// pickHeight is the most choices shown at once
+const pickHeight = 10
+
+// pickModel is a single-column list to choose one candidate from
+type pickModel struct {
+	prompt  string
+	choices []Choice
+	keys    keyMap
+	cursor  int
+	offset  int
+	chosen  int
+}
+
+func newPickModel(prompt string, choices []Choice) pickModel {
+	return pickModel{prompt: prompt, choices: choices, keys: defaultKeyMap(), chosen: -1}
+}
+
+// Pick asks the user to choose between choices and returns the index of the
+// chosen one, or -1 when the user quits without choosing
+func Pick(prompt string, choices []Choice) (int, error) {
+	final, err := tea.NewProgram(newPickModel(prompt, choices)).Run()
+	if err != nil {
+		return -1, fmt.Errorf("failed to run terminal UI: %w", err)
+	}
+	return final.(pickModel).chosen, nil
+}
+
+func (m pickModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Open):
+		if len(m.choices) > 0 {
+			m.chosen = m.cursor
+		}
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Up):
+		m.cursor = max(m.cursor-1, 0)
+	case key.Matches(keyMsg, m.keys.Down):
+		m.cursor = min(m.cursor+1, max(len(m.choices)-1, 0))
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+pickHeight {
+		m.offset = m.cursor - pickHeight + 1
+	}
+	return m, nil
+}
+
+func (m pickModel) View() string {
+	// Clear the list once a choice is made so it doesn't linger in the scrollback
+	if m.chosen >= 0 {
+		return ""
+	}
+
+	lines := []string{titleStyle.Render(m.prompt)}
+	for i := m.offset; i < len(m.choices) && i < m.offset+pickHeight; i++ {
+		choice := m.choices[i]
+		line := choice.Title
+		if choice.Detail != "" {
+			line += " " + dimStyle.Render(choice.Detail)
+		}
+		if i == m.cursor {
+			lines = append(lines, cursorStyle.Render("> ")+line)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	lines = append(lines, dimStyle.Render("↑/↓ move • enter open • q cancel"))
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,483 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/favorites"
+	"github.com/heycomputer/pudding/internal/history"
+	"github.com/heycomputer/pudding/internal/metadata"
+	"github.com/heycomputer/pudding/internal/parser"
+	"github.com/heycomputer/pudding/internal/selector"
+)
+
+// Options configure the terminal UI
+type Options struct {
+	Deps           []parser.Dependency // every dependency of the project, of every ecosystem, including transitive ones
+	ProjectRoot    string              // scopes per-project favorites
+	Query          string              // initial filter, applied with selector.FilterDependencies
+	ShowTransitive bool                // start on the view that includes transitive dependencies
+}
+
+// Selection is the dependency picked to open docs for
+type Selection struct {
+	Dep      *parser.Dependency
+	Keywords string // search term of a lookup reopened from the History view
+}
+
+// Run shows the terminal UI until the user picks a dependency or quits.
+// It returns a nil Selection when the user quits without picking one.
+func Run(opts Options) (*Selection, error) {
+	var entries []cache.Entry
+	if store, err := cache.OpenDefault(); err == nil {
+		entries, _ = store.List()
+	}
+
+	favStore, err := favorites.OpenDefault()
+	if err != nil {
+		return nil, err
+	}
+	favs, err := favStore.List(opts.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	histStore, err := history.OpenDefault()
+	if err != nil {
+		return nil, err
+	}
+	hist, err := histStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newModel(opts, entries, favs, hist)
+	m.favStore = favStore
+	m.histStore = histStore
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run terminal UI: %w", err)
+	}
+	return final.(model).selection, nil
+}
+
+// viewKind identifies what a tab lists
+type viewKind int
+
+const (
+	viewProject viewKind = iota
+	viewAll
+	viewFavorites
+	viewHistory
+	viewCached
+)
+
+// listView is one tab of the UI, such as the project's direct dependencies
+type listView struct {
+	kind  viewKind
+	title string
+	deps  []parser.Dependency
+}
+
+// metadataMsg delivers the metadata of a dependency looked up in the background
+type metadataMsg struct {
+	key  string
+	info *metadata.Info
+}
+
+// statusMsg reports the outcome of an action
+type statusMsg struct {
+	text string
+	err  error
+}
+
+// cacheMsg delivers a refreshed list of cached docs
+type cacheMsg []cache.Entry
+
+// favoritesMsg delivers the favorites after one was added or removed
+type favoritesMsg struct {
+	favorites []favorites.Favorite
+	status    string
+}
+
+// historyMsg delivers the history after entries were deleted
+type historyMsg struct {
+	entries []history.Entry
+	status  string
+}
+
+type model struct {
+	opts      Options
+	views     []listView
+	active    int
+	filter    textinput.Model
+	filtered  []parser.Dependency
+	cursor    int
+	offset    int
+	width     int
+	height    int
+	entries   []cache.Entry
+	cached    map[string]cache.Entry
+	favStore  *favorites.Store
+	favorites map[string]favorites.Favorite
+	histStore *history.Store
+	history   []history.Entry
+	lastEntry map[string]history.Entry // most recent lookup of each dependency version
+	opened    map[string]time.Time     // when each dependency was last opened, by type and name
+	byRecent  bool
+	conflicts map[string]bool // dependencies sub-projects use at different versions, by type and name
+	mixed     bool            // whether the project's dependencies come from several ecosystems
+	info      map[string]*metadata.Info
+	status    string
+	keys      keyMap
+	help      help.Model
+	selection *Selection
+}
+
+func newModel(opts Options, entries []cache.Entry, favs []favorites.Favorite, hist []history.Entry) model {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "filter dependencies"
+	filter.Width = 40
+	filter.SetValue(opts.Query)
+
+	m := model{
+		opts:   opts,
+		filter: filter,
+		info:   map[string]*metadata.Info{},
+		keys:   defaultKeyMap(),
+		help:   help.New(),
+		width:  80,
+		height: 24,
+	}
+
+	m.views = []listView{{kind: viewProject, title: "Project"}}
+	if len(selector.DirectDependencies(opts.Deps)) != len(opts.Deps) {
+		m.views = append(m.views, listView{kind: viewAll, title: "All"})
+		if opts.ShowTransitive {
+			m.active = 1
+		}
+	}
+	m.views = append(m.views, listView{kind: viewFavorites, title: "Favorites"},
+		listView{kind: viewHistory, title: "History"}, listView{kind: viewCached, title: "Cached"})
+
+	m.conflicts = map[string]bool{}
+	for _, group := range parser.VersionConflicts(opts.Deps) {
+		m.conflicts[favorites.Key(group[0].Type, group[0].Name)] = true
+	}
+	for _, dep := range opts.Deps {
+		m.mixed = m.mixed || dep.Type != opts.Deps[0].Type
+	}
+
+	m.setCached(entries)
+	m.setFavorites(favs)
+	m.setHistory(hist)
+	m.rebuildViews()
+
+	return m
+}
+
+// setCached records which docs are cached
+func (m *model) setCached(entries []cache.Entry) {
+	m.entries = entries
+	m.cached = map[string]cache.Entry{}
+	for _, entry := range entries {
+		m.cached[entry.Key()] = entry
+	}
+}
+
+// setFavorites records which dependencies are favorites
+func (m *model) setFavorites(favs []favorites.Favorite) {
+	m.favorites = map[string]favorites.Favorite{}
+	for _, fav := range favs {
+		m.favorites[fav.Key()] = fav
+	}
+}
+
+// setHistory records when dependencies were last opened
+func (m *model) setHistory(entries []history.Entry) {
+	m.history = entries
+	m.lastEntry = map[string]history.Entry{}
+	m.opened = map[string]time.Time{}
+	for _, entry := range entries {
+		dep := entry.Dependency()
+		if _, ok := m.lastEntry[depKey(&dep)]; !ok {
+			m.lastEntry[depKey(&dep)] = entry
+		}
+		if _, ok := m.opened[favorites.Key(dep.Type, dep.Name)]; !ok {
+			m.opened[favorites.Key(dep.Type, dep.Name)] = entry.OpenedAt
+		}
+	}
+}
+
+func (m model) isFavorite(dep parser.Dependency) bool {
+	_, ok := m.favorites[favorites.Key(dep.Type, dep.Name)]
+	return ok
+}
+
+// rebuildViews fills every tab, pinning favorites to the top of the
+// dependency lists, and reapplies the filter
+func (m *model) rebuildViews() {
+	for i := range m.views {
+		switch m.views[i].kind {
+		case viewProject:
+			m.views[i].deps = selector.PinFavorites(m.sorted(selector.DirectDependencies(m.opts.Deps)), m.isFavorite)
+		case viewAll:
+			m.views[i].deps = selector.PinFavorites(m.sorted(m.opts.Deps), m.isFavorite)
+		case viewFavorites:
+			deps := []parser.Dependency{}
+			for _, dep := range m.opts.Deps {
+				if m.isFavorite(dep) {
+					deps = append(deps, dep)
+				}
+			}
+			m.views[i].deps = m.sorted(deps)
+		case viewHistory:
+			deps := []parser.Dependency{}
+			seen := map[string]bool{}
+			for _, entry := range m.history {
+				dep := entry.Dependency()
+				if !seen[depKey(&dep)] {
+					seen[depKey(&dep)] = true
+					deps = append(deps, dep)
+				}
+			}
+			m.views[i].deps = deps
+		case viewCached:
+			deps := make([]parser.Dependency, 0, len(m.entries))
+			for _, entry := range m.entries {
+				deps = append(deps, parser.Dependency{Name: entry.Name, Version: entry.Version, Type: entry.Ecosystem})
+			}
+			m.views[i].deps = deps
+		}
+	}
+	m.applyFilter()
+}
+
+// sorted orders dependencies by when they were last opened when sorting by
+// recency, keeping the name order for the ones never opened
+func (m model) sorted(deps []parser.Dependency) []parser.Dependency {
+	if !m.byRecent {
+		return deps
+	}
+	result := append([]parser.Dependency(nil), deps...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return m.opened[favorites.Key(result[i].Type, result[i].Name)].After(m.opened[favorites.Key(result[j].Type, result[j].Name)])
+	})
+	return result
+}
+
+// applyFilter narrows the active view to dependencies matching the filter
+func (m *model) applyFilter() {
+	m.filtered = selector.FilterDependencies(m.views[m.active].deps, m.filter.Value())
+	if m.cursor >= len(m.filtered) {
+		m.cursor = max(len(m.filtered)-1, 0)
+	}
+	m.clampOffset()
+}
+
+// current returns the highlighted dependency, or nil when the list is empty
+func (m model) current() *parser.Dependency {
+	if m.cursor < len(m.filtered) {
+		return &m.filtered[m.cursor]
+	}
+	return nil
+}
+
+func depKey(dep *parser.Dependency) string {
+	return cache.Key(dep.Type, dep.Name, dep.Version)
+}
+
+func (m model) Init() tea.Cmd {
+	return m.lookupCurrent()
+}
+
+// lookupCurrent loads metadata for the highlighted dependency in the background
+func (m model) lookupCurrent() tea.Cmd {
+	dep := m.current()
+	if dep == nil {
+		return nil
+	}
+	key := depKey(dep)
+	if _, ok := m.info[key]; ok {
+		return nil
+	}
+	target := *dep
+	return func() tea.Msg {
+		return metadataMsg{key: key, info: metadata.Lookup(&target)}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+		m.clampOffset()
+		return m, nil
+
+	case metadataMsg:
+		m.info[msg.key] = msg.info
+		return m, nil
+
+	case statusMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.status = msg.text
+		}
+		return m, nil
+
+	case cacheMsg:
+		m.setCached(msg)
+		m.rebuildViews()
+		return m, nil
+
+	case favoritesMsg:
+		m.setFavorites(msg.favorites)
+		m.rebuildViews()
+		m.status = msg.status
+		return m, m.lookupCurrent()
+
+	case historyMsg:
+		m.setHistory(msg.entries)
+		m.rebuildViews()
+		m.status = msg.status
+		return m, m.lookupCurrent()
+
+	case tea.KeyMsg:
+		if m.filter.Focused() {
+			return m.updateFilter(msg)
+		}
+		return m.updateList(msg)
+	}
+
+	return m, nil
+}
+
+// updateFilter handles keys while the filter input has focus
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter", "esc", "tab", "down", "up":
+		m.filter.Blur()
+		if msg.String() == "esc" {
+			m.filter.SetValue("")
+			m.applyFilter()
+		}
+		return m, m.lookupCurrent()
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.cursor = 0
+	m.applyFilter()
+	return m, tea.Batch(cmd, m.lookupCurrent())
+}
+
+// updateList handles keys while navigating the dependency list
+func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Filter):
+		return m, m.filter.Focus()
+
+	case key.Matches(msg, m.keys.Up):
+		m.moveCursor(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.moveCursor(1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.moveCursor(-m.listHeight())
+	case key.Matches(msg, m.keys.PageDown):
+		m.moveCursor(m.listHeight())
+
+	case key.Matches(msg, m.keys.NextView):
+		m.switchView(1)
+	case key.Matches(msg, m.keys.PrevView):
+		m.switchView(-1)
+
+	case key.Matches(msg, m.keys.Open):
+		if dep := m.current(); dep != nil {
+			selected := *dep
+			m.selection = &Selection{Dep: &selected}
+			// Reopening from the History view returns to the same search
+			if m.views[m.active].kind == viewHistory {
+				m.selection.Keywords = m.lastEntry[depKey(dep)].Keywords
+			}
+			return m, tea.Quit
+		}
+
+	case key.Matches(msg, m.keys.SortRecent):
+		m.byRecent = !m.byRecent
+		m.rebuildViews()
+		if m.byRecent {
+			m.status = "Sorted by recently opened"
+		} else {
+			m.status = "Sorted by name"
+		}
+	case key.Matches(msg, m.keys.DeleteHistory):
+		if dep := m.current(); dep != nil && m.histStore != nil && m.views[m.active].kind == viewHistory {
+			return m, deleteHistory(m.histStore, *dep)
+		}
+
+	case key.Matches(msg, m.keys.Homepage):
+		if dep := m.current(); dep != nil {
+			return m, openHomepage(*dep)
+		}
+	case key.Matches(msg, m.keys.Changelog):
+		if dep := m.current(); dep != nil {
+			return m, openChangelog(*dep)
+		}
+	case key.Matches(msg, m.keys.Favorite), key.Matches(msg, m.keys.GlobalFavorite):
+		if dep := m.current(); dep != nil && m.favStore != nil {
+			global := key.Matches(msg, m.keys.GlobalFavorite)
+			fav, ok := m.favorites[favorites.Key(dep.Type, dep.Name)]
+			remove := ok && fav.Global == global
+			return m, toggleFavorite(m.favStore, m.opts.ProjectRoot, global, remove, *dep)
+		}
+	case key.Matches(msg, m.keys.CopyURL):
+		if dep := m.current(); dep != nil {
+			m.status = fmt.Sprintf("Fetching docs for %s %s...", dep.Name, dep.Version)
+			return m, copyDocURL(*dep)
+		}
+	}
+
+	return m, m.lookupCurrent()
+}
+
+func (m *model) moveCursor(delta int) {
+	m.cursor = min(max(m.cursor+delta, 0), max(len(m.filtered)-1, 0))
+	m.clampOffset()
+}
+
+func (m *model) switchView(delta int) {
+	m.active = (m.active + delta + len(m.views)) % len(m.views)
+	m.cursor = 0
+	m.offset = 0
+	m.applyFilter()
+}
+
+// clampOffset scrolls the list so the cursor stays visible
+func (m *model) clampOffset() {
+	height := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(m.offset, 0)
+}
@@ -0,0 +1,401 @@
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/favorites"
+	"github.com/heycomputer/pudding/internal/history"
+	"github.com/heycomputer/pudding/internal/metadata"
+	"github.com/heycomputer/pudding/internal/parser"
+)
+
+func testDeps() []parser.Dependency {
+	return []parser.Dependency{
+		{Name: "ecto", Version: "3.11.0", Type: "elixir"},
+		{Name: "phoenix", Version: "1.7.14", Type: "elixir"},
+		{Name: "phoenix_html", Version: "4.1.1", Type: "elixir"},
+		{Name: "telemetry", Version: "1.2.1", Type: "elixir", Transitive: true},
+	}
+}
+
+func press(m model, keys ...string) model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "shift+tab":
+			msg = tea.KeyMsg{Type: tea.KeyShiftTab}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	return m
+}
+
+func names(deps []parser.Dependency) string {
+	result := []string{}
+	for _, dep := range deps {
+		result = append(result, dep.Name)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestModel_ViewsSplitDirectAndTransitive(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+
+	if len(m.views) != 5 {
+		t.Fatalf("Expected Project, All, Favorites, History and Cached views, got %d", len(m.views))
+	}
+	if got := names(m.filtered); got != "ecto,phoenix,phoenix_html" {
+		t.Errorf("Project view = %s", got)
+	}
+
+	m = press(m, "tab")
+	if got := names(m.filtered); got != "ecto,phoenix,phoenix_html,telemetry" {
+		t.Errorf("All view = %s", got)
+	}
+
+	m = newModel(Options{Deps: testDeps(), ShowTransitive: true}, nil, nil, nil)
+	if m.views[m.active].title != "All" {
+		t.Errorf("Expected ShowTransitive to start on the All view, got %s", m.views[m.active].title)
+	}
+}
+
+func TestModel_InitialQueryFilters(t *testing.T) {
+	m := newModel(Options{Deps: testDeps(), Query: "PHX"}, nil, nil, nil)
+	if len(m.filtered) != 0 {
+		t.Errorf("Expected substring filter to match nothing for PHX, got %s", names(m.filtered))
+	}
+
+	m = newModel(Options{Deps: testDeps(), Query: "Phoenix"}, nil, nil, nil)
+	if got := names(m.filtered); got != "phoenix,phoenix_html" {
+		t.Errorf("Filtered = %s", got)
+	}
+}
+
+func TestModel_TypingFilters(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+
+	m = press(m, "/", "e", "c")
+	if !m.filter.Focused() {
+		t.Fatal("Expected / to focus the filter")
+	}
+	if got := names(m.filtered); got != "ecto" {
+		t.Errorf("Filtered = %s", got)
+	}
+
+	m = press(m, "backspace", "backspace", "enter")
+	if m.filter.Focused() {
+		t.Error("Expected enter to leave the filter")
+	}
+	if len(m.filtered) != 3 {
+		t.Errorf("Expected clearing the filter to show all 3 direct deps, got %d", len(m.filtered))
+	}
+}
+
+func TestModel_EnterSelectsDependency(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+
+	updated, cmd := press(m, "down").Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if m.selection == nil || m.selection.Dep.Name != "phoenix" {
+		t.Fatalf("Expected phoenix to be selected, got %+v", m.selection)
+	}
+	if m.selection.Dep.Type != "elixir" {
+		t.Errorf("Unexpected dependency type %s", m.selection.Dep.Type)
+	}
+	if cmd == nil {
+		t.Fatal("Expected selecting to quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected selecting to quit")
+	}
+}
+
+func TestModel_MixedEcosystems(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "jason", Version: "1.4.1", Type: "elixir"},
+		{Name: "json", Version: "2.7.1", Type: "gem"},
+		{Name: "json", Version: "2.0.0", Type: "npm"},
+	}
+	m := newModel(Options{Deps: deps}, nil, nil, nil)
+
+	list := m.listView(80, 10)
+	for _, label := range []string{"jason 1.4.1 [elixir]", "json 2.7.1 [gem]", "json 2.0.0 [npm]"} {
+		if !strings.Contains(list, label) {
+			t.Errorf("Expected %q in the picker, got:\n%s", label, list)
+		}
+	}
+
+	// The selection keeps its own type, which picks its docs backend
+	m = press(m, "down", "down", "enter")
+	if m.selection == nil || m.selection.Dep.Type != "npm" {
+		t.Errorf("Expected the npm json to be selected, got %+v", m.selection)
+	}
+	if single := newModel(Options{Deps: testDeps()}, nil, nil, nil); strings.Contains(single.listView(80, 10), "[elixir]") {
+		t.Error("Expected no ecosystem labels for a single ecosystem")
+	}
+}
+
+func TestModel_QuitWithoutSelection(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+	if updated.(model).selection != nil {
+		t.Error("Expected no selection after quitting")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestModel_CachedView(t *testing.T) {
+	entries := []cache.Entry{
+		{Ecosystem: "gem", Name: "rack", Version: "3.0.8", Path: "/tmp/rack"},
+		{Ecosystem: "elixir", Name: "phoenix", Version: "1.7.14", Path: "/tmp/phoenix"},
+	}
+	m := newModel(Options{Deps: testDeps()}, entries, nil, nil)
+
+	if _, ok := m.cached[depKey(&m.filtered[1])]; !ok {
+		t.Error("Expected phoenix to be marked as cached")
+	}
+
+	m = press(m, "shift+tab")
+	if m.views[m.active].title != "Cached" {
+		t.Fatalf("Expected the Cached view, got %s", m.views[m.active].title)
+	}
+	if got := names(m.filtered); got != "rack,phoenix" {
+		t.Errorf("Cached view = %s", got)
+	}
+
+	// Docs cached from another project open with that ecosystem's backend
+	m = press(m, "enter")
+	if m.selection == nil || m.selection.Dep.Type != "gem" {
+		t.Errorf("Expected rack to open as a Ruby dependency, got %+v", m.selection)
+	}
+}
+
+func TestModel_PreviewShowsMetadata(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+
+	if !strings.Contains(m.View(), "Loading package details") {
+		t.Error("Expected a loading message before metadata arrives")
+	}
+
+	updated, _ := m.Update(metadataMsg{
+		key:  depKey(&m.filtered[0]),
+		info: &metadata.Info{Summary: "A toolkit for data mapping", License: "Apache-2.0", Homepage: "https://hexdocs.pm/ecto"},
+	})
+	view := updated.(model).View()
+
+	for _, want := range []string{"ecto 3.11.0", "A toolkit for data mapping", "Apache-2.0", "https://hexdocs.pm/ecto", "not cached"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected preview to contain %q", want)
+		}
+	}
+}
+
+func TestModel_FavoritesArePinned(t *testing.T) {
+	favs := []favorites.Favorite{{Ecosystem: "elixir", Name: "phoenix_html"}, {Ecosystem: "elixir", Name: "telemetry", Global: true}}
+	m := newModel(Options{Deps: testDeps()}, nil, favs, nil)
+
+	if got := names(m.filtered); got != "phoenix_html,ecto,phoenix" {
+		t.Errorf("Project view = %s", got)
+	}
+
+	m = press(m, "tab")
+	if got := names(m.filtered); got != "phoenix_html,telemetry,ecto,phoenix" {
+		t.Errorf("All view = %s", got)
+	}
+
+	m = press(m, "tab")
+	if m.views[m.active].title != "Favorites" {
+		t.Fatalf("Expected the Favorites view, got %s", m.views[m.active].title)
+	}
+	if got := names(m.filtered); got != "phoenix_html,telemetry" {
+		t.Errorf("Favorites view = %s", got)
+	}
+}
+
+func TestModel_ToggleFavorite(t *testing.T) {
+	m := newModel(Options{Deps: testDeps(), ProjectRoot: "/src/app"}, nil, nil, nil)
+	m.favStore = favorites.Open(filepath.Join(t.TempDir(), "favorites.json"))
+
+	// Favorite phoenix, the second direct dependency
+	m = press(m, "down")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(model)
+
+	if got := names(m.filtered); got != "phoenix,ecto,phoenix_html" {
+		t.Errorf("Expected phoenix to be pinned, got %s", got)
+	}
+	favs, err := m.favStore.List("/src/app")
+	if err != nil || len(favs) != 1 || favs[0].Name != "phoenix" || favs[0].Global {
+		t.Errorf("Expected phoenix saved as a project favorite, got %+v (err=%v)", favs, err)
+	}
+
+	// Pressing f again on the same dependency removes it
+	for i, dep := range m.filtered {
+		if dep.Name == "phoenix" {
+			m.cursor = i
+		}
+	}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(model)
+
+	if m.isFavorite(testDeps()[1]) {
+		t.Error("Expected phoenix to no longer be a favorite")
+	}
+}
+
+func testHistory() []history.Entry {
+	now := time.Now()
+	return []history.Entry{
+		{Ecosystem: "elixir", Name: "phoenix_html", Version: "4.1.1", Keywords: "form_for", OpenedAt: now},
+		{Ecosystem: "gem", Name: "rack", Version: "3.0.8", OpenedAt: now.Add(-time.Hour)},
+		{Ecosystem: "elixir", Name: "phoenix_html", Version: "4.1.1", OpenedAt: now.Add(-2 * time.Hour)},
+		{Ecosystem: "elixir", Name: "ecto", Version: "3.11.0", OpenedAt: now.Add(-3 * time.Hour)},
+	}
+}
+
+func TestModel_SortByRecent(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, testHistory())
+
+	m = press(m, "s")
+	if got := names(m.filtered); got != "phoenix_html,ecto,phoenix" {
+		t.Errorf("Expected recently opened first, got %s", got)
+	}
+
+	m = press(m, "s")
+	if got := names(m.filtered); got != "ecto,phoenix,phoenix_html" {
+		t.Errorf("Expected name order after toggling back, got %s", got)
+	}
+}
+
+func TestModel_HistoryView(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, testHistory())
+
+	m = press(m, "shift+tab", "shift+tab")
+	if m.views[m.active].title != "History" {
+		t.Fatalf("Expected the History view, got %s", m.views[m.active].title)
+	}
+	if got := names(m.filtered); got != "phoenix_html,rack,ecto" {
+		t.Errorf("History view = %s", got)
+	}
+	if !strings.Contains(m.View(), "form_for") {
+		t.Error("Expected the preview to show the last search term")
+	}
+
+	// Reopening returns to the last search for that dependency
+	m = press(m, "enter")
+	if m.selection == nil || m.selection.Dep.Name != "phoenix_html" || m.selection.Keywords != "form_for" {
+		t.Errorf("Expected phoenix_html reopened with its search term, got %+v", m.selection)
+	}
+}
+
+func TestModel_DeleteHistory(t *testing.T) {
+	m := newModel(Options{Deps: testDeps()}, nil, nil, nil)
+	m.histStore = history.Open(filepath.Join(t.TempDir(), "history.json"))
+	for _, entry := range slices.Backward(testHistory()) {
+		if err := m.histStore.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	entries, _ := m.histStore.List()
+	m.setHistory(entries)
+	m.rebuildViews()
+
+	// d does nothing outside the History view
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}); cmd != nil {
+		if _, ok := cmd().(historyMsg); ok {
+			t.Error("Expected d to only delete from the History view")
+		}
+	}
+
+	m = press(m, "shift+tab", "shift+tab")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(model)
+
+	if got := names(m.filtered); got != "rack,ecto" {
+		t.Errorf("Expected phoenix_html removed from history, got %s", got)
+	}
+	entries, _ = m.histStore.List()
+	if len(entries) != 2 {
+		t.Errorf("Expected every phoenix_html lookup to be deleted, got %+v", entries)
+	}
+}
+
+func TestModel_ConflictingVersionsShowProjects(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "jason", Version: "1.4.1", Type: "elixir", Projects: []string{"apps/api", "apps/web"}},
+		{Name: "rack", Version: "2.2.8", Type: "gem", Projects: []string{"admin"}},
+		{Name: "rack", Version: "3.0.8", Type: "gem", Projects: []string{"."}},
+	}
+	m := newModel(Options{Deps: deps}, nil, nil, nil)
+
+	list := m.listView(80, 10)
+	if !strings.Contains(list, "rack 2.2.8 (admin)") || !strings.Contains(list, "rack 3.0.8 (.)") {
+		t.Errorf("Expected both rack versions with their projects, got:\n%s", list)
+	}
+	if strings.Contains(list, "jason 1.4.1 (") {
+		t.Errorf("Expected no projects next to a dependency without conflicts, got:\n%s", list)
+	}
+	if preview := m.previewView(80); !strings.Contains(preview, "apps/api, apps/web") {
+		t.Errorf("Expected the preview to list the projects, got:\n%s", preview)
+	}
+}
+
+func TestPickModel_EnterChooses(t *testing.T) {
+	var m tea.Model = newPickModel("Which cast?", []Choice{
+		{Title: "Ecto.Changeset.cast/3", Detail: "ecto 3.11.0"},
+		{Title: "Ecto.Changeset.cast/4", Detail: "ecto 3.11.0"},
+	})
+	if view := m.View(); !strings.Contains(view, "Which cast?") || !strings.Contains(view, "> Ecto.Changeset.cast/3") {
+		t.Errorf("Expected the prompt and first choice under the cursor, got:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if chosen := m.(pickModel).chosen; chosen != 1 {
+		t.Errorf("Expected the second choice, got %d", chosen)
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected choosing to quit")
+	}
+}
+
+func TestPickModel_QuitCancels(t *testing.T) {
+	var m tea.Model = newPickModel("Which?", []Choice{{Title: "a"}, {Title: "b"}})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if chosen := m.(pickModel).chosen; chosen != -1 {
+		t.Errorf("Expected no choice after quitting, got %d", chosen)
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected esc to quit")
+	}
+}
@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/heycomputer/pudding/internal/cache"
+	"github.com/heycomputer/pudding/internal/favorites"
+)
+
+var (
+	activeTabStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("5")).Padding(0, 1)
+	inactiveTabStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Padding(0, 1)
+	paneStyle        = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	cursorStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
+	cachedStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	favoriteStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	dimStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	titleStyle       = lipgloss.NewStyle().Bold(true)
+	labelStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Width(10)
+)
+
+// chromeHeight is the number of lines around the list: tabs, filter, pane
+// borders, status and help
+const chromeHeight = 6
+
+// listHeight is the number of dependencies visible at once
+func (m model) listHeight() int {
+	return max(m.height-chromeHeight, 1)
+}
+
+func (m model) View() string {
+	listWidth := max(m.width*2/5, 20)
+	previewWidth := max(m.width-listWidth-4, 20)
+	height := m.listHeight()
+
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		paneStyle.Width(listWidth).Height(height).Render(m.listView(listWidth, height)),
+		paneStyle.Width(previewWidth).Height(height).Render(m.previewView(previewWidth)),
+	)
+
+	return strings.Join([]string{
+		m.tabsView(),
+		m.filter.View(),
+		panes,
+		dimStyle.Render(m.status),
+		m.help.View(m.keys),
+	}, "\n")
+}
+
+func (m model) tabsView() string {
+	tabs := make([]string, 0, len(m.views))
+	for i, view := range m.views {
+		label := fmt.Sprintf("%s (%d)", view.title, len(view.deps))
+		if i == m.active {
+			tabs = append(tabs, activeTabStyle.Render(label))
+		} else {
+			tabs = append(tabs, inactiveTabStyle.Render(label))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+func (m model) listView(width, height int) string {
+	if len(m.filtered) == 0 {
+		return dimStyle.Render("No matching dependencies")
+	}
+
+	lines := []string{}
+	for i := m.offset; i < len(m.filtered) && i < m.offset+height; i++ {
+		dep := &m.filtered[i]
+
+		marker := " "
+		if _, ok := m.cached[depKey(dep)]; ok {
+			marker = cachedStyle.Render("●")
+		}
+		if m.isFavorite(*dep) {
+			marker += favoriteStyle.Render("★")
+		} else {
+			marker += " "
+		}
+		label := fmt.Sprintf("%s %s", dep.Name, dep.Version)
+		// Versions that differ between sub-projects say whose each one is
+		if m.conflicts[favorites.Key(dep.Type, dep.Name)] && len(dep.Projects) > 0 {
+			label += " (" + strings.Join(dep.Projects, ", ") + ")"
+		}
+		// Names can repeat across ecosystems, such as the json gem and npm package
+		if m.mixed {
+			label += " [" + dep.Type + "]"
+		}
+		line := truncate(label, width-5)
+
+		if i == m.cursor {
+			lines = append(lines, cursorStyle.Render("> ")+marker+" "+cursorStyle.Render(line))
+		} else {
+			lines = append(lines, "  "+marker+" "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) previewView(width int) string {
+	dep := m.current()
+	if dep == nil {
+		return ""
+	}
+
+	rows := []string{titleStyle.Render(fmt.Sprintf("%s %s", dep.Name, dep.Version)), ""}
+	field := func(label, value string) {
+		if value != "" {
+			rows = append(rows, labelStyle.Render(label)+lipgloss.NewStyle().Width(max(width-10, 10)).Render(value))
+		}
+	}
+
+	field("Type", dep.Type)
+	field("Source", dep.Source)
+	field("Projects", strings.Join(dep.Projects, ", "))
+	if fav, ok := m.favorites[favorites.Key(dep.Type, dep.Name)]; ok {
+		if fav.Global {
+			field("Favorite", "global")
+		} else {
+			field("Favorite", "this project")
+		}
+	}
+	if kind := m.views[m.active].kind; kind != viewCached && kind != viewHistory {
+		if dep.Transitive {
+			field("Declared", "transitive")
+		} else {
+			field("Declared", "direct")
+		}
+	}
+
+	if entry, ok := m.lastEntry[depKey(dep)]; ok {
+		field("Opened", entry.OpenedAt.Local().Format(time.DateTime))
+		field("Search", entry.Keywords)
+	}
+
+	info, loaded := m.info[depKey(dep)]
+	switch {
+	case !loaded:
+		rows = append(rows, dimStyle.Render("Loading package details..."))
+	default:
+		field("Summary", info.Summary)
+		field("License", info.License)
+		field("Homepage", info.Homepage)
+		field("Changelog", info.Changelog)
+	}
+
+	rows = append(rows, "")
+	if entry, ok := m.cached[depKey(dep)]; ok {
+		field("Docs", cachedStyle.Render("cached")+" "+docsDetails(entry))
+	} else {
+		field("Docs", "not cached")
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func docsDetails(entry cache.Entry) string {
+	return fmt.Sprintf("(%s, fetched %s)", cache.FormatSize(entry.Size), entry.FetchedAt.Local().Format(time.DateOnly))
+}
+
+// truncate shortens s to width characters, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 1 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
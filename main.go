@@ -1,79 +1,154 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
+	"sync"
 
+	"github.com/heycomputer/pudding/internal/config"
 	"github.com/heycomputer/pudding/internal/docs"
 	"github.com/heycomputer/pudding/internal/parser"
-	"github.com/heycomputer/pudding/internal/selector"
 )
 
-func main() {
-	// Parse command line flags
-	var query string
-	flag.StringVar(&query, "q", "", "Query/filter for dependency name")
-	flag.Parse()
+// selectedProject is the sub-project of a workspace picked with --project
+var selectedProject string
 
-	// If there's a positional argument, use it as the query
-	if flag.NArg() > 0 {
-		query = flag.Arg(0)
-	}
+var (
+	pluginsOnce sync.Once
+	// pluginErrors are the provider plugins on PATH that failed to load,
+	// which `pd doctor` reports
+	pluginErrors []error
+)
 
-	// Get optional search keyword from second positional argument
-	var searchKeyword string
-	if flag.NArg() > 1 {
-		searchKeyword = flag.Arg(1)
+func main() {
+	// --browser and --project apply to every command, so they're taken out
+	// before any flags are parsed. `pd config` has a --project flag of its
+	// own, and completion needs to see every word.
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] != "__complete" {
+		var browserCommand string
+		args, browserCommand = takeFlag(args, "browser")
+		if browserCommand != "" {
+			config.Override("browser", browserCommand)
+		}
+		if len(args) == 0 || args[0] != "config" {
+			args, selectedProject = takeFlag(args, "project")
+		}
 	}
+	os.Args = append(os.Args[:1], args...)
 
-	// Get current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get current directory: %v\n", err)
-		os.Exit(1)
+	// Anything that isn't a command is a query for the default open command
+	cmd, _ := lookupCommand("open")
+	args = os.Args[1:]
+	if len(os.Args) > 1 {
+		if found, ok := lookupCommand(os.Args[1]); ok {
+			cmd, args = found, os.Args[2:]
+		}
 	}
 
-	// Parse project dependencies
-	deps, projectType, err := parser.ParseProjectDependencies(cwd)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// Provider plugins teach pd ecosystems it doesn't know, before anything
+	// detects the project or validates the config naming them
+	if cmd.plugins && cmd.name != "doctor" {
+		for _, err := range loadPlugins() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
 
-	if len(deps) == 0 {
-		fmt.Fprintf(os.Stderr, "No dependencies found in project\n")
-		os.Exit(1)
+	// A broken config is reported up front by the commands using it, except
+	// `pd config` which can fix it and `pd doctor` which explains it
+	if cmd.plugins && cmd.name != "config" && cmd.name != "doctor" {
+		cfg, err := config.Current()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, err := range cfg.Warnings() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		applyMirrors(cfg)
 	}
 
-	// Sort dependencies by name for better UX
-	sort.Slice(deps, func(i, j int) bool {
-		return deps[i].Name < deps[j].Name
+	os.Exit(cmd.run(args))
+}
+
+// loadPlugins registers the provider plugins on PATH the first time it's
+// called, as each one is run to describe itself, and returns those that
+// failed to load
+func loadPlugins() []error {
+	pluginsOnce.Do(func() {
+		pluginErrors = docs.RegisterExternal()
 	})
+	return pluginErrors
+}
 
-	// Filter dependencies if query is provided
-	filteredDeps := deps
-	if query != "" {
-		filteredDeps = selector.FilterDependencies(deps, query)
-		if len(filteredDeps) == 0 {
-			fmt.Fprintf(os.Stderr, "No dependencies matching '%s' found\n", query)
-			os.Exit(1)
+// takeFlag removes -name/--name and its value from args, wherever it
+// appears, and returns the remaining arguments and the value
+func takeFlag(args []string, flagName string) ([]string, string) {
+	rest := []string{}
+	var command string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != flagName {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
 		}
+		command = value
+	}
+	return rest, command
+}
+
+// loadWorkspace parses the dependencies of every project in the repository,
+// or of the one picked with --project, leaving out the excluded ones and
+// adding the extra ones from the config
+func loadWorkspace(dir string) (*parser.Workspace, error) {
+	ws, err := parser.ParseWorkspace(dir, selectedProject)
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range ws.Skipped {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	cfg, err := config.Current()
+	if err != nil {
+		return nil, err
 	}
+	ws.Dependencies = cfg.Dependencies(ws.Dependencies)
+	return ws, nil
+}
 
-	// Let user select a dependency
-	selectedDep, err := selector.SelectDependency(filteredDeps, query)
+// projectDependencies returns the dependencies of the workspace in dir
+func projectDependencies(dir string) ([]parser.Dependency, error) {
+	ws, err := loadWorkspace(dir)
 	if err != nil {
-		// User cancelled or error occurred
-		fmt.Fprintf(os.Stderr, "Selection cancelled or error: %v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
+	return ws.Dependencies, nil
+}
 
-	// Fetch and open documentation
-	fmt.Printf("Opening documentation for %s %s...\n", selectedDep.Name, selectedDep.Version)
-	if err := docs.FetchAndOpen(selectedDep, projectType, searchKeyword); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to open documentation: %v\n", err)
-		os.Exit(1)
+// applyMirrors points the package managers pudding runs at the configured
+// mirrors, unless their own variables are already set
+func applyMirrors(cfg *config.Config) {
+	for name, value := range map[string]string{"HEX_MIRROR": cfg.Mirrors.Hex, "HEX_API_URL": cfg.Mirrors.HexAPI, "GOPROXY": cfg.Mirrors.GoProxy} {
+		if value != "" && os.Getenv(name) == "" {
+			os.Setenv(name, value)
+		}
 	}
 }
+
+// sortDependencies orders dependencies by name
+func sortDependencies(deps []parser.Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Name < deps[j].Name
+	})
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/heycomputer/pudding/internal/favorites"
+	"github.com/heycomputer/pudding/internal/parser"
 )
 
 func TestMainIntegration(t *testing.T) {
@@ -10,3 +18,226 @@ func TestMainIntegration(t *testing.T) {
 	// in their respective test files
 	t.Log("Integration test environment ready")
 }
+
+func TestTakeFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		rest    []string
+		command string
+	}{
+		{[]string{"ecto"}, []string{"ecto"}, ""},
+		{[]string{"--browser", "print", "ecto", "cast"}, []string{"ecto", "cast"}, "print"},
+		{[]string{"ecto", "-browser=firefox %s"}, []string{"ecto"}, "firefox %s"},
+		{[]string{"history", "open", "2", "--browser=w3m"}, []string{"history", "open", "2"}, "w3m"},
+		{[]string{"ecto", "--", "--browser"}, []string{"ecto", "--", "--browser"}, ""},
+	}
+	for _, tt := range tests {
+		rest, command := takeFlag(tt.args, "browser")
+		if !reflect.DeepEqual(rest, tt.rest) || command != tt.command {
+			t.Errorf("takeFlag(%q) = %q, %q, want %q, %q", tt.args, rest, command, tt.rest, tt.command)
+		}
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"rails", "-a", "routing"}, []string{"rails", "routing"}},
+		{[]string{"-a", "rails", "--", "-a", "--x"}, []string{"rails", "-a", "--x"}},
+		{[]string{"--", "-a"}, []string{"-a"}},
+	}
+	for _, tt := range tests {
+		flags := flag.NewFlagSet("pd test", flag.ContinueOnError)
+		all := flags.Bool("a", false, "")
+		got, err := parseInterspersed(flags, tt.args)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInterspersed(%q) = %q, %v, want %q", tt.args, got, err, tt.want)
+		}
+		if want := tt.args[0] != "--"; *all != want {
+			t.Errorf("parseInterspersed(%q) set -a to %v, want %v", tt.args, *all, want)
+		}
+	}
+}
+
+func TestLookupCommand(t *testing.T) {
+	for name, want := range map[string]string{"list": "list", "--version": "version", "-h": "help", "-": "-"} {
+		cmd, ok := lookupCommand(name)
+		if !ok || cmd.name != want {
+			t.Errorf("lookupCommand(%q) = %q, %v, want %q", name, cmd.name, ok, want)
+		}
+	}
+	if _, ok := lookupCommand("phoenix"); ok {
+		t.Error("lookupCommand(\"phoenix\") found a command")
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"cache"}, []string{"list", "size", "prune", "clear"}},
+		{[]string{"completion"}, []string{"bash", "zsh", "fish"}},
+		{[]string{"config", "set", "output"}, []string{"table", "json", "ndjson", "tsv"}},
+		{[]string{"config", "set", "docs.gem"}, []string{"local", "online"}},
+		{[]string{"list", "-o"}, []string{"table", "json", "ndjson", "tsv"}},
+		{[]string{"list", "-a", "--o"}, []string{"table", "json", "ndjson", "tsv"}},
+		{[]string{"search", "-n"}, nil},
+		{[]string{"ecto"}, nil},
+		{[]string{"cache", "clear"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range completions(tt.args, "") {
+			got = append(got, c.value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completions(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	// Outside a project the first word completes to commands only
+	first := completions(nil, "")
+	if len(first) == 0 || first[0].value != "open" {
+		t.Errorf("completions(nil) = %v, want the commands starting with open", first)
+	}
+	for _, c := range first {
+		if c.value == "__complete" || c.value == "-" {
+			t.Errorf("completions(nil) includes %q", c.value)
+		}
+	}
+	keys := completions([]string{"config", "get"}, "")
+	if len(keys) == 0 || keys[0].value != "browser" {
+		t.Errorf("completions(config get) = %v, want the config keys", keys)
+	}
+}
+
+func TestCompletions_FlagValueInSameWord(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var got []string
+	for _, c := range completions([]string{"list"}, "--o=") {
+		got = append(got, c.value)
+	}
+	want := []string{"--o=table", "--o=json", "--o=ndjson", "--o=tsv"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completions(list --o=) = %q, want %q", got, want)
+	}
+}
+
+func TestCompletions_FavRemove(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := favorites.OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(cwd, false, "gem", "rails"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(cwd, true, "npm", "react"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"fav", "remove"}, []string{"rails"}},
+		{[]string{"fav", "rm"}, []string{"rails"}},
+		{[]string{"fav", "remove", "--global"}, []string{"react"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range completions(tt.args, "") {
+			got = append(got, c.value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completions(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestResolveDependency_ConflictingVersions(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "rack", Version: "3.0.8", Type: "gem", Projects: []string{"."}},
+		{Name: "rack", Version: "2.2.8", Type: "gem", Projects: []string{"admin"}},
+		{Name: "rake", Version: "13.1.0", Type: "gem", Projects: []string{".", "admin"}},
+	}
+
+	if dep, err := resolveDependency(deps, "rake"); err != nil || dep.Version != "13.1.0" {
+		t.Errorf("resolveDependency(rake) = %+v, %v", dep, err)
+	}
+	_, err := resolveDependency(deps, "rack")
+	if err == nil || !strings.Contains(err.Error(), "3.0.8 (.), 2.2.8 (admin)") {
+		t.Errorf("Expected an error naming both versions, got %v", err)
+	}
+}
+
+func TestResolveDependency_MixedEcosystems(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "json", Version: "2.7.1", Type: "gem"},
+		{Name: "json", Version: "2.0.0", Type: "npm"},
+		{Name: "jason", Version: "1.4.1", Type: "elixir"},
+	}
+
+	_, err := resolveDependency(deps, "json")
+	if err == nil || !strings.Contains(err.Error(), "gem, npm") || !strings.Contains(err.Error(), "gem/json") {
+		t.Errorf("Expected an error naming both ecosystems, got %v", err)
+	}
+	if dep, err := resolveDependency(deps, "npm/json"); err != nil || dep.Type != "npm" {
+		t.Errorf("resolveDependency(npm/json) = %+v, %v", dep, err)
+	}
+	if dep, err := resolveDependency(deps, "jason"); err != nil || dep.Type != "elixir" {
+		t.Errorf("resolveDependency(jason) = %+v, %v", dep, err)
+	}
+}
+
+func TestWriteListTable_Workspace(t *testing.T) {
+	deps := []parser.Dependency{
+		{Name: "rack", Version: "2.2.8", Type: "gem", Projects: []string{"admin"}},
+		{Name: "rack", Version: "3.0.8", Type: "gem", Projects: []string{"."}},
+		{Name: "rake", Version: "13.1.0", Type: "gem", Projects: []string{".", "admin"}},
+	}
+	listed := []listedDependency{
+		{Name: "rack", Version: "2.2.8", Type: "gem", Direct: true, Projects: []string{"admin"}},
+		{Name: "rack", Version: "3.0.8", Type: "gem", Direct: true, Projects: []string{"."}},
+		{Name: "rake", Version: "13.1.0", Type: "gem", Direct: true, Projects: []string{".", "admin"}},
+	}
+
+	var out bytes.Buffer
+	if err := writeListTable(&out, listed, parser.VersionConflicts(deps)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"PROJECTS", "13.1.0   gem   direct  -     ., admin", "Versions differing between projects:", "rack  2.2.8 (admin)  3.0.8 (.)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteListTable_ExtrasAndMarkers(t *testing.T) {
+	listed := []listedDependency{
+		{Name: "colorama", Version: "0.4.6", Type: "pypi", Direct: true, Markers: `sys_platform == "win32"`},
+		{Name: "requests", Version: "2.31.0", Type: "pypi", Direct: true, Extras: []string{"socks"}},
+	}
+
+	var out bytes.Buffer
+	if err := writeListTable(&out, listed, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"MARKERS", `sys_platform == "win32"`, "requests[socks]  2.31.0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+}